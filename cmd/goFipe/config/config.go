@@ -0,0 +1,312 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	EnvDevelopment = "DEV"
+	EnvProduction  = "PRD"
+)
+
+// Config is the whole application configuration. It is loaded once at startup by Load and injected
+// into the packages that need it, so no other package reads the environment.
+type Config struct {
+	Env        string
+	AdminToken string
+	Server     ServerConfig
+	Postgres   PostgresConfig
+	Cache      CacheConfig
+	RateLimit  RateLimitConfig
+	Tracing    TracingConfig
+	Log        LogConfig
+}
+
+type ServerConfig struct {
+	Host              string
+	Port              int
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration
+	// RequestTimeout is the deadline of every request, overridden by route template in RouteTimeouts.
+	// A timeout of 0 disables the deadline.
+	RequestTimeout time.Duration
+	RouteTimeouts  map[string]time.Duration
+	// MigrateOnStart migrates the database before serving. It is off by default: releases are migrated
+	// once with gofipe migrate before they are rolled out, rather than by every instance starting.
+	MigrateOnStart bool
+}
+
+type PostgresConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	// SSLMode, SSLRootCert, SSLCert and SSLKey follow the libpq parameters of the same name.
+	// verify-ca and verify-full require SSLRootCert; SSLCert and SSLKey enable client certificates.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ConnectAttempts is the number of connection attempts on startup. The wait between attempts starts
+	// at ConnectBackoff and doubles up to ConnectMaxBackoff.
+	ConnectAttempts   int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
+
+	// ReplicaHost optionally names a read replica sharing the primary credentials. Reads of the
+	// vehicles table are routed to it; every other query goes to the primary.
+	ReplicaHost string
+	ReplicaPort int
+}
+
+type CacheConfig struct {
+	// Size is the maximum number of cached queries. A size of 0 disables the cache.
+	Size int
+	TTL  time.Duration
+	// VersionCheckInterval is how often the ingestion version is read, so ingestions made by other
+	// processes purge the cache. An interval of 0 disables the check.
+	VersionCheckInterval time.Duration
+}
+
+type RateLimitConfig struct {
+	IP     RateLimit
+	ApiKey RateLimit
+}
+
+// RateLimit is a token bucket refilled with Rate tokens per second up to Burst tokens.
+// A rate or burst of 0 disables the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type TracingConfig struct {
+	// Exporter is one of "otlp", "stdout" or "none".
+	Exporter string
+}
+
+type LogConfig struct {
+	// Level is one of "debug", "info" or "error" and Format one of "json" or "console". Both default
+	// by environment, debug and console in DEV, info and json in PRD, and can be changed at runtime
+	// through /admin/logger.
+	Level  string
+	Format string
+	Body   BodyLoggingConfig
+}
+
+// BodyLoggingConfig adds the request and response bodies of a sample of the requests to the access log.
+// Sensitive JSON fields are redacted and bodies larger than MaxBytes are omitted.
+type BodyLoggingConfig struct {
+	// SampleRate is the fraction of the requests, between 0 and 1, whose bodies are logged. 0 disables it.
+	SampleRate float64
+	MaxBytes   int
+}
+
+var (
+	logLevels        = []string{"debug", "info", "error"}
+	logFormats       = []string{"json", "console"}
+	sslModes         = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	tracingExporters = []string{"otlp", "stdout", "none"}
+)
+
+// Default returns the configuration used for every setting missing from the file, environment and flags.
+func Default() Config {
+	return Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			RequestTimeout:    10 * time.Second,
+			RouteTimeouts:     map[string]time.Duration{},
+		},
+		Postgres: PostgresConfig{
+			Port:              5432,
+			SSLMode:           "disable",
+			MaxOpenConns:      25,
+			MaxIdleConns:      10,
+			ConnMaxLifetime:   30 * time.Minute,
+			ConnMaxIdleTime:   5 * time.Minute,
+			ConnectAttempts:   5,
+			ConnectBackoff:    time.Second,
+			ConnectMaxBackoff: 30 * time.Second,
+			ReplicaPort:       5432,
+		},
+		Cache: CacheConfig{
+			Size:                 1000,
+			TTL:                  time.Hour,
+			VersionCheckInterval: 10 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			IP:     RateLimit{Rate: 20, Burst: 40},
+			ApiKey: RateLimit{Rate: 10, Burst: 20},
+		},
+		Tracing: TracingConfig{
+			Exporter: "none",
+		},
+		Log: LogConfig{
+			Body: BodyLoggingConfig{MaxBytes: 4096},
+		},
+	}
+}
+
+// Addr returns the address the server listens on.
+func (s ServerConfig) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// IsProduction reports whether the application runs in production.
+func (c Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// Validate returns every invalid setting at once, so a misconfigured deployment is fixed in one go.
+func (c Config) Validate() error {
+	var v validation
+	c.validateCommon(&v)
+	c.validateServer(&v)
+	c.validatePostgres(&v)
+	c.validateCache(&v)
+	c.validateRateLimit(&v)
+	c.validateTracing(&v)
+	return v.err()
+}
+
+// ValidateDatabase validates the settings used by the command-line tools, which connect to the database
+// but do not serve HTTP.
+func (c Config) ValidateDatabase() error {
+	var v validation
+	c.validateCommon(&v)
+	c.validatePostgres(&v)
+	return v.err()
+}
+
+type validation struct {
+	errs []error
+}
+
+func (v *validation) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validation) err() error {
+	return errors.Join(v.errs...)
+}
+
+func (c Config) validateCommon(v *validation) {
+	v.check(c.Env == EnvDevelopment || c.Env == EnvProduction,
+		"env must be %s or %s, got %q", EnvDevelopment, EnvProduction, c.Env)
+
+	v.check(slices.Contains(logLevels, c.Log.Level),
+		"log.level (LOG_LEVEL) must be one of %v, got %q", logLevels, c.Log.Level)
+	v.check(slices.Contains(logFormats, c.Log.Format),
+		"log.format (LOG_FORMAT) must be one of %v, got %q", logFormats, c.Log.Format)
+	v.check(c.Log.Body.SampleRate >= 0 && c.Log.Body.SampleRate <= 1,
+		"log.body.sample_rate (LOG_BODY_SAMPLE_RATE) must be between 0 and 1, got %v", c.Log.Body.SampleRate)
+	v.check(c.Log.Body.MaxBytes > 0, "log.body.max_bytes (LOG_BODY_MAX_BYTES) must be greater than 0")
+}
+
+func (c Config) validateServer(v *validation) {
+	v.check(c.Server.Host != "", "server.host (APP_HOST) is required")
+	v.check(validPort(c.Server.Port), "server.port (APP_PORT) must be between 1 and 65535, got %d", c.Server.Port)
+	v.check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be greater than 0")
+	v.check(c.Server.ReadTimeout > 0, "server.read_timeout must be greater than 0")
+	v.check(c.Server.WriteTimeout > 0, "server.write_timeout must be greater than 0")
+	v.check(c.Server.IdleTimeout > 0, "server.idle_timeout must be greater than 0")
+	v.check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be greater than 0")
+	v.check(c.Server.RequestTimeout >= 0, "server.request_timeout must not be negative")
+	for route, timeout := range c.Server.RouteTimeouts {
+		v.check(timeout >= 0, "server.route_timeouts %s must not be negative", route)
+	}
+}
+
+func (c Config) validatePostgres(v *validation) {
+	v.check(c.Postgres.Host != "", "postgres.host (POSTGRES_HOST) is required")
+	v.check(validPort(c.Postgres.Port), "postgres.port (POSTGRES_PORT) must be between 1 and 65535, got %d", c.Postgres.Port)
+	v.check(c.Postgres.User != "", "postgres.user (POSTGRES_USER) is required")
+	v.check(c.Postgres.Password != "", "postgres.password (POSTGRES_PASSWORD) is required")
+	v.check(c.Postgres.Database != "", "postgres.database (POSTGRES_DB) is required")
+	v.check(slices.Contains(sslModes, c.Postgres.SSLMode),
+		"postgres.sslmode (POSTGRES_SSLMODE) must be one of %v, got %q", sslModes, c.Postgres.SSLMode)
+	v.check(c.Postgres.SSLRootCert != "" || (c.Postgres.SSLMode != "verify-ca" && c.Postgres.SSLMode != "verify-full"),
+		"postgres.sslrootcert (POSTGRES_SSLROOTCERT) is required by sslmode %s", c.Postgres.SSLMode)
+	v.check((c.Postgres.SSLCert == "") == (c.Postgres.SSLKey == ""),
+		"postgres.sslcert (POSTGRES_SSLCERT) and postgres.sslkey (POSTGRES_SSLKEY) must be set together")
+	for _, file := range []struct{ key, path string }{
+		{"postgres.sslrootcert", c.Postgres.SSLRootCert},
+		{"postgres.sslcert", c.Postgres.SSLCert},
+		{"postgres.sslkey", c.Postgres.SSLKey},
+	} {
+		if file.path != "" {
+			_, err := os.Stat(file.path)
+			v.check(err == nil, "%s: %v", file.key, err)
+		}
+	}
+	v.check(c.Postgres.MaxOpenConns > 0, "postgres.max_open_conns must be greater than 0")
+	v.check(c.Postgres.MaxIdleConns >= 0 && c.Postgres.MaxIdleConns <= c.Postgres.MaxOpenConns,
+		"postgres.max_idle_conns must be between 0 and postgres.max_open_conns")
+	v.check(c.Postgres.ConnMaxLifetime >= 0, "postgres.conn_max_lifetime must not be negative")
+	v.check(c.Postgres.ConnMaxIdleTime >= 0, "postgres.conn_max_idle_time must not be negative")
+	v.check(c.Postgres.ConnectAttempts > 0, "postgres.connect_attempts must be greater than 0")
+	v.check(c.Postgres.ConnectBackoff > 0 && c.Postgres.ConnectBackoff <= c.Postgres.ConnectMaxBackoff,
+		"postgres.connect_backoff must be greater than 0 and not greater than postgres.connect_max_backoff")
+	v.check(c.Postgres.ReplicaHost == "" || validPort(c.Postgres.ReplicaPort),
+		"postgres.replica_port (POSTGRES_REPLICA_PORT) must be between 1 and 65535, got %d", c.Postgres.ReplicaPort)
+}
+
+func (c Config) validateCache(v *validation) {
+	v.check(c.Cache.Size >= 0, "cache.size (CACHE_SIZE) must not be negative")
+	v.check(c.Cache.TTL > 0, "cache.ttl (CACHE_TTL) must be greater than 0")
+	v.check(c.Cache.VersionCheckInterval >= 0,
+		"cache.version_check_interval (CACHE_VERSION_CHECK_INTERVAL) must not be negative")
+}
+
+func (c Config) validateRateLimit(v *validation) {
+	v.check(c.RateLimit.IP.Rate >= 0 && c.RateLimit.IP.Burst >= 0, "rate_limit.ip must not be negative")
+	v.check(c.RateLimit.ApiKey.Rate >= 0 && c.RateLimit.ApiKey.Burst >= 0, "rate_limit.api_key must not be negative")
+}
+
+func (c Config) validateTracing(v *validation) {
+	v.check(slices.Contains(tracingExporters, c.Tracing.Exporter),
+		"tracing.exporter (OTEL_TRACES_EXPORTER) must be one of %v, got %q", tracingExporters, c.Tracing.Exporter)
+}
+
+// applyEnvDefaults fills the settings whose default depends on the environment.
+func (c *Config) applyEnvDefaults() {
+	if c.Log.Level == "" {
+		c.Log.Level = "debug"
+		if c.IsProduction() {
+			c.Log.Level = "info"
+		}
+	}
+	if c.Log.Format == "" {
+		c.Log.Format = "console"
+		if c.IsProduction() {
+			c.Log.Format = "json"
+		}
+	}
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+var requiredEnv = map[string]string{
+	"APP_HOST":          "localhost",
+	"APP_PORT":          "8081",
+	"POSTGRES_HOST":     "localhost",
+	"POSTGRES_USER":     "test",
+	"POSTGRES_PASSWORD": "test",
+	"POSTGRES_DB":       "test",
+}
+
+func withEnv(overrides map[string]string) map[string]string {
+	env := map[string]string{}
+	for key, value := range requiredEnv {
+		env[key] = value
+	}
+	for key, value := range overrides {
+		env[key] = value
+	}
+	return env
+}
+
+func TestLoad(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configFile, []byte(`
+env: PRD
+server:
+  port: 9000
+  route_timeouts:
+    /vehicles: 5s
+postgres:
+  host: db
+  sslmode: require
+cache:
+  ttl: 2h
+rate_limit:
+  ip:
+    rate: 1.5
+`), 0o600)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    func(c *Config)
+		wantErr string
+	}{
+		{
+			name: "Defaults and environment",
+			env:  withEnv(nil),
+			want: func(c *Config) {
+				c.Server.Host = "localhost"
+				c.Server.Port = 8081
+				c.Postgres.Host = "localhost"
+				c.Postgres.User = "test"
+				c.Postgres.Password = "test"
+				c.Postgres.Database = "test"
+				c.Log.Level, c.Log.Format = "debug", "console"
+			},
+		},
+		{
+			name: "File, environment and flags precedence",
+			args: []string{"-config", configFile, "-cache.size=0", "-server.route_timeouts=/readyz=1s", "-server.migrate_on_start=true"},
+			env:  withEnv(map[string]string{"APP_PORT": "8082", "POSTGRES_HOST": "replica"}),
+			want: func(c *Config) {
+				c.Env = EnvProduction
+				c.Server.Host = "localhost"
+				c.Server.Port = 8082
+				c.Server.RouteTimeouts = map[string]time.Duration{"/readyz": time.Second}
+				c.Server.MigrateOnStart = true
+				c.Postgres.Host = "replica"
+				c.Postgres.User = "test"
+				c.Postgres.Password = "test"
+				c.Postgres.Database = "test"
+				c.Postgres.SSLMode = "require"
+				c.Cache.Size = 0
+				c.Cache.TTL = 2 * time.Hour
+				c.RateLimit.IP.Rate = 1.5
+				c.Log.Level, c.Log.Format = "info", "json"
+			},
+		},
+		{
+			name: "File from CONFIG_FILE",
+			env:  withEnv(map[string]string{"CONFIG_FILE": configFile}),
+			want: func(c *Config) {
+				c.Env = EnvProduction
+				c.Server.Host = "localhost"
+				c.Server.Port = 8081
+				c.Server.RouteTimeouts = map[string]time.Duration{"/vehicles": 5 * time.Second}
+				c.Postgres.Host = "localhost"
+				c.Postgres.User = "test"
+				c.Postgres.Password = "test"
+				c.Postgres.Database = "test"
+				c.Postgres.SSLMode = "require"
+				c.Cache.TTL = 2 * time.Hour
+				c.RateLimit.IP.Rate = 1.5
+				c.Log.Level, c.Log.Format = "info", "json"
+			},
+		},
+		{
+			name: "Log settings override the environment defaults",
+			env:  withEnv(map[string]string{"ENV": EnvProduction, "LOG_LEVEL": "debug"}),
+			want: func(c *Config) {
+				c.Env = EnvProduction
+				c.Server.Host = "localhost"
+				c.Server.Port = 8081
+				c.Postgres.Host = "localhost"
+				c.Postgres.User = "test"
+				c.Postgres.Password = "test"
+				c.Postgres.Database = "test"
+				c.Log.Level, c.Log.Format = "debug", "json"
+			},
+		},
+		{
+			name:    "Missing required settings",
+			env:     map[string]string{"APP_HOST": "localhost"},
+			wantErr: "server.port (APP_PORT) must be between 1 and 65535, got 0",
+		},
+		{
+			name:    "Invalid environment value",
+			env:     withEnv(map[string]string{"CACHE_TTL": "1 hour"}),
+			wantErr: `CACHE_TTL: must be a duration, e.g. 10s, got "1 hour"`,
+		},
+		{
+			name:    "Invalid boolean",
+			env:     withEnv(map[string]string{"MIGRATE_ON_START": "sometimes"}),
+			wantErr: `MIGRATE_ON_START: must be true or false, got "sometimes"`,
+		},
+		{
+			name:    "Invalid sslmode",
+			env:     withEnv(map[string]string{"POSTGRES_SSLMODE": "on"}),
+			wantErr: `postgres.sslmode (POSTGRES_SSLMODE) must be one of`,
+		},
+		{
+			name:    "Verified TLS without CA certificate",
+			env:     withEnv(map[string]string{"POSTGRES_SSLMODE": "verify-full"}),
+			wantErr: "postgres.sslrootcert (POSTGRES_SSLROOTCERT) is required by sslmode verify-full",
+		},
+		{
+			name:    "Missing client key",
+			env:     withEnv(map[string]string{"POSTGRES_SSLCERT": "config_test.go"}),
+			wantErr: "postgres.sslcert (POSTGRES_SSLCERT) and postgres.sslkey (POSTGRES_SSLKEY) must be set together",
+		},
+		{
+			name:    "Idle connections above open connections",
+			env:     withEnv(map[string]string{"POSTGRES_MAX_OPEN_CONNS": "5", "POSTGRES_MAX_IDLE_CONNS": "10"}),
+			wantErr: "postgres.max_idle_conns must be between 0 and postgres.max_open_conns",
+		},
+		{
+			name:    "Invalid log format",
+			env:     withEnv(map[string]string{"LOG_FORMAT": "text"}),
+			wantErr: `log.format (LOG_FORMAT) must be one of [json console], got "text"`,
+		},
+		{
+			name:    "Body sample rate above 1",
+			env:     withEnv(map[string]string{"LOG_BODY_SAMPLE_RATE": "10"}),
+			wantErr: "log.body.sample_rate (LOG_BODY_SAMPLE_RATE) must be between 0 and 1, got 10",
+		},
+		{
+			name:    "Unknown flag",
+			args:    []string{"-postgres.hots=db"},
+			env:     withEnv(nil),
+			wantErr: "flag provided but not defined: -postgres.hots",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.args, envLookup(tt.env))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			want := Default()
+			tt.want(&want)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLoad_MissingRequiredSettingsReportedTogether(t *testing.T) {
+	_, err := Load(nil, envLookup(map[string]string{}))
+	for _, want := range []string{
+		"server.host (APP_HOST) is required",
+		"postgres.host (POSTGRES_HOST) is required",
+		"postgres.user (POSTGRES_USER) is required",
+		"postgres.password (POSTGRES_PASSWORD) is required",
+		"postgres.database (POSTGRES_DB) is required",
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestLoad_UnknownFileSetting(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, os.WriteFile(configFile, []byte("postgres:\n  hots: db\n"), 0o600))
+
+	_, err := Load([]string{"-config", configFile}, envLookup(withEnv(nil)))
+	assert.ErrorContains(t, err, "unknown setting postgres.hots")
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	_, err := Load([]string{"-config", "../../../configs/config.example.yaml"}, envLookup(map[string]string{}))
+	assert.Nil(t, err)
+}
+
+func TestLoadWithFlags_ValidateDatabase(t *testing.T) {
+	flags := flag.NewFlagSet("gofipe query", flag.ContinueOnError)
+	limit := flags.Int("limit", 10, "")
+	env := map[string]string{
+		"POSTGRES_HOST":     "localhost",
+		"POSTGRES_USER":     "test",
+		"POSTGRES_PASSWORD": "test",
+		"POSTGRES_DB":       "test",
+	}
+
+	got, err := LoadWithFlags(flags, []string{"-limit=5", "-postgres.port=5433"}, envLookup(env), Config.ValidateDatabase)
+	assert.Nil(t, err, "the server settings are not required")
+	assert.Equal(t, 5, *limit)
+	assert.Equal(t, 5433, got.Postgres.Port)
+
+	_, err = Load(nil, envLookup(env))
+	assert.ErrorContains(t, err, "server.host (APP_HOST) is required")
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting describes one configuration value. The key is its path in the YAML file and its flag name;
+// env is the environment variable that overrides the file, if any.
+type setting struct {
+	key   string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	stringSetting("env", "ENV", "environment, DEV or PRD", func(c *Config) *string { return &c.Env }),
+	stringSetting("admin_token", "ADMIN_TOKEN", "token of the /admin endpoints, empty disables them",
+		func(c *Config) *string { return &c.AdminToken }),
+
+	stringSetting("server.host", "APP_HOST", "host the server listens on", func(c *Config) *string { return &c.Server.Host }),
+	intSetting("server.port", "APP_PORT", "port the server listens on", func(c *Config) *int { return &c.Server.Port }),
+	durationSetting("server.read_header_timeout", "HTTP_READ_HEADER_TIMEOUT", "time to read the request headers",
+		func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout }),
+	durationSetting("server.read_timeout", "HTTP_READ_TIMEOUT", "time to read the whole request",
+		func(c *Config) *time.Duration { return &c.Server.ReadTimeout }),
+	durationSetting("server.write_timeout", "HTTP_WRITE_TIMEOUT", "time to write the response, longer than the request timeout",
+		func(c *Config) *time.Duration { return &c.Server.WriteTimeout }),
+	durationSetting("server.idle_timeout", "HTTP_IDLE_TIMEOUT", "time a keep-alive connection is kept idle",
+		func(c *Config) *time.Duration { return &c.Server.IdleTimeout }),
+	durationSetting("server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "time to drain in-flight requests on shutdown",
+		func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+	durationSetting("server.request_timeout", "REQUEST_TIMEOUT", "deadline of every request, 0 disables it",
+		func(c *Config) *time.Duration { return &c.Server.RequestTimeout }),
+	{
+		key:   "server.route_timeouts",
+		env:   "REQUEST_TIMEOUTS",
+		usage: "request deadline by route template, e.g. /vehicles=5s,/admin/api-keys=30s",
+		set: func(c *Config, value string) error {
+			routeTimeouts, err := parseRouteTimeouts(value)
+			if err != nil {
+				return err
+			}
+			c.Server.RouteTimeouts = routeTimeouts
+			return nil
+		},
+	},
+	boolSetting("server.migrate_on_start", "MIGRATE_ON_START", "migrate the database before serving",
+		func(c *Config) *bool { return &c.Server.MigrateOnStart }),
+
+	stringSetting("postgres.host", "POSTGRES_HOST", "postgres host", func(c *Config) *string { return &c.Postgres.Host }),
+	intSetting("postgres.port", "POSTGRES_PORT", "postgres port", func(c *Config) *int { return &c.Postgres.Port }),
+	stringSetting("postgres.user", "POSTGRES_USER", "postgres user", func(c *Config) *string { return &c.Postgres.User }),
+	stringSetting("postgres.password", "POSTGRES_PASSWORD", "postgres password",
+		func(c *Config) *string { return &c.Postgres.Password }),
+	stringSetting("postgres.database", "POSTGRES_DB", "postgres database",
+		func(c *Config) *string { return &c.Postgres.Database }),
+	stringSetting("postgres.sslmode", "POSTGRES_SSLMODE", "postgres sslmode",
+		func(c *Config) *string { return &c.Postgres.SSLMode }),
+	stringSetting("postgres.sslrootcert", "POSTGRES_SSLROOTCERT", "CA certificate file verifying the server",
+		func(c *Config) *string { return &c.Postgres.SSLRootCert }),
+	stringSetting("postgres.sslcert", "POSTGRES_SSLCERT", "client certificate file",
+		func(c *Config) *string { return &c.Postgres.SSLCert }),
+	stringSetting("postgres.sslkey", "POSTGRES_SSLKEY", "client private key file",
+		func(c *Config) *string { return &c.Postgres.SSLKey }),
+	intSetting("postgres.max_open_conns", "POSTGRES_MAX_OPEN_CONNS", "maximum open connections",
+		func(c *Config) *int { return &c.Postgres.MaxOpenConns }),
+	intSetting("postgres.max_idle_conns", "POSTGRES_MAX_IDLE_CONNS", "maximum idle connections",
+		func(c *Config) *int { return &c.Postgres.MaxIdleConns }),
+	durationSetting("postgres.conn_max_lifetime", "POSTGRES_CONN_MAX_LIFETIME", "maximum lifetime of a connection, 0 is unlimited",
+		func(c *Config) *time.Duration { return &c.Postgres.ConnMaxLifetime }),
+	durationSetting("postgres.conn_max_idle_time", "POSTGRES_CONN_MAX_IDLE_TIME", "maximum idle time of a connection, 0 is unlimited",
+		func(c *Config) *time.Duration { return &c.Postgres.ConnMaxIdleTime }),
+	intSetting("postgres.connect_attempts", "POSTGRES_CONNECT_ATTEMPTS", "connection attempts on startup",
+		func(c *Config) *int { return &c.Postgres.ConnectAttempts }),
+	durationSetting("postgres.connect_backoff", "POSTGRES_CONNECT_BACKOFF", "initial wait between connection attempts",
+		func(c *Config) *time.Duration { return &c.Postgres.ConnectBackoff }),
+	durationSetting("postgres.connect_max_backoff", "POSTGRES_CONNECT_MAX_BACKOFF", "maximum wait between connection attempts",
+		func(c *Config) *time.Duration { return &c.Postgres.ConnectMaxBackoff }),
+	stringSetting("postgres.replica_host", "POSTGRES_REPLICA_HOST", "read replica host, empty disables read routing",
+		func(c *Config) *string { return &c.Postgres.ReplicaHost }),
+	intSetting("postgres.replica_port", "POSTGRES_REPLICA_PORT", "read replica port",
+		func(c *Config) *int { return &c.Postgres.ReplicaPort }),
+
+	intSetting("cache.size", "CACHE_SIZE", "maximum number of cached queries, 0 disables the cache",
+		func(c *Config) *int { return &c.Cache.Size }),
+	durationSetting("cache.ttl", "CACHE_TTL", "time a query stays cached", func(c *Config) *time.Duration { return &c.Cache.TTL }),
+	durationSetting("cache.version_check_interval", "CACHE_VERSION_CHECK_INTERVAL",
+		"how often the cache checks for ingestions of other processes, 0 disables the check",
+		func(c *Config) *time.Duration { return &c.Cache.VersionCheckInterval }),
+
+	floatSetting("rate_limit.ip.rate", "RATE_LIMIT_IP_RATE", "requests per second by client ip",
+		func(c *Config) *float64 { return &c.RateLimit.IP.Rate }),
+	intSetting("rate_limit.ip.burst", "RATE_LIMIT_IP_BURST", "burst of requests by client ip",
+		func(c *Config) *int { return &c.RateLimit.IP.Burst }),
+	floatSetting("rate_limit.api_key.rate", "RATE_LIMIT_KEY_RATE", "requests per second by api key",
+		func(c *Config) *float64 { return &c.RateLimit.ApiKey.Rate }),
+	intSetting("rate_limit.api_key.burst", "RATE_LIMIT_KEY_BURST", "burst of requests by api key",
+		func(c *Config) *int { return &c.RateLimit.ApiKey.Burst }),
+
+	stringSetting("tracing.exporter", "OTEL_TRACES_EXPORTER", "trace exporter, otlp, stdout or none",
+		func(c *Config) *string { return &c.Tracing.Exporter }),
+
+	stringSetting("log.level", "LOG_LEVEL", "log level, debug, info or error; defaults to debug in DEV and info in PRD",
+		func(c *Config) *string { return &c.Log.Level }),
+	stringSetting("log.format", "LOG_FORMAT", "log format, json or console; defaults to console in DEV and json in PRD",
+		func(c *Config) *string { return &c.Log.Format }),
+	floatSetting("log.body.sample_rate", "LOG_BODY_SAMPLE_RATE", "fraction of the requests whose bodies are logged, 0 disables it",
+		func(c *Config) *float64 { return &c.Log.Body.SampleRate }),
+	intSetting("log.body.max_bytes", "LOG_BODY_MAX_BYTES", "larger bodies are not logged",
+		func(c *Config) *int { return &c.Log.Body.MaxBytes }),
+}
+
+// Load builds the configuration from, in increasing order of precedence, the defaults, the YAML file
+// given by the -config flag or the CONFIG_FILE environment variable, the environment and the flags.
+// It returns an error describing every invalid or missing setting.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, error) {
+	return LoadWithFlags(flag.NewFlagSet("gofipe", flag.ContinueOnError), args, lookupEnv, Config.Validate)
+}
+
+// LoadWithFlags is Load for a command defining its own flags in flags, which are parsed together with
+// the configuration flags. validate checks the settings the command uses, e.g. Config.ValidateDatabase.
+func LoadWithFlags(
+	flags *flag.FlagSet,
+	args []string,
+	lookupEnv func(string) (string, bool),
+	validate func(Config) error) (Config, error) {
+	configFile := flags.String("config", "", "path of the YAML configuration file (CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.key] = flags.String(s.key, "", fmt.Sprintf("%s (%s)", s.usage, s.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, fmt.Errorf("parsing flags: %w", err)
+	}
+
+	config := Default()
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := applyFile(&config, *configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok {
+			if err := s.set(&config, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.key == f.Name {
+				if err := s.set(&config, *flagValues[s.key]); err != nil {
+					errs = append(errs, fmt.Errorf("-%s: %w", s.key, err))
+				}
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return Config{}, errors.Join(errs...)
+	}
+
+	config.applyEnvDefaults()
+	if err := validate(config); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return config, nil
+}
+
+func applyFile(config *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading configuration file: %w", err)
+	}
+
+	var document map[string]any
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("parsing configuration file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", document, values)
+
+	var errs []error
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s, ok := findSetting(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %s", path, key))
+			continue
+		}
+		if err := s.set(config, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// flatten converts the nested YAML document into dotted keys. Mappings of a known setting, such as
+// server.route_timeouts, are kept whole in the route=value,route=value form used by the environment.
+func flatten(prefix string, node any, values map[string]string) {
+	if node == nil {
+		return
+	}
+	mapping, isMapping := node.(map[string]any)
+	if !isMapping {
+		values[prefix] = fmt.Sprint(node)
+		return
+	}
+
+	if _, ok := findSetting(prefix); ok {
+		pairs := make([]string, 0, len(mapping))
+		for key, value := range mapping {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+		}
+		sort.Strings(pairs)
+		values[prefix] = strings.Join(pairs, ",")
+		return
+	}
+
+	for key, value := range mapping {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flatten(key, value, values)
+	}
+}
+
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func parseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	routeTimeouts := map[string]time.Duration{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		route, timeoutValue, found := strings.Cut(pair, "=")
+		timeout, err := time.ParseDuration(strings.TrimSpace(timeoutValue))
+		if !found || err != nil {
+			return nil, fmt.Errorf("must be a list of route=duration, e.g. /vehicles=5s, got %q", pair)
+		}
+		routeTimeouts[strings.TrimSpace(route)] = timeout
+	}
+	return routeTimeouts, nil
+}
+
+func stringSetting(key string, env string, usage string, field func(*Config) *string) setting {
+	return setting{key: key, env: env, usage: usage, set: func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}}
+}
+
+func intSetting(key string, env string, usage string, field func(*Config) *int) setting {
+	return setting{key: key, env: env, usage: usage, set: func(c *Config, value string) error {
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func floatSetting(key string, env string, usage string, field func(*Config) *float64) setting {
+	return setting{key: key, env: env, usage: usage, set: func(c *Config, value string) error {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func boolSetting(key string, env string, usage string, field func(*Config) *bool) setting {
+	return setting{key: key, env: env, usage: usage, set: func(c *Config, value string) error {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
+
+func durationSetting(key string, env string, usage string, field func(*Config) *time.Duration) setting {
+	return setting{key: key, env: env, usage: usage, set: func(c *Config, value string) error {
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("must be a duration, e.g. 10s, got %q", value)
+		}
+		*field(c) = parsed
+		return nil
+	}}
+}
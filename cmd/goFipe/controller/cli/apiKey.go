@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+)
+
+const apiKeyUsage = "usage: gofipe apikey <create|list|revoke|usage> [flags]"
+
+// runApiKey manages the API keys as the /admin/api-keys endpoints do.
+func runApiKey(ctx context.Context, env Environment, args []string) error {
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+	action, args := args[0], args[1:]
+	flags := newFlagSet(env, "apikey "+action)
+	format := flags.String("format", formatTable, "output format, table or json")
+
+	var run func(ctx context.Context, services Services) (any, error)
+	switch action {
+	case "create":
+		name := flags.String("name", "", "name of the key owner")
+		quota := flags.Int("quota", 0, "monthly request quota, 0 is unlimited")
+		run = func(ctx context.Context, services Services) (any, error) {
+			apiKey, rawKey, err := services.ApiKey.Create(ctx, *name, *quota)
+			if err != nil {
+				return nil, appError(err)
+			}
+			fmt.Fprintln(env.Stderr, "Store the key now, it can not be shown again.")
+			return dto.CreateApiKeyResponse{ApiKeyResponse: dto.ApiKeyResponseFromDomain(apiKey), Key: rawKey}, nil
+		}
+	case "list":
+		run = func(ctx context.Context, services Services) (any, error) {
+			apiKeys, err := services.ApiKey.List(ctx)
+			if err != nil {
+				return nil, appError(err)
+			}
+			response := make([]dto.ApiKeyResponse, 0, len(apiKeys))
+			for _, apiKey := range apiKeys {
+				response = append(response, dto.ApiKeyResponseFromDomain(apiKey))
+			}
+			return response, nil
+		}
+	case "revoke":
+		id := flags.Uint("id", 0, "id of the key")
+		run = func(ctx context.Context, services Services) (any, error) {
+			if *id == 0 {
+				return nil, errors.New("-id is required")
+			}
+			if err := services.ApiKey.Revoke(ctx, *id); err != nil {
+				return nil, appError(err)
+			}
+			fmt.Fprintf(env.Stderr, "API key %d revoked\n", *id)
+			return nil, nil
+		}
+	case "usage":
+		now := time.Now().UTC()
+		id := flags.Uint("id", 0, "id of the key")
+		year := flags.Int("year", now.Year(), "year of the usage")
+		month := flags.Int("month", int(now.Month()), "month of the usage")
+		run = func(ctx context.Context, services Services) (any, error) {
+			if *id == 0 {
+				return nil, errors.New("-id is required")
+			}
+			usage, err := services.ApiKey.GetUsage(ctx, *id, *year, *month)
+			if err != nil {
+				return nil, appError(err)
+			}
+			return dto.ApiKeyUsageResponseFromDomain(usage), nil
+		}
+	default:
+		return fmt.Errorf("unknown action %q, %s", action, apiKeyUsage)
+	}
+
+	cfg, log, _, err := load(env, flags, args, config.Config.ValidateDatabase)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+	if *format != formatTable && *format != formatJSON {
+		return fmt.Errorf("format must be %s or %s, got %q", formatTable, formatJSON, *format)
+	}
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+
+	result, err := run(ctx, services)
+	if err != nil || result == nil {
+		return err
+	}
+	if *format == formatJSON {
+		encoder := json.NewEncoder(env.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	return writeApiKeyTable(env.Stdout, result)
+}
+
+// writeApiKeyTable writes the result of an apikey action as a table with one key per row.
+func writeApiKeyTable(w io.Writer, result any) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	switch result := result.(type) {
+	case dto.CreateApiKeyResponse:
+		fmt.Fprintln(table, "ID\tNAME\tPREFIX\tMONTHLY_QUOTA\tKEY")
+		fmt.Fprintf(table, "%d\t%s\t%s\t%d\t%s\n", result.ID, result.Name, result.Prefix, result.MonthlyQuota, result.Key)
+	case []dto.ApiKeyResponse:
+		fmt.Fprintln(table, "ID\tNAME\tPREFIX\tMONTHLY_QUOTA\tCREATED_AT\tREVOKED_AT")
+		for _, apiKey := range result {
+			revokedAt := "-"
+			if apiKey.RevokedAt != nil {
+				revokedAt = apiKey.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(table, "%d\t%s\t%s\t%d\t%s\t%s\n", apiKey.ID, apiKey.Name, apiKey.Prefix,
+				apiKey.MonthlyQuota, apiKey.CreatedAt.Format(time.RFC3339), revokedAt)
+		}
+	case dto.ApiKeyUsageResponse:
+		fmt.Fprintln(table, "API_KEY_ID\tANO\tMES\tREQUESTS")
+		fmt.Fprintf(table, "%d\t%d\t%d\t%d\n", result.ApiKeyID, result.Year, result.Month, result.Requests)
+	default:
+		return fmt.Errorf("unexpected result %T", result)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunApiKey(t *testing.T) {
+	createdAt := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		args          []string
+		apiKeyService func(service *mockPort.MockApiKeyService)
+		wantCode      int
+		wantStdout    string
+		wantStderr    string
+	}{
+		{
+			name: "create",
+			args: []string{"apikey", "create", "-name", "Dealer", "-quota", "1000"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Create(gomock.Any(), "Dealer", 1000).Return(
+					domain.ApiKey{ID: 1, Name: "Dealer", Prefix: "gf_01234567", MonthlyQuota: 1000, CreatedAt: createdAt},
+					"gf_0123456789",
+					nil,
+				)
+			},
+			wantCode: 0,
+			wantStdout: "ID  NAME    PREFIX       MONTHLY_QUOTA  KEY\n" +
+				"1   Dealer  gf_01234567  1000           gf_0123456789\n",
+			wantStderr: "Store the key now, it can not be shown again.\n",
+		},
+		{
+			name: "create with validation error",
+			args: []string{"apikey", "create"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Create(gomock.Any(), "", 0).Return(domain.ApiKey{}, "", errs.NewValidationError("Name is required"))
+			},
+			wantCode:   1,
+			wantStderr: "gofipe apikey: Name is required\n",
+		},
+		{
+			name: "list",
+			args: []string{"apikey", "list"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().List(gomock.Any()).Return([]domain.ApiKey{
+					{ID: 1, Name: "Dealer", Prefix: "gf_01234567", CreatedAt: createdAt},
+					{ID: 2, Name: "Old", Prefix: "gf_76543210", CreatedAt: createdAt, RevokedAt: &createdAt},
+				}, nil)
+			},
+			wantCode: 0,
+			wantStdout: "ID  NAME    PREFIX       MONTHLY_QUOTA  CREATED_AT            REVOKED_AT\n" +
+				"1   Dealer  gf_01234567  0              2021-07-01T00:00:00Z  -\n" +
+				"2   Old     gf_76543210  0              2021-07-01T00:00:00Z  2021-07-01T00:00:00Z\n",
+		},
+		{
+			name: "revoke",
+			args: []string{"apikey", "revoke", "-id", "1"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Revoke(gomock.Any(), uint(1)).Return(nil)
+			},
+			wantCode:   0,
+			wantStderr: "API key 1 revoked\n",
+		},
+		{
+			name:          "revoke without id",
+			args:          []string{"apikey", "revoke"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {},
+			wantCode:      1,
+			wantStderr:    "gofipe apikey: -id is required\n",
+		},
+		{
+			name: "usage as json",
+			args: []string{"apikey", "usage", "-id", "1", "-year", "2021", "-month", "7", "-format", "json"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().GetUsage(gomock.Any(), uint(1), 2021, 7).
+					Return(domain.ApiKeyUsage{ApiKeyID: 1, Year: 2021, Month: 7, Requests: 42}, nil)
+			},
+			wantCode:   0,
+			wantStdout: "{\n  \"api_key_id\": 1,\n  \"ano\": 2021,\n  \"mes\": 7,\n  \"requests\": 42\n}\n",
+		},
+		{
+			name:          "unknown action",
+			args:          []string{"apikey", "rotate"},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {},
+			wantCode:      1,
+			wantStderr:    "gofipe apikey: unknown action \"rotate\", usage: gofipe apikey <create|list|revoke|usage> [flags]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, mockApiKeyService, services := getMockServices(t)
+			tt.apiKeyService(mockApiKeyService)
+
+			var r testRun
+			assert.Equal(t, tt.wantCode, r.run(tt.args, services, nil))
+			assert.Equal(t, tt.wantStdout, r.stdout.String())
+			assert.Equal(t, tt.wantStderr, r.stderr.String())
+		})
+	}
+}
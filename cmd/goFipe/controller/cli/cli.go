@@ -0,0 +1,135 @@
+// Package cli implements the gofipe command: the server and the tools operators use to manage the
+// vehicles and the API keys through the service layer, without going through the REST API.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+// Services are the services used by the commands, backed by the database.
+type Services struct {
+	Vehicle ports.VehicleService
+	ApiKey  ports.ApiKeyService
+	Health  ports.HealthService
+	// Migrate creates or updates the database schema to SchemaVersion.
+	Migrate       func() error
+	SchemaVersion int
+}
+
+// OpenFunc connects to the database described by config and builds the services. The returned function
+// releases the connection.
+type OpenFunc func(ctx context.Context, config config.Config, log logger.Logger) (Services, func(), error)
+
+// Environment is what the commands read from and write to, so they can run outside of a process.
+type Environment struct {
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	LookupEnv func(string) (string, bool)
+	Open      OpenFunc
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, env Environment, args []string) error
+}
+
+var commands = []command{
+	{name: "serve", summary: "serve the REST API, the default command", run: runServe},
+	{name: "migrate", summary: "create or update the database schema", run: runMigrate},
+	{name: "ingest", summary: "store the vehicles of a CSV or JSON file", run: runIngest},
+	{name: "query", summary: "query the vehicles with the where and order syntax of the API", run: runQuery},
+	{name: "export", summary: "write every vehicle matching a filter to a CSV or JSON file", run: runExport},
+	{name: "apikey", summary: "create, list and revoke API keys and show their usage", run: runApiKey},
+}
+
+// Run runs the command named by the first argument with the remaining arguments and returns the exit
+// code. Without a command, or when the first argument is a flag, the server is started.
+func Run(ctx context.Context, args []string, env Environment) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage(env.Stderr)
+		return 0
+	}
+
+	cmd, found := findCommand(name)
+	if !found {
+		fmt.Fprintf(env.Stderr, "gofipe: unknown command %q\n\n", name)
+		usage(env.Stderr)
+		return 2
+	}
+	if err := cmd.run(ctx, env, args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(env.Stderr, "gofipe %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: gofipe <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Every command accepts the configuration flags; run gofipe <command> -h to list them.")
+}
+
+func newFlagSet(env Environment, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("gofipe "+name, flag.ContinueOnError)
+	flags.SetOutput(env.Stderr)
+	return flags
+}
+
+// load parses the flags of the command together with the configuration flags and creates the logger,
+// which writes to stderr so the output of the command can be piped.
+func load(
+	env Environment,
+	flags *flag.FlagSet,
+	args []string,
+	validate func(config.Config) error) (config.Config, logger.Logger, *logger.Controller, error) {
+	cfg, err := config.LoadWithFlags(flags, args, env.LookupEnv, validate)
+	if err != nil {
+		return config.Config{}, nil, nil, err
+	}
+	log, controller, err := logger.New(env.Stderr, cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		return config.Config{}, nil, nil, fmt.Errorf("initializing logger: %w", err)
+	}
+	return cfg, log, controller, nil
+}
+
+// appError converts the error returned by a service, which is nil or an *errs.AppError, to an error.
+func appError(err *errs.AppError) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(err.Message)
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEnv = map[string]string{
+	"POSTGRES_HOST":     "localhost",
+	"POSTGRES_USER":     "test",
+	"POSTGRES_PASSWORD": "test",
+	"POSTGRES_DB":       "test",
+	"LOG_LEVEL":         "error",
+}
+
+type testRun struct {
+	stdin  string
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+	opened bool
+	closed bool
+}
+
+// run runs the command with services backed by the mocks, or failing to open if openErr is not nil.
+func (r *testRun) run(args []string, services Services, openErr error) int {
+	env := Environment{
+		Stdin:  strings.NewReader(r.stdin),
+		Stdout: &r.stdout,
+		Stderr: &r.stderr,
+		LookupEnv: func(key string) (string, bool) {
+			value, found := testEnv[key]
+			return value, found
+		},
+		Open: func(ctx context.Context, config config.Config, log logger.Logger) (Services, func(), error) {
+			if openErr != nil {
+				return Services{}, nil, openErr
+			}
+			r.opened = true
+			return services, func() { r.closed = true }, nil
+		},
+	}
+	return Run(context.Background(), args, env)
+}
+
+func getMockServices(t *testing.T) (*mockPort.MockVehicleService, *mockPort.MockApiKeyService, Services) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	mockVehicleService := mockPort.NewMockVehicleService(ctrl)
+	mockApiKeyService := mockPort.NewMockApiKeyService(ctrl)
+	return mockVehicleService, mockApiKeyService, Services{Vehicle: mockVehicleService, ApiKey: mockApiKeyService}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("unknown command", func(t *testing.T) {
+		var r testRun
+		assert.Equal(t, 2, r.run([]string{"unknown"}, Services{}, nil))
+		assert.Contains(t, r.stderr.String(), `gofipe: unknown command "unknown"`)
+		assert.Contains(t, r.stderr.String(), "Usage: gofipe <command> [flags]")
+	})
+
+	t.Run("help", func(t *testing.T) {
+		var r testRun
+		assert.Equal(t, 0, r.run([]string{"help"}, Services{}, nil))
+		assert.Contains(t, r.stderr.String(), "migrate")
+	})
+
+	t.Run("command help", func(t *testing.T) {
+		var r testRun
+		assert.Equal(t, 0, r.run([]string{"query", "-h"}, Services{}, nil))
+		assert.Contains(t, r.stderr.String(), "-where")
+		assert.False(t, r.opened)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		var r testRun
+		assert.Equal(t, 1, r.run([]string{"migrate", "-env", "QA"}, Services{}, nil))
+		assert.Contains(t, r.stderr.String(), "gofipe migrate: invalid configuration")
+		assert.False(t, r.opened)
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		var r testRun
+		assert.Equal(t, 1, r.run([]string{"migrate"}, Services{}, errors.New("connection refused")))
+		assert.Equal(t, "gofipe migrate: connection refused\n", r.stderr.String())
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		var r testRun
+		migrated := false
+		services := Services{Migrate: func() error { migrated = true; return nil }, SchemaVersion: 3}
+		assert.Equal(t, 0, r.run([]string{"migrate"}, services, nil))
+		assert.True(t, migrated)
+		assert.True(t, r.closed)
+		assert.Equal(t, "Database migrated to schema version 3\n", r.stdout.String())
+	})
+
+	t.Run("migrate error", func(t *testing.T) {
+		var r testRun
+		services := Services{Migrate: func() error { return errors.New("migrating tables: permission denied") }}
+		assert.Equal(t, 1, r.run([]string{"migrate"}, services, nil))
+		assert.Equal(t, "gofipe migrate: migrating tables: permission denied\n", r.stderr.String())
+		assert.True(t, r.closed)
+	})
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/tracing"
+)
+
+const tracingShutdownTimeout = 5 * time.Second
+
+// runServe serves the REST API until ctx is done, migrating the database first only if
+// server.migrate_on_start is set. Resources are released in the reverse order they were acquired once
+// the in-flight requests are drained.
+func runServe(ctx context.Context, env Environment, args []string) error {
+	cfg, log, loggerController, err := load(env, newFlagSet(env, "serve"), args, config.Config.Validate)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing.Exporter)
+	if err != nil {
+		return fmt.Errorf("initializing tracing: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("Unable to flush traces", logger.Err(err))
+		}
+	}()
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+	if cfg.Server.MigrateOnStart {
+		if err := services.Migrate(); err != nil {
+			return err
+		}
+	}
+	return rest.Start(ctx, cfg, log, loggerController, services.Vehicle, services.ApiKey, services.Health)
+}
+
+// runMigrate creates or updates the database schema, so it can be done before rolling out a release.
+func runMigrate(ctx context.Context, env Environment, args []string) error {
+	cfg, log, _, err := load(env, newFlagSet(env, "migrate"), args, config.Config.ValidateDatabase)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+	if err := services.Migrate(); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "Database migrated to schema version %d\n", services.SchemaVersion)
+	return nil
+}
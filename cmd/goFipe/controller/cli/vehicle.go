@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/params"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+const (
+	whereUsage = "filter, a comma separated list of column:value, e.g. year:2021,month:7"
+	orderUsage = "sort, a comma separated list of column:asc or column:desc"
+	fromUsage  = "first reference month, as YYYY-MM, e.g. 2021-01"
+	toUsage    = "last reference month, as YYYY-MM, e.g. 2021-07"
+)
+
+// runQuery prints a page of vehicles, as GET /vehicles returns it.
+func runQuery(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet(env, "query")
+	where := flags.String("where", "", whereUsage)
+	order := flags.String("order", "", orderUsage)
+	from := flags.String("from", "", fromUsage)
+	to := flags.String("to", "", toUsage)
+	offset := flags.Int("offset", 0, "number of vehicles to skip")
+	limit := flags.Int("limit", domain.DefaultLimit, fmt.Sprintf("maximum number of vehicles, up to %d", domain.MaxLimit))
+	format := flags.String("format", formatTable, "output format, table, csv or json")
+	cfg, log, _, err := load(env, flags, args, config.Config.ValidateDatabase)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+
+	query, errQuery := params.VehicleQuery(*where, *order, *from, *to, *offset, *limit)
+	if errQuery != nil {
+		return appError(errQuery)
+	}
+	writer, err := newVehicleWriter(*format, env.Stdout)
+	if err != nil {
+		return err
+	}
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+
+	vehicles, errGet := services.Vehicle.GetVehicle(ctx, query)
+	if errGet != nil && errGet.Code != http.StatusNotFound {
+		return appError(errGet)
+	}
+	if err := writer.Write(vehicles); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// runExport writes every vehicle matching the filter, fetching them page by page.
+func runExport(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet(env, "export")
+	where := flags.String("where", "", whereUsage)
+	order := flags.String("order", "fipe_code:asc", orderUsage)
+	from := flags.String("from", "", fromUsage)
+	to := flags.String("to", "", toUsage)
+	output := flags.String("output", "", "file to write, stdout if empty")
+	format := flags.String("format", "", "output format, csv or json; defaults to the extension of -output or csv")
+	cfg, log, _, err := load(env, flags, args, config.Config.ValidateDatabase)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+
+	query, errQuery := params.VehicleQuery(*where, *order, *from, *to, 0, domain.MaxLimit)
+	if errQuery != nil {
+		return appError(errQuery)
+	}
+	if *format == "" {
+		*format = formatFromPath(*output)
+	}
+	if *format != formatCSV && *format != formatJSON {
+		return fmt.Errorf("format must be %s or %s, got %q", formatCSV, formatJSON, *format)
+	}
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+
+	var w io.Writer = env.Stdout
+	closeOutput := func() error { return nil }
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer file.Close()
+		w, closeOutput = file, file.Close
+	}
+	writer, err := newVehicleWriter(*format, w)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for ; ; query = query.NextPage() {
+		vehicles, errGet := services.Vehicle.GetVehicle(ctx, query)
+		if errGet != nil && errGet.Code != http.StatusNotFound {
+			return appError(errGet)
+		}
+		if err := writer.Write(vehicles); err != nil {
+			return err
+		}
+		count += len(vehicles)
+		if len(vehicles) < domain.MaxLimit {
+			break
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if err := closeOutput(); err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
+	log.Info("Vehicles exported", logger.Int("count", count))
+	return nil
+}
+
+// runIngest stores the vehicles of a file written by export or by hand. The file is rejected as a whole
+// if any vehicle is invalid.
+func runIngest(ctx context.Context, env Environment, args []string) error {
+	flags := newFlagSet(env, "ingest")
+	input := flags.String("file", "", "file to read, - reads stdin")
+	format := flags.String("format", "", "input format, csv or json; defaults to the extension of -file or csv")
+	cfg, log, _, err := load(env, flags, args, config.Config.ValidateDatabase)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Sync() }()
+
+	if *input == "" {
+		return errors.New("-file is required")
+	}
+	if *format == "" {
+		*format = formatFromPath(*input)
+	}
+
+	r := env.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("opening input file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	vehicles, err := readVehicles(r, *format)
+	if err != nil {
+		return err
+	}
+
+	services, closeServices, err := env.Open(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer closeServices()
+
+	if err := appError(services.Vehicle.SaveVehicles(ctx, vehicles)); err != nil {
+		return err
+	}
+	fmt.Fprintf(env.Stdout, "%d vehicles saved\n", len(vehicles))
+	return nil
+}
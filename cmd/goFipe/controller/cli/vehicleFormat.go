@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+)
+
+const (
+	formatTable = "table"
+	formatCSV   = "csv"
+	formatJSON  = "json"
+)
+
+// vehicleColumns are the CSV and table columns, named as the fields of the API response.
+var vehicleColumns = []string{"ano", "mes", "fipe_code", "marca", "modelo", "ano_modelo", "autenticacao", "valor_medio"}
+
+// vehicleWriter writes vehicles page by page; Flush must be called after the last page.
+type vehicleWriter interface {
+	Write(vehicles []domain.Vehicle) error
+	Flush() error
+}
+
+func newVehicleWriter(format string, w io.Writer) (vehicleWriter, error) {
+	switch format {
+	case formatTable:
+		return &tableVehicleWriter{w: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)}, nil
+	case formatCSV:
+		return &csvVehicleWriter{w: csv.NewWriter(w)}, nil
+	case formatJSON:
+		return &jsonVehicleWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("format must be %s, %s or %s, got %q", formatTable, formatCSV, formatJSON, format)
+	}
+}
+
+// formatFromPath returns the format given by the extension of path, CSV unless it is .json.
+func formatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return formatJSON
+	}
+	return formatCSV
+}
+
+func vehicleRecord(vehicle domain.Vehicle) []string {
+	return []string{
+		strconv.Itoa(vehicle.Year),
+		strconv.Itoa(vehicle.Month),
+		vehicle.FipeCode,
+		vehicle.Brand,
+		vehicle.Model,
+		vehicle.YearModel,
+		vehicle.Authentication,
+		strconv.FormatFloat(float64(vehicle.MeanValue), 'f', -1, 32),
+	}
+}
+
+type tableVehicleWriter struct {
+	w             *tabwriter.Writer
+	headerWritten bool
+}
+
+func (t *tableVehicleWriter) Write(vehicles []domain.Vehicle) error {
+	if !t.headerWritten {
+		t.headerWritten = true
+		if _, err := fmt.Fprintln(t.w, strings.ToUpper(strings.Join(vehicleColumns, "\t"))); err != nil {
+			return err
+		}
+	}
+	for _, vehicle := range vehicles {
+		if _, err := fmt.Fprintln(t.w, strings.Join(vehicleRecord(vehicle), "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tableVehicleWriter) Flush() error {
+	if err := t.Write(nil); err != nil {
+		return err
+	}
+	return t.w.Flush()
+}
+
+type csvVehicleWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvVehicleWriter) Write(vehicles []domain.Vehicle) error {
+	if !c.headerWritten {
+		c.headerWritten = true
+		if err := c.w.Write(vehicleColumns); err != nil {
+			return err
+		}
+	}
+	for _, vehicle := range vehicles {
+		if err := c.w.Write(vehicleRecord(vehicle)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *csvVehicleWriter) Flush() error {
+	if err := c.Write(nil); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonVehicleWriter writes a JSON array of the API representation, one vehicle per line, without
+// holding the whole array in memory.
+type jsonVehicleWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonVehicleWriter) Write(vehicles []domain.Vehicle) error {
+	for _, vehicle := range vehicles {
+		content, err := json.Marshal(dto.VehicleResponseFromDomain(vehicle))
+		if err != nil {
+			return err
+		}
+		separator := ",\n"
+		if j.count == 0 {
+			separator = "[\n"
+		}
+		if _, err := fmt.Fprintf(j.w, "%s%s", separator, content); err != nil {
+			return err
+		}
+		j.count++
+	}
+	return nil
+}
+
+func (j *jsonVehicleWriter) Flush() error {
+	if j.count == 0 {
+		_, err := fmt.Fprintln(j.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprint(j.w, "\n]\n")
+	return err
+}
+
+func readVehicles(r io.Reader, format string) ([]domain.Vehicle, error) {
+	switch format {
+	case formatCSV:
+		return readVehiclesCSV(r)
+	case formatJSON:
+		return readVehiclesJSON(r)
+	default:
+		return nil, fmt.Errorf("format must be %s or %s, got %q", formatCSV, formatJSON, format)
+	}
+}
+
+// readVehiclesCSV reads a CSV file with a header naming the vehicleColumns, in any order.
+func readVehiclesCSV(r io.Reader) ([]domain.Vehicle, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	indexes := map[string]int{}
+	for index, column := range header {
+		indexes[strings.TrimSpace(column)] = index
+	}
+	for _, column := range vehicleColumns {
+		if _, found := indexes[column]; !found {
+			return nil, fmt.Errorf("CSV header must have the columns %s, missing %q",
+				strings.Join(vehicleColumns, ","), column)
+		}
+	}
+
+	var vehicles []domain.Vehicle
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return vehicles, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		field := func(column string) string {
+			return strings.TrimSpace(record[indexes[column]])
+		}
+		year, err := strconv.Atoi(field("ano"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: ano must be an integer, got %q", line, field("ano"))
+		}
+		month, err := strconv.Atoi(field("mes"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: mes must be an integer, got %q", line, field("mes"))
+		}
+		meanValue, err := strconv.ParseFloat(field("valor_medio"), 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: valor_medio must be a number, got %q", line, field("valor_medio"))
+		}
+		vehicles = append(vehicles, domain.Vehicle{
+			Year:           year,
+			Month:          month,
+			FipeCode:       field("fipe_code"),
+			Brand:          field("marca"),
+			Model:          field("modelo"),
+			YearModel:      field("ano_modelo"),
+			Authentication: field("autenticacao"),
+			MeanValue:      float32(meanValue),
+		})
+	}
+}
+
+// readVehiclesJSON reads a JSON array of the API representation, as written by export.
+func readVehiclesJSON(r io.Reader) ([]domain.Vehicle, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var responses []dto.GetVehicleResponse
+	if err := decoder.Decode(&responses); err != nil {
+		return nil, fmt.Errorf("reading JSON: %w", err)
+	}
+
+	vehicles := make([]domain.Vehicle, 0, len(responses))
+	for _, response := range responses {
+		vehicles = append(vehicles, response.ToDomain())
+	}
+	return vehicles, nil
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+const vehiclesCSV = `ano,mes,fipe_code,marca,modelo,ano_modelo,autenticacao,valor_medio
+2021,7,111111-1,Acura,Integra GS 1.8,1992 Gasolina,1,700
+2021,8,111111-1,Acura,Integra GS 1.8,1991 Gasolina,2,900.5
+`
+
+func TestRunQuery(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+
+	tests := []struct {
+		name           string
+		args           []string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantCode       int
+		wantStdout     string
+		wantStderr     string
+	}{
+		{
+			name: "table",
+			args: []string{"query", "-where", "fipe_code:111111-1", "-order", "year:desc", "-limit", "1"},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Where("fipe_code", "111111-1").OrderBy("year", true).Page(0, 1).MustBuild()).
+					Return(vehicles[:1], nil)
+			},
+			wantCode: 0,
+			wantStdout: "ANO   MES  FIPE_CODE  MARCA  MODELO          ANO_MODELO     AUTENTICACAO  VALOR_MEDIO\n" +
+				"2021  7    111111-1   Acura  Integra GS 1.8  1992 Gasolina  1             700\n",
+		},
+		{
+			name: "csv",
+			args: []string{"query", "-where", "year:2021", "-order", "month:asc", "-offset", "10", "-format", "csv"},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Where("year", "2021").OrderBy("month", false).Page(10, domain.DefaultLimit).MustBuild()).
+					Return(vehicles[:1], nil)
+			},
+			wantCode: 0,
+			wantStdout: "ano,mes,fipe_code,marca,modelo,ano_modelo,autenticacao,valor_medio\n" +
+				"2021,7,111111-1,Acura,Integra GS 1.8,1992 Gasolina,1,700\n",
+		},
+		{
+			name: "json",
+			args: []string{"query", "-where", "year:2021", "-order", "month:asc", "-format", "json"},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().GetVehicle(gomock.Any(), gomock.Any()).Return(vehicles[:2], nil)
+			},
+			wantCode: 0,
+			wantStdout: "[\n" +
+				`{"ano":2021,"mes":7,"fipe_code":"111111-1","marca":"Acura","modelo":"Integra GS 1.8","ano_modelo":"1992 Gasolina","autenticacao":"1","valor_medio":700},` + "\n" +
+				`{"ano":2021,"mes":6,"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL","ano_modelo":"1991 Gasolina","autenticacao":"2","valor_medio":800}` + "\n" +
+				"]\n",
+		},
+		{
+			name: "not found is an empty result",
+			args: []string{"query", "-where", "year:2021", "-order", "month:asc", "-format", "json"},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().GetVehicle(gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewNotFoundError("Vehicles not found"))
+			},
+			wantCode:   0,
+			wantStdout: "[]\n",
+		},
+		{
+			name:           "validation error",
+			args:           []string{"query", "-where", "year:1800", "-order", "month:asc"},
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr:     "gofipe query: Invalid year\n",
+		},
+		{
+			name: "service error",
+			args: []string{"query", "-where", "year:2021", "-order", "month:asc"},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().GetVehicle(gomock.Any(), gomock.Any()).
+					Return(nil, errs.NewGatewayTimeoutError("Query timeout"))
+			},
+			wantCode:   1,
+			wantStderr: "gofipe query: Query timeout\n",
+		},
+		{
+			name:           "invalid where",
+			args:           []string{"query", "-where", "year", "-order", "month:asc"},
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr:     "gofipe query: Clausula where 0 deve ser no formato 'key:value'\n",
+		},
+		{
+			name:           "invalid format",
+			args:           []string{"query", "-where", "year:2021", "-order", "month:asc", "-format", "xml"},
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr:     "gofipe query: format must be table, csv or json, got \"xml\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, _, services := getMockServices(t)
+			tt.vehicleService(mockVehicleService)
+
+			var r testRun
+			assert.Equal(t, tt.wantCode, r.run(tt.args, services, nil))
+			assert.Equal(t, tt.wantStdout, r.stdout.String())
+			assert.Equal(t, tt.wantStderr, r.stderr.String())
+		})
+	}
+}
+
+func TestRunExport(t *testing.T) {
+	page := make([]domain.Vehicle, domain.MaxLimit)
+	for index := range page {
+		page[index] = domain.GetDomainVehiclesExamples()[0]
+	}
+	lastPage := domain.GetDomainVehiclesExamples()[:1]
+
+	t.Run("pages until a short page", func(t *testing.T) {
+		mockVehicleService, _, services := getMockServices(t)
+		query := domain.NewVehicleQueryBuilder().
+			Where("year", "2021").OrderBy("fipe_code", false).Page(0, domain.MaxLimit).MustBuild()
+		gomock.InOrder(
+			mockVehicleService.EXPECT().GetVehicle(gomock.Any(), query).Return(page, nil),
+			mockVehicleService.EXPECT().GetVehicle(gomock.Any(), query.NextPage()).Return(lastPage, nil),
+		)
+
+		output := filepath.Join(t.TempDir(), "vehicles.json")
+		var r testRun
+		assert.Equal(t, 0, r.run([]string{"export", "-where", "year:2021", "-output", output}, services, nil))
+		assert.Empty(t, r.stdout.String())
+
+		file, err := os.Open(output)
+		assert.Nil(t, err)
+		defer file.Close()
+		exported, err := readVehiclesJSON(file)
+		assert.Nil(t, err)
+		assert.Equal(t, append(page, lastPage...), exported)
+	})
+
+	t.Run("stops on not found", func(t *testing.T) {
+		mockVehicleService, _, services := getMockServices(t)
+		gomock.InOrder(
+			mockVehicleService.EXPECT().GetVehicle(gomock.Any(), gomock.Any()).Return(page, nil),
+			mockVehicleService.EXPECT().GetVehicle(gomock.Any(), gomock.Any()).
+				Return(nil, errs.NewNotFoundError("Vehicles not found")),
+		)
+
+		var r testRun
+		assert.Equal(t, 0, r.run([]string{"export", "-where", "year:2021"}, services, nil))
+		exported, err := readVehiclesCSV(&r.stdout)
+		assert.Nil(t, err)
+		assert.Equal(t, page, exported)
+	})
+}
+
+func TestRunIngest(t *testing.T) {
+	vehicles := []domain.Vehicle{
+		{Year: 2021, Month: 7, FipeCode: "111111-1", Brand: "Acura", Model: "Integra GS 1.8",
+			YearModel: "1992 Gasolina", Authentication: "1", MeanValue: 700},
+		{Year: 2021, Month: 8, FipeCode: "111111-1", Brand: "Acura", Model: "Integra GS 1.8",
+			YearModel: "1991 Gasolina", Authentication: "2", MeanValue: 900.5},
+	}
+	jsonFile := filepath.Join(t.TempDir(), "vehicles.json")
+	err := os.WriteFile(jsonFile, []byte(`[
+{"ano":2021,"mes":7,"fipe_code":"111111-1","marca":"Acura","modelo":"Integra GS 1.8","ano_modelo":"1992 Gasolina","autenticacao":"1","valor_medio":700},
+{"ano":2021,"mes":8,"fipe_code":"111111-1","marca":"Acura","modelo":"Integra GS 1.8","ano_modelo":"1991 Gasolina","autenticacao":"2","valor_medio":900.5}
+]`), 0o600)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name           string
+		args           []string
+		stdin          string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantCode       int
+		wantStdout     string
+		wantStderr     string
+	}{
+		{
+			name:  "csv from stdin",
+			args:  []string{"ingest", "-file", "-"},
+			stdin: vehiclesCSV,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().SaveVehicles(gomock.Any(), vehicles).Return(nil)
+			},
+			wantCode:   0,
+			wantStdout: "2 vehicles saved\n",
+		},
+		{
+			name: "json file",
+			args: []string{"ingest", "-file", jsonFile},
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().SaveVehicles(gomock.Any(), vehicles).Return(nil)
+			},
+			wantCode:   0,
+			wantStdout: "2 vehicles saved\n",
+		},
+		{
+			name:  "validation error",
+			args:  []string{"ingest", "-file", "-"},
+			stdin: vehiclesCSV,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().SaveVehicles(gomock.Any(), vehicles).
+					Return(errs.NewValidationError("Vehicle 1: Invalid year/month 2021/8"))
+			},
+			wantCode:   1,
+			wantStderr: "gofipe ingest: Vehicle 1: Invalid year/month 2021/8\n",
+		},
+		{
+			name:           "missing column",
+			args:           []string{"ingest", "-file", "-"},
+			stdin:          "ano,mes,fipe_code\n2021,7,111111-1\n",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr: "gofipe ingest: CSV header must have the columns " +
+				"ano,mes,fipe_code,marca,modelo,ano_modelo,autenticacao,valor_medio, missing \"marca\"\n",
+		},
+		{
+			name:           "invalid value",
+			args:           []string{"ingest", "-file", "-"},
+			stdin:          "ano,mes,fipe_code,marca,modelo,ano_modelo,autenticacao,valor_medio\n2021,jul,111111-1,,,,,1\n",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr:     "gofipe ingest: line 2: mes must be an integer, got \"jul\"\n",
+		},
+		{
+			name:           "file is required",
+			args:           []string{"ingest"},
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantCode:       1,
+			wantStderr:     "gofipe ingest: -file is required\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, _, services := getMockServices(t)
+			tt.vehicleService(mockVehicleService)
+
+			r := testRun{stdin: tt.stdin}
+			assert.Equal(t, tt.wantCode, r.run(tt.args, services, nil))
+			assert.Equal(t, tt.wantStdout, r.stdout.String())
+			assert.Equal(t, tt.wantStderr, r.stderr.String())
+		})
+	}
+}
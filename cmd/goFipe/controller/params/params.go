@@ -0,0 +1,152 @@
+// Package params parses the vehicle query syntax shared by the REST API and the command-line tools.
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+)
+
+// VehicleQuery builds the query of the where and order parameters, the period from from to to, formatted
+// as YYYY-MM and empty if open, and the page. Queries rejected by the domain rules are counted by reason.
+func VehicleQuery(where string, order string, from string, to string, offset int, limit int) (domain.VehicleQuery, *errs.AppError) {
+	builder := domain.NewVehicleQueryBuilder()
+	if err := ParseWhere(builder, where); err != nil {
+		return domain.VehicleQuery{}, err
+	}
+	if err := ParseOrderBy(builder, order); err != nil {
+		return domain.VehicleQuery{}, err
+	}
+	return Build(builder.Period(from, to).Page(offset, limit))
+}
+
+// Build builds the query, converting a rule it breaks to a ValidationError.
+func Build(builder *domain.VehicleQueryBuilder) (domain.VehicleQuery, *errs.AppError) {
+	query, err := builder.Build()
+	if err != nil {
+		return domain.VehicleQuery{}, validationError(err)
+	}
+	return query, nil
+}
+
+// Period parses the period of the from and to parameters, see domain.ParsePeriod.
+func Period(from string, to string) (domain.Period, *errs.AppError) {
+	period, err := domain.ParsePeriod(from, to)
+	if err != nil {
+		return domain.Period{}, validationError(err)
+	}
+	return period, nil
+}
+
+// TextSearch builds the free text search of the q parameter, see domain.NewTextSearch.
+func TextSearch(q string, offset int, limit int) (domain.TextSearch, *errs.AppError) {
+	search, err := domain.NewTextSearch(q, offset, limit)
+	if err != nil {
+		return domain.TextSearch{}, validationError(err)
+	}
+	return search, nil
+}
+
+// Autocomplete builds the autocomplete of the field and prefix parameters, see domain.NewAutocomplete.
+func Autocomplete(field string, prefix string, brand string, model string, limit int) (domain.Autocomplete, *errs.AppError) {
+	autocomplete, err := domain.NewAutocomplete(field, prefix, brand, model, limit)
+	if err != nil {
+		return domain.Autocomplete{}, validationError(err)
+	}
+	return autocomplete, nil
+}
+
+// Listing normalizes the description parameter, reading 2-digit years against clock, see domain.NewListing.
+func Listing(clock domain.Clock, description string) (domain.Listing, *errs.AppError) {
+	listing, err := domain.NewListing(clock, description)
+	if err != nil {
+		return domain.Listing{}, validationError(err)
+	}
+	return listing, nil
+}
+
+// validationError counts the broken rule by reason and converts it to a ValidationError.
+func validationError(err *domain.QueryError) *errs.AppError {
+	metrics.ValidationFailures.WithLabelValues(err.Reason).Inc()
+	return errs.NewValidationError(err.Message)
+}
+
+// ParseWhere parses the where parameter, a comma separated list of column:value clauses, adding a
+// filter to builder for each clause. A column may appear in several clauses, e.g. year:2020,year:2021.
+func ParseWhere(builder *domain.VehicleQueryBuilder, whereString string) *errs.AppError {
+	if len(strings.TrimSpace(whereString)) == 0 {
+		return errs.NewBadRequestError("Campo where deve possuir no minimo 1 clausula")
+	}
+
+	for index, split := range strings.Split(whereString, ",") {
+		key, value, ok := parseClause(split)
+		if !ok {
+			return errs.NewBadRequestError(
+				fmt.Sprintf("Clausula where %d deve ser no formato 'key:value'", index),
+			)
+		}
+		builder.Where(key, value)
+	}
+
+	return nil
+}
+
+// ParseOrderBy parses the order parameter, a comma separated list of column:asc or column:desc clauses
+// in decreasing sort priority, adding a sort to builder for each clause.
+func ParseOrderBy(builder *domain.VehicleQueryBuilder, orderByString string) *errs.AppError {
+	if len(strings.TrimSpace(orderByString)) == 0 {
+		return errs.NewBadRequestError("Campo order deve possuir no minimo 1 clausula")
+	}
+
+	for index, split := range strings.Split(orderByString, ",") {
+		key, value, ok := parseClause(split)
+		if !ok {
+			return errs.NewBadRequestError(
+				fmt.Sprintf("Clausula order %d deve ser no formato 'key:value'", index),
+			)
+		}
+
+		switch value {
+		case "asc":
+			builder.OrderBy(key, false)
+		case "desc":
+			builder.OrderBy(key, true)
+		default:
+			return errs.NewBadRequestError(
+				fmt.Sprintf("Clausula order %d: Value deve ser asc ou desc", index),
+			)
+		}
+	}
+
+	return nil
+}
+
+// ParseFields parses the fields parameter, a comma separated list of the fields to return, restricting
+// builder to them. An empty parameter returns every field.
+func ParseFields(builder *domain.VehicleQueryBuilder, fieldsString string) *errs.AppError {
+	if len(strings.TrimSpace(fieldsString)) == 0 {
+		return nil
+	}
+
+	fields := strings.Split(fieldsString, ",")
+	for index, field := range fields {
+		fields[index] = strings.TrimSpace(field)
+		if fields[index] == "" {
+			return errs.NewBadRequestError(fmt.Sprintf("Campo fields %d deve ser o nome de um campo", index))
+		}
+	}
+	builder.Select(fields...)
+	return nil
+}
+
+// parseClause splits a key:value clause, returning false if it is not in this format.
+func parseClause(clause string) (string, string, bool) {
+	keyValue := strings.Split(strings.TrimSpace(clause), ":")
+	if len(keyValue) != 2 || keyValue[0] == "" || keyValue[1] == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1]), true
+}
@@ -0,0 +1,232 @@
+package params
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWhere(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    domain.VehicleQuery
+		wantErr *errs.AppError
+	}{
+		{
+			name:    "Normal use case",
+			input:   "fipe_code:111111-1,year:2021,month:7",
+			want:    domain.NewVehicleQueryBuilder().Where("fipe_code", "111111-1").Where("year", "2021").Where("month", "7").MustBuild(),
+			wantErr: nil,
+		},
+		{
+			name:    "Repeated column",
+			input:   "year:2020, year:2021",
+			want:    domain.NewVehicleQueryBuilder().Where("year", "2020").Where("year", "2021").MustBuild(),
+			wantErr: nil,
+		},
+		{
+			name:    "Invalid where clause",
+			input:   "fipe_code:1,year:2021,month",
+			wantErr: errs.NewBadRequestError("Clausula where 2 deve ser no formato 'key:value'"),
+		},
+		{
+			name:    "Empty where clause",
+			input:   "",
+			wantErr: errs.NewBadRequestError("Campo where deve possuir no minimo 1 clausula"),
+		},
+		{
+			name:    "Invalid where clause",
+			input:   "fipe_code:1,year:2021,month:",
+			wantErr: errs.NewBadRequestError("Clausula where 2 deve ser no formato 'key:value'"),
+		},
+		{
+			name:    "Invalid where clause",
+			input:   "fipe_code:1,year:2021,month:7,",
+			wantErr: errs.NewBadRequestError("Clausula where 3 deve ser no formato 'key:value'"),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := domain.NewVehicleQueryBuilder()
+			gotErr := ParseWhere(builder, tt.input)
+			assert.Equalf(t, tt.wantErr, gotErr, "ParseWhere(%v)", tt.input)
+			if tt.wantErr == nil {
+				assert.Equalf(t, tt.want, builder.MustBuild(), "ParseWhere(%v)", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.VehicleQuery
+		wantErr *errs.AppError
+	}{
+		{
+			name:    "Test normal use case",
+			input:   "fipe_code:asc,mean_value:desc",
+			want:    domain.NewVehicleQueryBuilder().OrderBy("fipe_code", false).OrderBy("mean_value", true).MustBuild(),
+			wantErr: nil,
+		},
+		{
+			name:    "Test sort priority follows the clauses",
+			input:   "year:desc,month:desc,fipe_code:asc",
+			want:    domain.NewVehicleQueryBuilder().OrderBy("year", true).OrderBy("month", true).OrderBy("fipe_code", false).MustBuild(),
+			wantErr: nil,
+		},
+		{
+			name:    "Test with abnormal case, clauses with invalid format",
+			input:   "year:asc,month:desc,fipe_code",
+			wantErr: errs.NewBadRequestError("Clausula order 2 deve ser no formato 'key:value'"),
+		},
+		{
+			name:    "Test with abnormal case, empty value",
+			input:   "year:asc,month:",
+			wantErr: errs.NewBadRequestError("Clausula order 1 deve ser no formato 'key:value'"),
+		},
+		{
+			name:    "Test with abnormal case, invalid direction",
+			input:   "year:asc,month:up",
+			wantErr: errs.NewBadRequestError("Clausula order 1: Value deve ser asc ou desc"),
+		},
+		{
+			name:    "Test without clauses",
+			input:   "",
+			wantErr: errs.NewBadRequestError("Campo order deve possuir no minimo 1 clausula"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := domain.NewVehicleQueryBuilder()
+			gotErr := ParseOrderBy(builder, tt.input)
+			assert.Equalf(t, tt.wantErr, gotErr, "ParseOrderBy(%v)", tt.input)
+			if tt.wantErr == nil {
+				assert.Equalf(t, tt.want, builder.MustBuild(), "ParseOrderBy(%v)", tt.input)
+			}
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.VehicleQuery
+		wantErr *errs.AppError
+	}{
+		{
+			name:  "Test normal use case",
+			input: "fipe_code, mean_value",
+			want:  domain.NewVehicleQueryBuilder().Select("fipe_code", "mean_value").MustBuild(),
+		},
+		{
+			name:  "Test without fields, every field",
+			input: "",
+			want:  domain.NewVehicleQueryBuilder().MustBuild(),
+		},
+		{
+			name:    "Test with abnormal case, empty field",
+			input:   "fipe_code,,mean_value",
+			wantErr: errs.NewBadRequestError("Campo fields 1 deve ser o nome de um campo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := domain.NewVehicleQueryBuilder()
+			gotErr := ParseFields(builder, tt.input)
+			assert.Equalf(t, tt.wantErr, gotErr, "ParseFields(%v)", tt.input)
+			if tt.wantErr == nil {
+				assert.Equalf(t, tt.want, builder.MustBuild(), "ParseFields(%v)", tt.input)
+			}
+		})
+	}
+}
+
+func TestVehicleQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		where   string
+		order   string
+		from    string
+		to      string
+		offset  int
+		limit   int
+		want    domain.VehicleQuery
+		wantErr *errs.AppError
+	}{
+		{
+			name:   "valid query",
+			where:  "year:2021,month:7",
+			order:  "mean_value:desc",
+			offset: 10,
+			limit:  5,
+			want: domain.NewVehicleQueryBuilder().
+				Where("year", "2021").Where("month", "7").OrderBy("mean_value", true).Page(10, 5).MustBuild(),
+		},
+		{
+			name:  "period",
+			where: "fipe_code:111111-1",
+			order: "year:asc,month:asc",
+			from:  "2021-01",
+			to:    "2021-07",
+			limit: 5,
+			want: domain.NewVehicleQueryBuilder().
+				Where("fipe_code", "111111-1").OrderBy("year", false).OrderBy("month", false).
+				Period("2021-01", "2021-07").Page(0, 5).MustBuild(),
+		},
+		{
+			name:    "invalid period",
+			where:   "year:2021",
+			order:   "year:desc",
+			from:    "2021-08",
+			to:      "2021-07",
+			limit:   5,
+			wantErr: errs.NewValidationError("From must not be after to"),
+		},
+		{
+			name:    "syntax error",
+			where:   "year",
+			order:   "mean_value:desc",
+			limit:   5,
+			wantErr: errs.NewBadRequestError("Clausula where 0 deve ser no formato 'key:value'"),
+		},
+		{
+			name:    "domain rule broken",
+			where:   "year:2021",
+			order:   "year:desc,year:asc",
+			limit:   5,
+			wantErr: errs.NewValidationError("Duplicated column: year"),
+		},
+		{
+			name:    "invalid page",
+			where:   "year:2021",
+			order:   "year:desc",
+			limit:   0,
+			wantErr: errs.NewValidationError(fmt.Sprintf("Limit must be between 1 and %d", domain.MaxLimit)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotErr := VehicleQuery(tt.where, tt.order, tt.from, tt.to, tt.offset, tt.limit)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, gotErr)
+		})
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	got, err := Period("2021-01", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "2021-01..", got.String())
+
+	got, err = Period("2021-08", "2021-07")
+	assert.Equal(t, domain.Period{}, got)
+	assert.Equal(t, errs.NewValidationError("From must not be after to"), err)
+}
@@ -1,55 +1,109 @@
 package rest
 
 import (
+	"context"
 	"fmt"
 	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/config"
 	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/handler"
 	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/middleware"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
 	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
 	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"net"
 	"net/http"
-	"os"
+	"time"
 )
 
-func Start(vehicleService ports.VehicleService) {
-	sanityCheck()
+// v1Sunset is when the API v1 routes of the vehicles, deprecated by their /v2 successors, are removed.
+var v1Sunset = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+// Start serves the API until ctx is done and then shuts the server down gracefully. loggerSettings
+// backs the /admin/logger endpoints.
+func Start(
+	ctx context.Context,
+	config config.Config,
+	log logger.Logger,
+	loggerSettings handler.LoggerSettings,
+	vehicleService ports.VehicleService,
+	apiKeyService ports.ApiKeyService,
+	healthService ports.HealthService) error {
 	router := mux.NewRouter()
-	vehicleHandler := handler.NewVehicleHandler(vehicleService)
+	router.Use(
+		middleware.TracingMiddleware(),
+		middleware.MetricsMiddleware(),
+		middleware.RecoveryMiddleware(log, nil),
+		middleware.TimeoutMiddleware(config.Server.RequestTimeout, config.Server.RouteTimeouts),
+	)
+	vehicleHandler := handler.NewVehicleHandler(vehicleService, log)
+	apiKeyHandler := handler.NewApiKeyHandler(apiKeyService, log)
+	healthHandler := handler.NewHealthHandler(healthService, log)
+	loggerHandler := handler.NewLoggerHandler(loggerSettings, log)
 	router.HandleFunc("/health-check", healthCheck).Methods("GET")
-	router.HandleFunc("/vehicles", vehicleHandler.Get).Methods("GET")
+	router.HandleFunc("/livez", healthHandler.Livez).Methods("GET")
+	router.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
 
-	appHost := os.Getenv("APP_HOST")
-	appPort := os.Getenv("APP_PORT")
-	loggedRouter := middleware.LoggingMiddleware()(router)
+	adminRouter := router.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.AdminMiddleware(config.AdminToken))
+	adminRouter.HandleFunc("/api-keys", apiKeyHandler.Create).Methods("POST")
+	adminRouter.HandleFunc("/api-keys", apiKeyHandler.List).Methods("GET")
+	adminRouter.HandleFunc("/api-keys/{id}", apiKeyHandler.Revoke).Methods("DELETE")
+	adminRouter.HandleFunc("/api-keys/{id}/usage", apiKeyHandler.GetUsage).Methods("GET")
+	adminRouter.HandleFunc("/logger", loggerHandler.Get).Methods("GET")
+	adminRouter.HandleFunc("/logger", loggerHandler.Update).Methods("PUT")
 
-	logger.Info(
-		"Started server",
-		logger.String("host", appHost),
-		logger.String("port", appPort),
-	)
-	err := http.ListenAndServe(
-		fmt.Sprintf("%s:%s", appHost, appPort),
-		loggedRouter,
+	rateLimitBackend := middleware.NewInMemoryRateLimitBackend()
+	ipRateLimit := middleware.RateLimit(config.RateLimit.IP)
+	apiKeyRateLimit := middleware.RateLimit(config.RateLimit.ApiKey)
+
+	apiRouter := router.NewRoute().Subrouter()
+	apiRouter.Use(
+		middleware.RateLimitMiddleware(rateLimitBackend, ipRateLimit, middleware.ClientIPKey, log),
+		middleware.AuthMiddleware(apiKeyService, log),
+		middleware.RateLimitMiddleware(rateLimitBackend, apiKeyRateLimit, middleware.ApiKeyKey, log),
+		middleware.UsageMiddleware(apiKeyService, log),
 	)
-	if err != nil {
-		logger.Fatal("Error starting server", logger.String("error", err.Error()))
-	}
-}
+	deprecated := middleware.DeprecationMiddleware("/v2", v1Sunset)
+	apiRouter.Handle("/vehicles", deprecated(http.HandlerFunc(vehicleHandler.Get))).Methods("GET")
+	apiRouter.Handle("/vehicles/search", deprecated(http.HandlerFunc(vehicleHandler.Search))).Methods("POST")
+	apiRouter.Handle("/vehicles/search", deprecated(http.HandlerFunc(vehicleHandler.SearchText))).Methods("GET")
+	apiRouter.Handle("/vehicles/batch", deprecated(http.HandlerFunc(vehicleHandler.Batch))).Methods("POST")
+	apiRouter.Handle("/vehicles/{fipe_code}/history", deprecated(http.HandlerFunc(vehicleHandler.GetHistory))).Methods("GET")
+	apiRouter.Handle("/vehicles/{fipe_code}/price", deprecated(http.HandlerFunc(vehicleHandler.GetPriceAsOf))).Methods("GET")
+	apiRouter.Handle("/autocomplete", deprecated(http.HandlerFunc(vehicleHandler.Autocomplete))).Methods("GET")
 
-func sanityCheck() {
-	if _, ok := os.LookupEnv("APP_HOST"); !ok {
-		logger.Fatal("APP_HOST environment variable is not set")
-	}
+	v2Router := apiRouter.PathPrefix("/v2").Subrouter()
+	v2Router.HandleFunc("/vehicles", vehicleHandler.GetV2).Methods("GET")
+	v2Router.HandleFunc("/vehicles/search", vehicleHandler.SearchV2).Methods("POST")
+	v2Router.HandleFunc("/vehicles/search", vehicleHandler.SearchTextV2).Methods("GET")
+	v2Router.HandleFunc("/vehicles/batch", vehicleHandler.BatchV2).Methods("POST")
+	v2Router.HandleFunc("/vehicles/{fipe_code}/history", vehicleHandler.GetHistoryV2).Methods("GET")
+	v2Router.HandleFunc("/vehicles/{fipe_code}/price", vehicleHandler.GetPriceAsOfV2).Methods("GET")
+	v2Router.HandleFunc("/vehicles/match", vehicleHandler.MatchListing).Methods("GET")
+	v2Router.HandleFunc("/vehicles/match/batch", vehicleHandler.MatchListings).Methods("POST")
+	v2Router.HandleFunc("/autocomplete", vehicleHandler.Autocomplete).Methods("GET")
 
-	if _, ok := os.LookupEnv("APP_PORT"); !ok {
-		logger.Fatal("APP_PORT environment variable is not set")
+	listener, err := net.Listen("tcp", config.Server.Addr())
+	if err != nil {
+		return fmt.Errorf("listening on %s: %w", config.Server.Addr(), err)
 	}
+	loggedRouter := middleware.LoggingMiddleware(log, middleware.BodyLogging(config.Log.Body))(router)
+
+	log.Info(
+		"Started server",
+		logger.String("host", config.Server.Host),
+		logger.Int("port", config.Server.Port),
+	)
+	return serve(ctx, newServer(loggedRouter, config.Server), listener, config.Server.ShutdownTimeout, log)
 }
 
-func healthCheck(w http.ResponseWriter, _ *http.Request) {
+// healthCheck is kept for existing monitors; prefer /livez and /readyz.
+func healthCheck(w http.ResponseWriter, r *http.Request) {
 	_, err := w.Write([]byte("Ok"))
 	if err != nil {
-		http.Error(w, "Erro ao serializar resposta", http.StatusInternalServerError)
+		response.Error(w, r, "Erro ao serializar resposta", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
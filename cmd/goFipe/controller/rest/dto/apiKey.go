@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+)
+
+type CreateApiKeyRequest struct {
+	Name         string `json:"name"`
+	MonthlyQuota int    `json:"monthly_quota"`
+}
+
+type ApiKeyResponse struct {
+	ID           uint       `json:"id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	MonthlyQuota int        `json:"monthly_quota"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateApiKeyResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+type ApiKeyUsageResponse struct {
+	ApiKeyID uint `json:"api_key_id"`
+	Year     int  `json:"ano"`
+	Month    int  `json:"mes"`
+	Requests int  `json:"requests"`
+}
+
+func ApiKeyResponseFromDomain(apiKey domain.ApiKey) ApiKeyResponse {
+	return ApiKeyResponse{
+		ID:           apiKey.ID,
+		Name:         apiKey.Name,
+		Prefix:       apiKey.Prefix,
+		MonthlyQuota: apiKey.MonthlyQuota,
+		CreatedAt:    apiKey.CreatedAt,
+		RevokedAt:    apiKey.RevokedAt,
+	}
+}
+
+func ApiKeyUsageResponseFromDomain(usage domain.ApiKeyUsage) ApiKeyUsageResponse {
+	return ApiKeyUsageResponse{
+		ApiKeyID: usage.ApiKeyID,
+		Year:     usage.Year,
+		Month:    usage.Month,
+		Requests: usage.Requests,
+	}
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// AutocompleteResponse is the body of GET /v2/autocomplete and GET /autocomplete: the suggested values of
+// a field, each with the number of fipe codes that have it.
+type AutocompleteResponse struct {
+	Field  string                      `json:"field"`
+	Values []AutocompleteValueResponse `json:"values"`
+}
+
+type AutocompleteValueResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+func AutocompleteResponseFromDomain(autocomplete domain.Autocomplete, values []domain.AutocompleteValue) AutocompleteResponse {
+	valuesResponse := make([]AutocompleteValueResponse, 0, len(values))
+	for _, value := range values {
+		valuesResponse = append(valuesResponse, AutocompleteValueResponse{Value: value.Value, Count: value.Count})
+	}
+	return AutocompleteResponse{Field: string(autocomplete.Field()), Values: valuesResponse}
+}
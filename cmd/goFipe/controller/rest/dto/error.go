@@ -0,0 +1,16 @@
+package dto
+
+type ErrorResponse struct {
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ProblemResponse is an RFC 9457 problem details body, served as application/problem+json.
+type ProblemResponse struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
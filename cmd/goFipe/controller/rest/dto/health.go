@@ -0,0 +1,24 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+type HealthResponse struct {
+	Status string                              `json:"status"`
+	Checks map[string]DependencyHealthResponse `json:"checks,omitempty"`
+}
+
+type DependencyHealthResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+func HealthResponseFromDomain(health domain.Health) HealthResponse {
+	checks := make(map[string]DependencyHealthResponse, len(health.Dependencies))
+	for _, dependency := range health.Dependencies {
+		checks[dependency.Name] = DependencyHealthResponse{
+			Status:  string(dependency.Status),
+			Message: dependency.Message,
+		}
+	}
+	return HealthResponse{Status: string(health.Status()), Checks: checks}
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"math"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+)
+
+// ListingMatchResponse is a match of GET /v2/vehicles/match, with its confidence from 0 to 1 rounded to 3
+// decimals.
+type ListingMatchResponse struct {
+	Vehicle    VehicleV2Response `json:"vehicle"`
+	Confidence float64           `json:"confidence"`
+}
+
+func ListingMatchesResponseFromDomain(matches []domain.ListingMatch) []ListingMatchResponse {
+	response := make([]ListingMatchResponse, 0, len(matches))
+	for _, match := range matches {
+		response = append(response, ListingMatchResponse{
+			Vehicle:    VehicleV2ResponseFromDomain(match.Vehicle, domain.VehicleFields),
+			Confidence: math.Round(match.Confidence*1000) / 1000,
+		})
+	}
+	return response
+}
+
+// BatchListingRequest is the body of POST /v2/vehicles/match/batch, e.g.
+//
+//	{"items": [{"description": "FIAT 147 CL 1991 GAS"}], "limit": 3}
+//
+// An omitted limit returns domain.DefaultListingMatches matches for each item.
+type BatchListingRequest struct {
+	Items []BatchListingItem `json:"items"`
+	Limit int                `json:"limit"`
+}
+
+type BatchListingItem struct {
+	Description string `json:"description"`
+}
+
+// Descriptions returns the description of each item, in order.
+func (b BatchListingRequest) Descriptions() []string {
+	descriptions := make([]string, 0, len(b.Items))
+	for _, item := range b.Items {
+		descriptions = append(descriptions, item.Description)
+	}
+	return descriptions
+}
+
+// BatchListingResponse has an item for each item of the request, in the same order.
+type BatchListingResponse struct {
+	Items []BatchListingItemResponse `json:"items"`
+}
+
+type BatchListingItemResponse struct {
+	Description string                 `json:"description"`
+	Status      string                 `json:"status"`
+	Message     string                 `json:"message,omitempty"`
+	Matches     []ListingMatchResponse `json:"matches,omitempty"`
+}
+
+func BatchListingResponseFromDomain(results []domain.ListingMatchResult) BatchListingResponse {
+	items := make([]BatchListingItemResponse, 0, len(results))
+	for _, result := range results {
+		item := BatchListingItemResponse{
+			Description: result.Description,
+			Status:      string(result.Status),
+			Message:     result.Message,
+		}
+		if result.Status == domain.LookupFound {
+			item.Matches = ListingMatchesResponseFromDomain(result.Matches)
+		}
+		items = append(items, item)
+	}
+	return BatchListingResponse{Items: items}
+}
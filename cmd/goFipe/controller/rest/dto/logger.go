@@ -0,0 +1,8 @@
+package dto
+
+// LoggerSettings is both the response of GET /admin/logger and the body of PUT /admin/logger, where
+// an empty field keeps the current value.
+type LoggerSettings struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
@@ -25,3 +25,55 @@ func VehicleResponseFromDomain(vehicle domain.Vehicle) GetVehicleResponse {
 		MeanValue:      vehicle.MeanValue,
 	}
 }
+
+// ToDomain converts a vehicle in the API representation, e.g. read from an exported file, to the domain.
+func (v GetVehicleResponse) ToDomain() domain.Vehicle {
+	return domain.Vehicle{
+		Year:           v.Year,
+		Month:          v.Month,
+		FipeCode:       v.FipeCode,
+		Brand:          v.Brand,
+		Model:          v.Model,
+		YearModel:      v.YearModel,
+		Authentication: v.Authentication,
+		MeanValue:      v.MeanValue,
+	}
+}
+
+// SparseVehicleResponse is a GetVehicleResponse restricted to the fields selected by a query; the other
+// fields are omitted.
+type SparseVehicleResponse struct {
+	Year           *int     `json:"ano,omitempty"`
+	Month          *int     `json:"mes,omitempty"`
+	FipeCode       *string  `json:"fipe_code,omitempty"`
+	Brand          *string  `json:"marca,omitempty"`
+	Model          *string  `json:"modelo,omitempty"`
+	YearModel      *string  `json:"ano_modelo,omitempty"`
+	Authentication *string  `json:"autenticacao,omitempty"`
+	MeanValue      *float32 `json:"valor_medio,omitempty"`
+}
+
+func SparseVehicleResponseFromDomain(vehicle domain.Vehicle, fields []domain.VehicleField) SparseVehicleResponse {
+	var response SparseVehicleResponse
+	for _, field := range fields {
+		switch field {
+		case domain.VehicleFieldYear:
+			response.Year = &vehicle.Year
+		case domain.VehicleFieldMonth:
+			response.Month = &vehicle.Month
+		case domain.VehicleFieldFipeCode:
+			response.FipeCode = &vehicle.FipeCode
+		case domain.VehicleFieldBrand:
+			response.Brand = &vehicle.Brand
+		case domain.VehicleFieldModel:
+			response.Model = &vehicle.Model
+		case domain.VehicleFieldYearModel:
+			response.YearModel = &vehicle.YearModel
+		case domain.VehicleFieldAuthentication:
+			response.Authentication = &vehicle.Authentication
+		case domain.VehicleFieldMeanValue:
+			response.MeanValue = &vehicle.MeanValue
+		}
+	}
+	return response
+}
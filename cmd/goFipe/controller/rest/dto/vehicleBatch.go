@@ -0,0 +1,98 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// BatchVehicleRequest is the body of POST /vehicles/batch, e.g.
+//
+//	{"items": [{"fipe_code": "222222-2", "year_model": "1991 Gasolina", "reference_month": "2021-07"}]}
+type BatchVehicleRequest struct {
+	Items []BatchVehicleItem `json:"items"`
+}
+
+type BatchVehicleItem struct {
+	FipeCode       string `json:"fipe_code"`
+	YearModel      string `json:"year_model"`
+	ReferenceMonth string `json:"reference_month"`
+}
+
+// ToDomain converts the items to lookups. A reference month not formatted as YYYY-MM is left zero, so
+// the service reports only that item as invalid.
+func (b BatchVehicleRequest) ToDomain() []domain.VehicleLookup {
+	lookups := make([]domain.VehicleLookup, 0, len(b.Items))
+	for _, item := range b.Items {
+		referenceMonth, _ := domain.ParseReferenceMonth(item.ReferenceMonth)
+		lookups = append(lookups, domain.VehicleLookup{
+			FipeCode:       item.FipeCode,
+			YearModel:      item.YearModel,
+			ReferenceMonth: referenceMonth,
+		})
+	}
+	return lookups
+}
+
+// BatchVehicleResponse has an item for each item of the request, in the same order.
+type BatchVehicleResponse struct {
+	Items []BatchVehicleItemResponse `json:"items"`
+}
+
+type BatchVehicleItemResponse struct {
+	FipeCode       string              `json:"fipe_code"`
+	YearModel      string              `json:"ano_modelo"`
+	ReferenceMonth string              `json:"mes_referencia"`
+	Status         string              `json:"status"`
+	Message        string              `json:"mensagem,omitempty"`
+	Vehicle        *GetVehicleResponse `json:"veiculo,omitempty"`
+}
+
+func BatchVehicleResponseFromDomain(results []domain.VehicleLookupResult) BatchVehicleResponse {
+	items := make([]BatchVehicleItemResponse, 0, len(results))
+	for _, result := range results {
+		item := BatchVehicleItemResponse{
+			FipeCode:       result.Lookup.FipeCode,
+			YearModel:      result.Lookup.YearModel,
+			ReferenceMonth: result.Lookup.ReferenceMonth.String(),
+			Status:         string(result.Status),
+			Message:        result.Message,
+		}
+		if result.Status == domain.LookupFound {
+			vehicle := VehicleResponseFromDomain(result.Vehicle)
+			item.Vehicle = &vehicle
+		}
+		items = append(items, item)
+	}
+	return BatchVehicleResponse{Items: items}
+}
+
+// BatchVehicleV2Response is the body of POST /v2/vehicles/batch: BatchVehicleResponse with English field
+// names and the vehicles as VehicleV2Response.
+type BatchVehicleV2Response struct {
+	Items []BatchVehicleItemV2Response `json:"items"`
+}
+
+type BatchVehicleItemV2Response struct {
+	FipeCode       string             `json:"fipe_code"`
+	YearModel      string             `json:"year_model"`
+	ReferenceMonth string             `json:"reference_month"`
+	Status         string             `json:"status"`
+	Message        string             `json:"message,omitempty"`
+	Vehicle        *VehicleV2Response `json:"vehicle,omitempty"`
+}
+
+func BatchVehicleV2ResponseFromDomain(results []domain.VehicleLookupResult) BatchVehicleV2Response {
+	items := make([]BatchVehicleItemV2Response, 0, len(results))
+	for _, result := range results {
+		item := BatchVehicleItemV2Response{
+			FipeCode:       result.Lookup.FipeCode,
+			YearModel:      result.Lookup.YearModel,
+			ReferenceMonth: result.Lookup.ReferenceMonth.String(),
+			Status:         string(result.Status),
+			Message:        result.Message,
+		}
+		if result.Status == domain.LookupFound {
+			vehicle := VehicleV2ResponseFromDomain(result.Vehicle, domain.VehicleFields)
+			item.Vehicle = &vehicle
+		}
+		items = append(items, item)
+	}
+	return BatchVehicleV2Response{Items: items}
+}
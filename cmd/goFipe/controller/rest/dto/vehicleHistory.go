@@ -0,0 +1,75 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// VehicleHistoryResponse is the body of GET /vehicles/{fipe_code}/history. Reference months are
+// formatted as YYYY-MM.
+type VehicleHistoryResponse struct {
+	FipeCode string                 `json:"fipe_code"`
+	Brand    string                 `json:"marca"`
+	Model    string                 `json:"modelo"`
+	From     string                 `json:"de"`
+	To       string                 `json:"ate"`
+	Prices   []VehiclePriceResponse `json:"precos"`
+}
+
+type VehiclePriceResponse struct {
+	ReferenceMonth string  `json:"mes_referencia"`
+	YearModel      string  `json:"ano_modelo"`
+	MeanValue      float32 `json:"valor_medio"`
+}
+
+func VehicleHistoryResponseFromDomain(history domain.VehicleHistory) VehicleHistoryResponse {
+	prices := make([]VehiclePriceResponse, 0, len(history.Prices))
+	for _, price := range history.Prices {
+		prices = append(prices, VehiclePriceResponse{
+			ReferenceMonth: price.ReferenceMonth.String(),
+			YearModel:      price.YearModel,
+			MeanValue:      price.MeanValue,
+		})
+	}
+	return VehicleHistoryResponse{
+		FipeCode: history.FipeCode,
+		Brand:    history.Brand,
+		Model:    history.Model,
+		From:     history.Period.From.String(),
+		To:       history.Period.To.String(),
+		Prices:   prices,
+	}
+}
+
+// VehicleHistoryV2Response is the body of GET /v2/vehicles/{fipe_code}/history: VehicleHistoryResponse
+// with English field names.
+type VehicleHistoryV2Response struct {
+	FipeCode string                   `json:"fipe_code"`
+	Brand    string                   `json:"brand"`
+	Model    string                   `json:"model"`
+	From     string                   `json:"from"`
+	To       string                   `json:"to"`
+	Prices   []VehiclePriceV2Response `json:"prices"`
+}
+
+type VehiclePriceV2Response struct {
+	ReferenceMonth string  `json:"reference_month"`
+	YearModel      string  `json:"year_model"`
+	MeanValue      float32 `json:"mean_value"`
+}
+
+func VehicleHistoryV2ResponseFromDomain(history domain.VehicleHistory) VehicleHistoryV2Response {
+	prices := make([]VehiclePriceV2Response, 0, len(history.Prices))
+	for _, price := range history.Prices {
+		prices = append(prices, VehiclePriceV2Response{
+			ReferenceMonth: price.ReferenceMonth.String(),
+			YearModel:      price.YearModel,
+			MeanValue:      price.MeanValue,
+		})
+	}
+	return VehicleHistoryV2Response{
+		FipeCode: history.FipeCode,
+		Brand:    history.Brand,
+		Model:    history.Model,
+		From:     history.Period.From.String(),
+		To:       history.Period.To.String(),
+		Prices:   prices,
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// VehicleMatchResponse is a match of GET /vehicles/search?q=, scored from 0 to 1.
+type VehicleMatchResponse struct {
+	Vehicle GetVehicleResponse `json:"veiculo"`
+	Score   float64            `json:"relevancia"`
+}
+
+func VehicleMatchResponseFromDomain(match domain.VehicleMatch) VehicleMatchResponse {
+	return VehicleMatchResponse{Vehicle: VehicleResponseFromDomain(match.Vehicle), Score: match.Score}
+}
+
+// VehicleMatchV2Response is a match of GET /v2/vehicles/search?q=, scored from 0 to 1.
+type VehicleMatchV2Response struct {
+	Vehicle VehicleV2Response `json:"vehicle"`
+	Score   float64           `json:"score"`
+}
+
+func VehicleMatchV2ResponseFromDomain(match domain.VehicleMatch) VehicleMatchV2Response {
+	return VehicleMatchV2Response{
+		Vehicle: VehicleV2ResponseFromDomain(match.Vehicle, domain.VehicleFields),
+		Score:   match.Score,
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// VehiclePriceAsOfResponse is the body of GET /vehicles/{fipe_code}/price. The date is formatted as
+// YYYY-MM-DD and the reference month the price came from as YYYY-MM.
+type VehiclePriceAsOfResponse struct {
+	FipeCode       string  `json:"fipe_code"`
+	Brand          string  `json:"marca"`
+	Model          string  `json:"modelo"`
+	YearModel      string  `json:"ano_modelo"`
+	AsOf           string  `json:"data"`
+	ReferenceMonth string  `json:"mes_referencia"`
+	MeanValue      float32 `json:"valor_medio"`
+}
+
+func VehiclePriceAsOfResponseFromDomain(price domain.VehiclePriceAsOf) VehiclePriceAsOfResponse {
+	return VehiclePriceAsOfResponse{
+		FipeCode:       price.FipeCode,
+		Brand:          price.Brand,
+		Model:          price.Model,
+		YearModel:      price.Price.YearModel,
+		AsOf:           price.AsOf.Format(domain.AsOfLayout),
+		ReferenceMonth: price.Price.ReferenceMonth.String(),
+		MeanValue:      price.Price.MeanValue,
+	}
+}
+
+// VehiclePriceAsOfV2Response is the body of GET /v2/vehicles/{fipe_code}/price: VehiclePriceAsOfResponse
+// with English field names.
+type VehiclePriceAsOfV2Response struct {
+	FipeCode       string  `json:"fipe_code"`
+	Brand          string  `json:"brand"`
+	Model          string  `json:"model"`
+	YearModel      string  `json:"year_model"`
+	AsOf           string  `json:"as_of"`
+	ReferenceMonth string  `json:"reference_month"`
+	MeanValue      float32 `json:"mean_value"`
+}
+
+func VehiclePriceAsOfV2ResponseFromDomain(price domain.VehiclePriceAsOf) VehiclePriceAsOfV2Response {
+	return VehiclePriceAsOfV2Response{
+		FipeCode:       price.FipeCode,
+		Brand:          price.Brand,
+		Model:          price.Model,
+		YearModel:      price.Price.YearModel,
+		AsOf:           price.AsOf.Format(domain.AsOfLayout),
+		ReferenceMonth: price.Price.ReferenceMonth.String(),
+		MeanValue:      price.Price.MeanValue,
+	}
+}
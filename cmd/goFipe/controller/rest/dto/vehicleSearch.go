@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+)
+
+// SearchVehicleRequest is the body of POST /vehicles/search. An omitted limit is domain.DefaultLimit
+// and omitted fields are every field.
+type SearchVehicleRequest struct {
+	Filter *SearchFilter `json:"filter"`
+	Order  []SearchSort  `json:"order"`
+	Fields []string      `json:"fields"`
+	Offset int           `json:"offset"`
+	Limit  *int          `json:"limit"`
+}
+
+// SearchFilter is a node of the filter tree: either a group, with exactly one of And, Or and Not, or a
+// condition, with Field, Op and Value. Value is a string, a number or a list of them, e.g.
+//
+//	{"and": [
+//	  {"or": [{"field": "brand", "op": "eq", "value": "Fiat"}, {"field": "brand", "op": "eq", "value": "VW"}]},
+//	  {"field": "mean_value", "op": "between", "value": [30000, 50000]}
+//	]}
+type SearchFilter struct {
+	And   []SearchFilter  `json:"and,omitempty"`
+	Or    []SearchFilter  `json:"or,omitempty"`
+	Not   *SearchFilter   `json:"not,omitempty"`
+	Field string          `json:"field,omitempty"`
+	Op    string          `json:"op,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type SearchSort struct {
+	Field     string `json:"field"`
+	Direction string `json:"direction"`
+}
+
+// ToDomain converts the tree to the domain. Only its shape is checked here: fields, operators and
+// values are validated by domain.VehicleQueryBuilder.Match.
+func (f SearchFilter) ToDomain() (domain.FilterNode, *errs.AppError) {
+	groups := 0
+	for _, isSet := range []bool{f.And != nil, f.Or != nil, f.Not != nil, f.Field != ""} {
+		if isSet {
+			groups++
+		}
+	}
+	if groups != 1 {
+		return domain.FilterNode{}, errs.NewBadRequestError("Filtro deve possuir exatamente um de and, or, not ou field")
+	}
+
+	switch {
+	case f.And != nil:
+		children, err := filtersToDomain(f.And)
+		return domain.And(children...), err
+	case f.Or != nil:
+		children, err := filtersToDomain(f.Or)
+		return domain.Or(children...), err
+	case f.Not != nil:
+		child, err := f.Not.ToDomain()
+		return domain.Not(child), err
+	}
+
+	values, ok := filterValues(f.Value)
+	if !ok {
+		return domain.FilterNode{}, errs.NewBadRequestError(
+			fmt.Sprintf("Filtro %s: value deve ser um texto, um numero ou uma lista deles", f.Field),
+		)
+	}
+	return domain.Compare(f.Field, f.Op, values...), nil
+}
+
+func filtersToDomain(filters []SearchFilter) ([]domain.FilterNode, *errs.AppError) {
+	nodes := make([]domain.FilterNode, 0, len(filters))
+	for _, filter := range filters {
+		node, err := filter.ToDomain()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// filterValues returns the text of a scalar value or of each element of a list of them, numbers as
+// written in the request.
+func filterValues(raw json.RawMessage) ([]string, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, false
+	}
+	if raw[0] != '[' {
+		value, ok := filterValue(raw)
+		return []string{value}, ok
+	}
+
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil {
+		return nil, false
+	}
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		value, ok := filterValue(bytes.TrimSpace(element))
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
+
+func filterValue(raw json.RawMessage) (string, bool) {
+	if len(raw) > 0 && raw[0] == '"' {
+		var text string
+		err := json.Unmarshal(raw, &text)
+		return text, err == nil
+	}
+	var number json.Number
+	err := json.Unmarshal(raw, &number)
+	return number.String(), err == nil && number != ""
+}
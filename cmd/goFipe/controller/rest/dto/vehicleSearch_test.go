@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFilter_ToDomain(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    domain.FilterNode
+		wantErr *errs.AppError
+	}{
+		{
+			name: "nested groups",
+			filter: `{"and": [
+				{"or": [{"field": "brand", "op": "eq", "value": "Fiat"}, {"field": "brand", "op": "eq", "value": "VW"}]},
+				{"field": "mean_value", "op": "between", "value": [30000, 50000.5]},
+				{"not": {"field": "year", "op": "eq", "value": 2021}}
+			]}`,
+			want: domain.And(
+				domain.Or(domain.Compare("brand", "eq", "Fiat"), domain.Compare("brand", "eq", "VW")),
+				domain.Compare("mean_value", "between", "30000", "50000.5"),
+				domain.Not(domain.Compare("year", "eq", "2021")),
+			),
+		},
+		{
+			name:   "fields and operators are validated by the domain",
+			filter: `{"field": "price", "op": "like", "value": "1"}`,
+			want:   domain.Compare("price", "like", "1"),
+		},
+		{
+			name:    "group and condition",
+			filter:  `{"and": [], "field": "year", "op": "eq", "value": 2021}`,
+			wantErr: errs.NewBadRequestError("Filtro deve possuir exatamente um de and, or, not ou field"),
+		},
+		{
+			name:    "empty filter",
+			filter:  `{}`,
+			wantErr: errs.NewBadRequestError("Filtro deve possuir exatamente um de and, or, not ou field"),
+		},
+		{
+			name:    "invalid nested filter",
+			filter:  `{"or": [{"field": "year", "op": "eq", "value": 2021}, {"not": {}}]}`,
+			wantErr: errs.NewBadRequestError("Filtro deve possuir exatamente um de and, or, not ou field"),
+		},
+		{
+			name:    "missing value",
+			filter:  `{"field": "year", "op": "eq"}`,
+			wantErr: errs.NewBadRequestError("Filtro year: value deve ser um texto, um numero ou uma lista deles"),
+		},
+		{
+			name:    "boolean value",
+			filter:  `{"field": "year", "op": "in", "value": [2021, true]}`,
+			wantErr: errs.NewBadRequestError("Filtro year: value deve ser um texto, um numero ou uma lista deles"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filter SearchFilter
+			assert.Nil(t, json.Unmarshal([]byte(tt.filter), &filter))
+
+			got, err := filter.ToDomain()
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
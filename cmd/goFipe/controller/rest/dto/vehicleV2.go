@@ -0,0 +1,75 @@
+package dto
+
+import "github.com/raffops/gofipe/cmd/goFipe/domain"
+
+// VehicleV2Response is a vehicle in API v2: English field names, with the reference month, the model and
+// the price nested. Fields not selected by the query are omitted, as are the nested objects without any
+// selected field.
+type VehicleV2Response struct {
+	FipeCode       *string                   `json:"fipe_code,omitempty"`
+	ReferenceMonth *ReferenceMonthV2Response `json:"reference_month,omitempty"`
+	Model          *ModelV2Response          `json:"model,omitempty"`
+	Price          *PriceV2Response          `json:"price,omitempty"`
+}
+
+type ReferenceMonthV2Response struct {
+	Year  *int `json:"year,omitempty"`
+	Month *int `json:"month,omitempty"`
+}
+
+type ModelV2Response struct {
+	Brand     *string `json:"brand,omitempty"`
+	Name      *string `json:"name,omitempty"`
+	YearModel *string `json:"year_model,omitempty"`
+}
+
+type PriceV2Response struct {
+	MeanValue      *float32 `json:"mean_value,omitempty"`
+	Authentication *string  `json:"authentication,omitempty"`
+}
+
+func VehicleV2ResponseFromDomain(vehicle domain.Vehicle, fields []domain.VehicleField) VehicleV2Response {
+	var response VehicleV2Response
+	for _, field := range fields {
+		switch field {
+		case domain.VehicleFieldYear:
+			response.referenceMonth().Year = &vehicle.Year
+		case domain.VehicleFieldMonth:
+			response.referenceMonth().Month = &vehicle.Month
+		case domain.VehicleFieldFipeCode:
+			response.FipeCode = &vehicle.FipeCode
+		case domain.VehicleFieldBrand:
+			response.model().Brand = &vehicle.Brand
+		case domain.VehicleFieldModel:
+			response.model().Name = &vehicle.Model
+		case domain.VehicleFieldYearModel:
+			response.model().YearModel = &vehicle.YearModel
+		case domain.VehicleFieldAuthentication:
+			response.price().Authentication = &vehicle.Authentication
+		case domain.VehicleFieldMeanValue:
+			response.price().MeanValue = &vehicle.MeanValue
+		}
+	}
+	return response
+}
+
+func (v *VehicleV2Response) referenceMonth() *ReferenceMonthV2Response {
+	if v.ReferenceMonth == nil {
+		v.ReferenceMonth = &ReferenceMonthV2Response{}
+	}
+	return v.ReferenceMonth
+}
+
+func (v *VehicleV2Response) model() *ModelV2Response {
+	if v.Model == nil {
+		v.Model = &ModelV2Response{}
+	}
+	return v.Model
+}
+
+func (v *VehicleV2Response) price() *PriceV2Response {
+	if v.Price == nil {
+		v.Price = &PriceV2Response{}
+	}
+	return v.Price
+}
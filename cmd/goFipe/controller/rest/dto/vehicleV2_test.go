@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleV2ResponseFromDomain(t *testing.T) {
+	vehicle := domain.GetDomainVehiclesExamples()[1]
+	tests := []struct {
+		name   string
+		fields []domain.VehicleField
+		want   string
+	}{
+		{
+			name:   "every field",
+			fields: domain.VehicleFields,
+			want: `{"fipe_code":"222222-2","reference_month":{"year":2021,"month":6},` +
+				`"model":{"brand":"Fiat","name":"147 C/ CL","year_model":"1991 Gasolina"},` +
+				`"price":{"mean_value":800,"authentication":"2"}}`,
+		},
+		{
+			name:   "nested objects without selected fields are omitted",
+			fields: []domain.VehicleField{domain.VehicleFieldMeanValue, domain.VehicleFieldMonth},
+			want:   `{"reference_month":{"month":6},"price":{"mean_value":800}}`,
+		},
+		{
+			name:   "fipe code only",
+			fields: []domain.VehicleField{domain.VehicleFieldFipeCode},
+			want:   `{"fipe_code":"222222-2"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(VehicleV2ResponseFromDomain(vehicle, tt.fields))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
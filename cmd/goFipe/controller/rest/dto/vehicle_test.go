@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	"reflect"
 	"testing"
@@ -47,3 +48,50 @@ func Test_vehicleResponseFromDomain(t *testing.T) {
 		})
 	}
 }
+
+func TestGetVehicleResponse_ToDomain(t *testing.T) {
+	for _, vehicle := range domain.GetDomainVehiclesExamples() {
+		if got := VehicleResponseFromDomain(vehicle).ToDomain(); !reflect.DeepEqual(got, vehicle) {
+			t.Errorf("ToDomain() = %v, want %v", got, vehicle)
+		}
+	}
+}
+
+func TestSparseVehicleResponseFromDomain(t *testing.T) {
+	vehicle := domain.GetDomainVehiclesExamples()[0]
+	vehicle.MeanValue = 0
+	tests := []struct {
+		name   string
+		fields []domain.VehicleField
+		want   string
+	}{
+		{
+			name:   "Every field is GetVehicleResponse",
+			fields: domain.VehicleFields,
+			want: `{"ano":2021,"mes":7,"fipe_code":"111111-1","marca":"Acura","modelo":"Integra GS 1.8",` +
+				`"ano_modelo":"1992 Gasolina","autenticacao":"1","valor_medio":0}`,
+		},
+		{
+			name:   "Selected fields, zero values included",
+			fields: []domain.VehicleField{domain.VehicleFieldMeanValue, domain.VehicleFieldFipeCode},
+			want:   `{"fipe_code":"111111-1","valor_medio":0}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(SparseVehicleResponseFromDomain(vehicle, tt.fields))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("SparseVehicleResponseFromDomain() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	full, _ := json.Marshal(VehicleResponseFromDomain(vehicle))
+	sparse, _ := json.Marshal(SparseVehicleResponseFromDomain(vehicle, domain.VehicleFields))
+	if string(full) != string(sparse) {
+		t.Errorf("SparseVehicleResponseFromDomain() = %s, want %s", sparse, full)
+	}
+}
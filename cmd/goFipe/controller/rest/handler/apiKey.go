@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+type ApiKeyHandler struct {
+	apiKeyService ports.ApiKeyService
+	log           logger.Logger
+}
+
+func NewApiKeyHandler(apiKeyService ports.ApiKeyService, log logger.Logger) ApiKeyHandler {
+	return ApiKeyHandler{apiKeyService: apiKeyService, log: log}
+}
+
+func (h ApiKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request dto.CreateApiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error(w, r, "Corpo da requisicao invalido", http.StatusBadRequest)
+		return
+	}
+
+	apiKey, rawKey, errCreate := h.apiKeyService.Create(r.Context(), request.Name, request.MonthlyQuota)
+	if errCreate != nil {
+		response.AppError(w, r, errCreate)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	err := json.NewEncoder(w).Encode(dto.CreateApiKeyResponse{
+		ApiKeyResponse: dto.ApiKeyResponseFromDomain(apiKey),
+		Key:            rawKey,
+	})
+	if err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+func (h ApiKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	apiKeys, errList := h.apiKeyService.List(r.Context())
+	if errList != nil {
+		response.AppError(w, r, errList)
+		return
+	}
+
+	responseApiKeys := make([]dto.ApiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		responseApiKeys = append(responseApiKeys, dto.ApiKeyResponseFromDomain(apiKey))
+	}
+	if err := json.NewEncoder(w).Encode(responseApiKeys); err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+func (h ApiKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, errId := handleIdParameter(mux.Vars(r)["id"])
+	if errId != nil {
+		response.AppError(w, r, errId)
+		return
+	}
+
+	if errRevoke := h.apiKeyService.Revoke(r.Context(), id); errRevoke != nil {
+		response.AppError(w, r, errRevoke)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h ApiKeyHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, errId := handleIdParameter(mux.Vars(r)["id"])
+	if errId != nil {
+		response.AppError(w, r, errId)
+		return
+	}
+
+	currentYear, currentMonth, _ := time.Now().UTC().Date()
+	year, errYear := handleOptionalIntParameter(r.URL.Query().Get("year"), currentYear, "Ano deve ser um numero inteiro")
+	if errYear != nil {
+		response.AppError(w, r, errYear)
+		return
+	}
+	month, errMonth := handleOptionalIntParameter(r.URL.Query().Get("month"), int(currentMonth), "Mes deve ser um numero inteiro")
+	if errMonth != nil {
+		response.AppError(w, r, errMonth)
+		return
+	}
+
+	usage, errUsage := h.apiKeyService.GetUsage(r.Context(), id, year, month)
+	if errUsage != nil {
+		response.AppError(w, r, errUsage)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(dto.ApiKeyUsageResponseFromDomain(usage)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+func handleIdParameter(idString string) (uint, *errs.AppError) {
+	id, err := strconv.ParseUint(idString, 10, 64)
+	if err != nil || id == 0 {
+		return 0, errs.NewBadRequestError("Id deve ser um numero inteiro positivo")
+	}
+	return uint(id), nil
+}
+
+func handleOptionalIntParameter(value string, defaultValue int, message string) (int, *errs.AppError) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errs.NewBadRequestError(message)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMockApiKeyService(t *testing.T) (*mockPort.MockApiKeyService, *gomock.Controller) {
+	ctrl := gomock.NewController(t)
+	mockApiKeyService := mockPort.NewMockApiKeyService(ctrl)
+	return mockApiKeyService, ctrl
+}
+
+func newApiKeyRouter(apiKeyHandler ApiKeyHandler) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/api-keys", apiKeyHandler.Create).Methods("POST")
+	router.HandleFunc("/admin/api-keys", apiKeyHandler.List).Methods("GET")
+	router.HandleFunc("/admin/api-keys/{id}", apiKeyHandler.Revoke).Methods("DELETE")
+	router.HandleFunc("/admin/api-keys/{id}/usage", apiKeyHandler.GetUsage).Methods("GET")
+	return router
+}
+
+func TestApiKeyHandler(t *testing.T) {
+	createdAt := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		apiKeyService  func(service *mockPort.MockApiKeyService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:   "Create api key",
+			method: "POST",
+			path:   "/admin/api-keys",
+			body:   `{"name":"Dealer","monthly_quota":1000}`,
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Create(gomock.Any(), "Dealer", 1000).Return(
+					domain.ApiKey{ID: 1, Name: "Dealer", Prefix: "gf_01234567", MonthlyQuota: 1000, CreatedAt: createdAt},
+					"gf_0123456789",
+					nil,
+				)
+			},
+			wantBody:       "{\"id\":1,\"name\":\"Dealer\",\"prefix\":\"gf_01234567\",\"monthly_quota\":1000,\"created_at\":\"2021-07-01T00:00:00Z\",\"key\":\"gf_0123456789\"}\n",
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "Create api key with invalid body",
+			method:         "POST",
+			path:           "/admin/api-keys",
+			body:           `{"name":`,
+			apiKeyService:  func(service *mockPort.MockApiKeyService) {},
+			wantBody:       `{"message":"Corpo da requisicao invalido"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Create api key with validation error",
+			method: "POST",
+			path:   "/admin/api-keys",
+			body:   `{"name":""}`,
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Create(gomock.Any(), "", 0).Return(domain.ApiKey{}, "", errs.NewValidationError("Name is required"))
+			},
+			wantBody:       `{"message":"Name is required"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List api keys",
+			method: "GET",
+			path:   "/admin/api-keys",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().List(gomock.Any()).Return(
+					[]domain.ApiKey{{ID: 1, Name: "Dealer", Prefix: "gf_01234567", CreatedAt: createdAt}},
+					nil,
+				)
+			},
+			wantBody:       "[{\"id\":1,\"name\":\"Dealer\",\"prefix\":\"gf_01234567\",\"monthly_quota\":0,\"created_at\":\"2021-07-01T00:00:00Z\"}]\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "Revoke api key",
+			method: "DELETE",
+			path:   "/admin/api-keys/1",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Revoke(gomock.Any(), uint(1)).Return(nil)
+			},
+			wantBody:       "",
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Revoke api key with invalid id",
+			method:         "DELETE",
+			path:           "/admin/api-keys/abc",
+			apiKeyService:  func(service *mockPort.MockApiKeyService) {},
+			wantBody:       `{"message":"Id deve ser um numero inteiro positivo"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Api key usage",
+			method: "GET",
+			path:   "/admin/api-keys/1/usage?year=2021&month=7",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().GetUsage(gomock.Any(), uint(1), 2021, 7).
+					Return(domain.ApiKeyUsage{ApiKeyID: 1, Year: 2021, Month: 7, Requests: 42}, nil)
+			},
+			wantBody:       "{\"api_key_id\":1,\"ano\":2021,\"mes\":7,\"requests\":42}\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Api key usage with invalid month",
+			method:         "GET",
+			path:           "/admin/api-keys/1/usage?year=2021&month=jul",
+			apiKeyService:  func(service *mockPort.MockApiKeyService) {},
+			wantBody:       `{"message":"Mes deve ser um numero inteiro"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyService, ctrl := getMockApiKeyService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.apiKeyService(mockApiKeyService)
+
+			req, err := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rr := httptest.NewRecorder()
+			newApiKeyRouter(NewApiKeyHandler(mockApiKeyService, logger.NewNop())).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
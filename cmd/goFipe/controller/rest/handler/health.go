@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+type HealthHandler struct {
+	healthService ports.HealthService
+	log           logger.Logger
+}
+
+func NewHealthHandler(healthService ports.HealthService, log logger.Logger) HealthHandler {
+	return HealthHandler{healthService: healthService, log: log}
+}
+
+// Livez reports that the process is able to serve requests. It does not check any dependency, so a
+// database outage does not make the orchestrator restart every instance.
+func (h HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.writeHealth(w, r, dto.HealthResponse{Status: string(domain.HealthStatusUp)}, http.StatusOK)
+}
+
+// Readyz reports whether the instance can serve traffic, with the status of each dependency.
+// It answers 503 when any dependency is down.
+func (h HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	health := h.healthService.Readiness(r.Context())
+
+	statusCode := http.StatusOK
+	if health.Status() != domain.HealthStatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+	h.writeHealth(w, r, dto.HealthResponseFromDomain(health), statusCode)
+}
+
+func (h HealthHandler) writeHealth(w http.ResponseWriter, r *http.Request, response dto.HealthResponse, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(response); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body.Bytes())
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		healthService  func(service *mockPort.MockHealthService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:           "Live",
+			path:           "/livez",
+			healthService:  func(service *mockPort.MockHealthService) {},
+			wantBody:       `{"status":"up"}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Ready",
+			path: "/readyz",
+			healthService: func(service *mockPort.MockHealthService) {
+				service.EXPECT().Readiness(gomock.Any()).Return(domain.Health{
+					Dependencies: []domain.DependencyHealth{
+						{Name: "postgres", Status: domain.HealthStatusUp},
+						{Name: "schema", Status: domain.HealthStatusUp, Message: "Schema version 1"},
+					},
+				})
+			},
+			wantBody: `{"status":"up","checks":{"postgres":{"status":"up"},` +
+				`"schema":{"status":"up","message":"Schema version 1"}}}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Degraded",
+			path: "/readyz",
+			healthService: func(service *mockPort.MockHealthService) {
+				service.EXPECT().Readiness(gomock.Any()).Return(domain.Health{
+					Dependencies: []domain.DependencyHealth{
+						{Name: "postgres", Status: domain.HealthStatusUp},
+						{Name: "reference_data", Status: domain.HealthStatusDown, Message: "No reference month loaded"},
+					},
+				})
+			},
+			wantBody: `{"status":"down","checks":{"postgres":{"status":"up"},` +
+				`"reference_data":{"status":"down","message":"No reference month loaded"}}}` + "\n",
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			mockHealthService := mockPort.NewMockHealthService(ctrl)
+			tt.healthService(mockHealthService)
+
+			healthHandler := NewHealthHandler(mockHealthService, logger.NewNop())
+			router := mux.NewRouter()
+			router.HandleFunc("/livez", healthHandler.Livez).Methods("GET")
+			router.HandleFunc("/readyz", healthHandler.Readyz).Methods("GET")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		})
+	}
+}
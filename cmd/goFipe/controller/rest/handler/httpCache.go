@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+)
+
+// vehicleCacheMaxAge is how long clients may reuse a vehicle response without revalidating. Responses
+// are private, since they answer an authenticated API key and shared caches do not key on it.
+// FIPE references are immutable once published, so revalidation is cheap and usually answered with 304.
+const vehicleCacheMaxAge = time.Hour
+
+// writeCacheable writes body as a cacheable response. It sets the ETag, Last-Modified and Cache-Control
+// headers and answers conditional requests (If-None-Match, If-Modified-Since) with 304 Not Modified.
+// A zero lastModified omits the Last-Modified header.
+func writeCacheable(w http.ResponseWriter, r *http.Request, body []byte, lastModified time.Time) {
+	etag := computeETag(body)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(vehicleCacheMaxAge.Seconds())))
+	w.Header().Add("Vary", "X-API-Key")
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(r, etag, lastModified) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// computeETag returns a strong ETag for the response body.
+func computeETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return fmt.Sprintf("%q", hex.EncodeToString(hash[:16]))
+}
+
+// isNotModified evaluates the conditional headers of the request following RFC 9110:
+// If-None-Match takes precedence and If-Modified-Since is only evaluated when it is absent.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// latestReferenceMonth returns the first instant of the most recent reference month among the vehicles.
+func latestReferenceMonth(vehicles []domain.Vehicle) time.Time {
+	var latest time.Time
+	for _, vehicle := range vehicles {
+		referenceMonth := time.Date(vehicle.Year, time.Month(vehicle.Month), 1, 0, 0, 0, 0, time.UTC)
+		if referenceMonth.After(latest) {
+			latest = referenceMonth
+		}
+	}
+	return latest
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleHandler_GetConditional(t *testing.T) {
+	vehiclesExamples := domain.GetDomainVehiclesExamples()
+	body := "[{\"ano\":2021,\"mes\":7,\"fipe_code\":\"111111-1\",\"marca\":\"Acura\",\"modelo\":\"Integra GS 1.8\",\"ano_modelo\":\"1992 Gasolina\",\"autenticacao\":\"1\",\"valor_medio\":700}]\n"
+	etag := computeETag([]byte(body))
+	lastModified := "Thu, 01 Jul 2021 00:00:00 GMT"
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			name:           "Unconditional request",
+			headers:        map[string]string{},
+			wantStatusCode: http.StatusOK,
+			wantBody:       body,
+		},
+		{
+			name:           "If-None-Match matches",
+			headers:        map[string]string{"If-None-Match": `"other", ` + etag},
+			wantStatusCode: http.StatusNotModified,
+			wantBody:       "",
+		},
+		{
+			name:           "If-None-Match weak match",
+			headers:        map[string]string{"If-None-Match": "W/" + etag},
+			wantStatusCode: http.StatusNotModified,
+			wantBody:       "",
+		},
+		{
+			name:           "If-None-Match does not match, If-Modified-Since ignored",
+			headers:        map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": lastModified},
+			wantStatusCode: http.StatusOK,
+			wantBody:       body,
+		},
+		{
+			name:           "If-Modified-Since after last modified",
+			headers:        map[string]string{"If-Modified-Since": "Fri, 02 Jul 2021 00:00:00 GMT"},
+			wantStatusCode: http.StatusNotModified,
+			wantBody:       "",
+		},
+		{
+			name:           "If-Modified-Since before last modified",
+			headers:        map[string]string{"If-Modified-Since": "Wed, 30 Jun 2021 00:00:00 GMT"},
+			wantStatusCode: http.StatusOK,
+			wantBody:       body,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			mockVehicleService.EXPECT().
+				GetVehicle(gomock.Any(), gomock.Any()).
+				Return([]domain.Vehicle{vehiclesExamples[0]}, nil)
+
+			req := httptest.NewRequest("GET", "/vehicles?where=fipe_code:111111-1&order=year:asc&offset=0&limit=1", nil)
+			for header, value := range tt.headers {
+				req.Header.Set(header, value)
+			}
+			rr := httptest.NewRecorder()
+			NewVehicleHandler(mockVehicleService, logger.NewNop()).Get(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			assert.Equal(t, etag, rr.Header().Get("ETag"))
+			assert.Equal(t, lastModified, rr.Header().Get("Last-Modified"))
+			assert.Equal(t, "private, max-age=3600", rr.Header().Get("Cache-Control"))
+		})
+	}
+}
+
+func Test_latestReferenceMonth(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+
+	assert.Equal(t, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), latestReferenceMonth(vehicles))
+	assert.True(t, latestReferenceMonth(nil).IsZero())
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+// LoggerSettings changes the level and the format of the application logger at runtime.
+// It is implemented by *logger.Controller.
+type LoggerSettings interface {
+	Level() string
+	SetLevel(level string) error
+	Format() string
+	SetFormat(format string) error
+}
+
+type LoggerHandler struct {
+	settings LoggerSettings
+	log      logger.Logger
+}
+
+func NewLoggerHandler(settings LoggerSettings, log logger.Logger) LoggerHandler {
+	return LoggerHandler{settings: settings, log: log}
+}
+
+func (h LoggerHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.writeSettings(w, r)
+}
+
+// Update changes the level and/or the format. The change is not persisted: a restart applies the
+// configured values again.
+func (h LoggerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var request dto.LoggerSettings
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error(w, r, "Corpo da requisicao invalido", http.StatusBadRequest)
+		return
+	}
+	if request.Level == "" && request.Format == "" {
+		response.Error(w, r, "Informe level e/ou format", http.StatusBadRequest)
+		return
+	}
+
+	// Both values are validated before any is applied, so a rejected request changes nothing.
+	if request.Level != "" && !slices.Contains(logger.Levels, request.Level) {
+		response.Error(w, r, "Level deve ser debug, info ou error", http.StatusBadRequest)
+		return
+	}
+	if request.Format != "" && !slices.Contains(logger.Formats, request.Format) {
+		response.Error(w, r, "Format deve ser json ou console", http.StatusBadRequest)
+		return
+	}
+
+	if request.Level != "" {
+		if err := h.settings.SetLevel(request.Level); err != nil {
+			response.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Format != "" {
+		if err := h.settings.SetFormat(request.Format); err != nil {
+			response.Error(w, r, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	logger.FromContext(r.Context(), h.log).Info("Logger settings changed",
+		logger.String("level", h.settings.Level()),
+		logger.String("format", h.settings.Format()),
+	)
+	h.writeSettings(w, r)
+}
+
+func (h LoggerHandler) writeSettings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	err := json.NewEncoder(w).Encode(dto.LoggerSettings{
+		Level:  h.settings.Level(),
+		Format: h.settings.Format(),
+	})
+	if err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+// writeEncodeError logs a response that could not be encoded and answers 500.
+func writeEncodeError(w http.ResponseWriter, r *http.Request, log logger.Logger, err error) {
+	logger.FromContext(r.Context(), log).Error("Unable to encode response", logger.Err(err))
+	response.Error(w, r, "Erro ao serializar resposta", http.StatusInternalServerError)
+}
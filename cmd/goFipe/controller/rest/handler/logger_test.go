@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:           "Get settings",
+			method:         "GET",
+			wantBody:       `{"level":"info","format":"json"}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Change level",
+			method:         "PUT",
+			body:           `{"level":"debug"}`,
+			wantBody:       `{"level":"debug","format":"json"}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Change level and format",
+			method:         "PUT",
+			body:           `{"level":"error","format":"console"}`,
+			wantBody:       `{"level":"error","format":"console"}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid format keeps the valid level unchanged",
+			method:         "PUT",
+			body:           `{"level":"debug","format":"text"}`,
+			wantBody:       `{"message":"Format deve ser json ou console"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid level",
+			method:         "PUT",
+			body:           `{"level":"warn"}`,
+			wantBody:       `{"message":"Level deve ser debug, info ou error"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Empty settings",
+			method:         "PUT",
+			body:           `{}`,
+			wantBody:       `{"message":"Informe level e/ou format"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid body",
+			method:         "PUT",
+			body:           `level=debug`,
+			wantBody:       `{"message":"Corpo da requisicao invalido"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, controller, err := logger.New(io.Discard, logger.LevelInfo, logger.FormatJSON)
+			assert.Nil(t, err)
+			loggerHandler := NewLoggerHandler(controller, log)
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/logger", loggerHandler.Get).Methods("GET")
+			router.HandleFunc("/admin/logger", loggerHandler.Update).Methods("PUT")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, "/admin/logger", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			if tt.wantStatusCode != http.StatusOK {
+				assert.Equal(t, logger.LevelInfo, controller.Level())
+				assert.Equal(t, logger.FormatJSON, controller.Format())
+			}
+		})
+	}
+}
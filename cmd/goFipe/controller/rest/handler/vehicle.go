@@ -1,147 +1,520 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/params"
 	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
 	"net/http"
 	"strconv"
-	"strings"
+	"time"
+)
 
-	"github.com/raffops/gofipe/cmd/goFipe/errs"
+const (
+	// maxSearchBodySize bounds the body of POST /vehicles/search.
+	maxSearchBodySize = 64 << 10
+	// maxBatchBodySize bounds the body of POST /vehicles/batch.
+	maxBatchBodySize = 256 << 10
+	// maxListingBatchBodySize bounds the body of POST /v2/vehicles/match/batch.
+	maxListingBatchBodySize = 64 << 10
 )
 
 type VehicleHandler struct {
 	vehicleService ports.VehicleService
+	log            logger.Logger
+}
+
+func NewVehicleHandler(vehicleService ports.VehicleService, log logger.Logger) VehicleHandler {
+	return VehicleHandler{vehicleService: vehicleService, log: log}
+}
+
+// vehiclesBody converts the vehicles of a query to the response body of an API version, restricted to
+// the fields of the query.
+type vehiclesBody func(vehicles []domain.Vehicle, fields []domain.VehicleField) any
+
+func vehiclesV1Body(vehicles []domain.Vehicle, fields []domain.VehicleField) any {
+	responseVehicles := make([]dto.SparseVehicleResponse, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		responseVehicles = append(responseVehicles, dto.SparseVehicleResponseFromDomain(vehicle, fields))
+	}
+	return responseVehicles
+}
+
+func vehiclesV2Body(vehicles []domain.Vehicle, fields []domain.VehicleField) any {
+	responseVehicles := make([]dto.VehicleV2Response, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		responseVehicles = append(responseVehicles, dto.VehicleV2ResponseFromDomain(vehicle, fields))
+	}
+	return responseVehicles
+}
+
+// matchesBody converts the matches of a text search to the response body of an API version.
+type matchesBody func(matches []domain.VehicleMatch) any
+
+func matchesV1Body(matches []domain.VehicleMatch) any {
+	responseMatches := make([]dto.VehicleMatchResponse, 0, len(matches))
+	for _, match := range matches {
+		responseMatches = append(responseMatches, dto.VehicleMatchResponseFromDomain(match))
+	}
+	return responseMatches
 }
 
-func NewVehicleHandler(vehicleService ports.VehicleService) VehicleHandler {
-	return VehicleHandler{vehicleService: vehicleService}
+func matchesV2Body(matches []domain.VehicleMatch) any {
+	responseMatches := make([]dto.VehicleMatchV2Response, 0, len(matches))
+	for _, match := range matches {
+		responseMatches = append(responseMatches, dto.VehicleMatchV2ResponseFromDomain(match))
+	}
+	return responseMatches
 }
 
+// Get answers GET /vehicles, the API v1 representation of the vehicles.
 func (h VehicleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	h.get(w, r, vehiclesV1Body)
+}
+
+// GetV2 answers GET /v2/vehicles, the API v2 representation of the vehicles.
+func (h VehicleHandler) GetV2(w http.ResponseWriter, r *http.Request) {
+	h.get(w, r, vehiclesV2Body)
+}
+
+func (h VehicleHandler) get(w http.ResponseWriter, r *http.Request, toBody vehiclesBody) {
 	r.Header.Set("Content-Type", "application/json")
 	w.Header().Set("Content-Type", "application/json")
 
-	where, errWhere := handleWhereParameter(r.URL.Query().Get("where"))
-	if errWhere != nil {
-		http.Error(w, errWhere.Message, errWhere.Code)
+	builder := domain.NewVehicleQueryBuilder()
+	if errWhere := params.ParseWhere(builder, r.URL.Query().Get("where")); errWhere != nil {
+		response.AppError(w, r, errWhere)
 		return
 	}
-
-	orderBy, errOrderBy := handleOrderByParameter(r.URL.Query().Get("order"))
-	if errOrderBy != nil {
-		http.Error(w, errOrderBy.Message, errOrderBy.Code)
+	if errOrderBy := params.ParseOrderBy(builder, r.URL.Query().Get("order")); errOrderBy != nil {
+		response.AppError(w, r, errOrderBy)
+		return
+	}
+	if errFields := params.ParseFields(builder, r.URL.Query().Get("fields")); errFields != nil {
+		response.AppError(w, r, errFields)
 		return
 	}
 
 	offsetString := r.URL.Query().Get("offset")
 	offset, err := strconv.Atoi(offsetString)
 	if err != nil {
-		http.Error(w, "Offset deve ser um numero inteiro", http.StatusBadRequest)
+		response.Error(w, r, "Offset deve ser um numero inteiro", http.StatusBadRequest)
 		return
 	}
 	limitString := r.URL.Query().Get("limit")
 	limit, err := strconv.Atoi(limitString)
 	if err != nil {
-		http.Error(w, "Limit deve ser um numero inteiro", http.StatusBadRequest)
+		response.Error(w, r, "Limit deve ser um numero inteiro", http.StatusBadRequest)
 		return
 	}
 
-	vehicles, errGet := h.vehicleService.GetVehicle(where, orderBy, offset, limit)
-	if errGet != nil {
-		http.Error(w, errGet.Message, errGet.Code)
+	query, errQuery := params.Build(builder.
+		Period(r.URL.Query().Get("from"), r.URL.Query().Get("to")).
+		Page(offset, limit))
+	if errQuery != nil {
+		response.AppError(w, r, errQuery)
 		return
 	}
 
-	var responseVehicles []dto.GetVehicleResponse
-	for _, vehicle := range vehicles {
-		responseVehicles = append(responseVehicles, dto.VehicleResponseFromDomain(vehicle))
+	vehicles, errGet := h.vehicleService.GetVehicle(r.Context(), query)
+	if errGet != nil {
+		response.AppError(w, r, errGet)
+		return
 	}
-	err = json.NewEncoder(w).Encode(responseVehicles)
+
+	var body bytes.Buffer
+	err = json.NewEncoder(&body).Encode(toBody(vehicles, query.Fields()))
 	if err != nil {
-		http.Error(w, "Erro ao serializar resposta", http.StatusInternalServerError)
+		writeEncodeError(w, r, h.log, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	writeCacheable(w, r, body.Bytes(), latestReferenceMonth(vehicles))
 }
 
-func handleWhereParameter(whereString string) (map[string]string, *errs.AppError) {
-	if len(strings.TrimSpace(whereString)) == 0 {
-		return nil, errs.NewBadRequestError("Campo where deve possuir no minimo 1 clausula")
-	}
+// historyBody converts the price history of a fipe code to the response body of an API version.
+type historyBody func(history domain.VehicleHistory) any
 
-	where := map[string]string{}
-	for index, split := range strings.Split(whereString, ",") {
-		split = strings.TrimSpace(split)
-		if len(split) == 0 {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula where %d deve ser no formato 'key:value'", index),
-			)
-		}
+func historyV1Body(history domain.VehicleHistory) any {
+	return dto.VehicleHistoryResponseFromDomain(history)
+}
 
-		keyValue := strings.Split(split, ":")
-		if len(keyValue) != 2 {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula where %d deve ser no formato 'key:value'", index),
-			)
-		}
+func historyV2Body(history domain.VehicleHistory) any {
+	return dto.VehicleHistoryV2ResponseFromDomain(history)
+}
 
-		if keyValue[0] == "" || keyValue[1] == "" {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula where %d deve ser no formato 'key:value'", index),
-			)
-		}
+// GetHistory answers GET /vehicles/{fipe_code}/history, the API v1 representation of the price history.
+func (h VehicleHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	h.getHistory(w, r, historyV1Body)
+}
 
-		key := strings.TrimSpace(keyValue[0])
-		value := strings.TrimSpace(keyValue[1])
-		where[key] = value
+// GetHistoryV2 answers GET /v2/vehicles/{fipe_code}/history, the API v2 representation of the price
+// history.
+func (h VehicleHandler) GetHistoryV2(w http.ResponseWriter, r *http.Request) {
+	h.getHistory(w, r, historyV2Body)
+}
+
+// getHistory answers the prices of the fipe code from the from to the to reference month, both formatted
+// as YYYY-MM and optional, see VehicleService.GetHistory.
+func (h VehicleHandler) getHistory(w http.ResponseWriter, r *http.Request, toBody historyBody) {
+	w.Header().Set("Content-Type", "application/json")
+
+	period, errPeriod := params.Period(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if errPeriod != nil {
+		response.AppError(w, r, errPeriod)
+		return
+	}
+
+	history, errHistory := h.vehicleService.GetHistory(r.Context(), mux.Vars(r)["fipe_code"], period)
+	if errHistory != nil {
+		response.AppError(w, r, errHistory)
+		return
 	}
 
-	return where, nil
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(toBody(history)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	var lastModified time.Time
+	if len(history.Prices) > 0 {
+		lastModified = history.Prices[len(history.Prices)-1].ReferenceMonth.Time()
+	}
+	writeCacheable(w, r, body.Bytes(), lastModified)
+}
+
+// priceAsOfBody converts the price of a vehicle on a date to the response body of an API version.
+type priceAsOfBody func(price domain.VehiclePriceAsOf) any
+
+func priceAsOfV1Body(price domain.VehiclePriceAsOf) any {
+	return dto.VehiclePriceAsOfResponseFromDomain(price)
+}
+
+func priceAsOfV2Body(price domain.VehiclePriceAsOf) any {
+	return dto.VehiclePriceAsOfV2ResponseFromDomain(price)
+}
+
+// GetPriceAsOf answers GET /vehicles/{fipe_code}/price, the API v1 representation of the price on a date.
+func (h VehicleHandler) GetPriceAsOf(w http.ResponseWriter, r *http.Request) {
+	h.getPriceAsOf(w, r, priceAsOfV1Body)
+}
+
+// GetPriceAsOfV2 answers GET /v2/vehicles/{fipe_code}/price, the API v2 representation of the price on a
+// date.
+func (h VehicleHandler) GetPriceAsOfV2(w http.ResponseWriter, r *http.Request) {
+	h.getPriceAsOf(w, r, priceAsOfV2Body)
 }
 
-func handleOrderByParameter(orderByString string) (map[string]bool, *errs.AppError) {
-	if len(strings.TrimSpace(orderByString)) == 0 {
-		return nil, errs.NewBadRequestError("Campo order deve possuir no minimo 1 clausula")
+// getPriceAsOf answers the price of the year_model parameter on the as_of date, formatted as YYYY-MM-DD,
+// see VehicleService.GetPriceAsOf.
+func (h VehicleHandler) getPriceAsOf(w http.ResponseWriter, r *http.Request, toBody priceAsOfBody) {
+	w.Header().Set("Content-Type", "application/json")
+
+	asOf, err := time.Parse(domain.AsOfLayout, r.URL.Query().Get("as_of"))
+	if err != nil {
+		response.Error(w, r, "As_of deve ser uma data no formato YYYY-MM-DD", http.StatusBadRequest)
+		return
 	}
 
-	orderBy := map[string]bool{}
-	for index, split := range strings.Split(orderByString, ",") {
-		split = strings.TrimSpace(split)
-		if len(split) == 0 {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula order %d deve ser no formato 'key:value'", index))
-		}
+	price, errPrice := h.vehicleService.GetPriceAsOf(
+		r.Context(), mux.Vars(r)["fipe_code"], r.URL.Query().Get("year_model"), asOf,
+	)
+	if errPrice != nil {
+		response.AppError(w, r, errPrice)
+		return
+	}
 
-		keyValue := strings.Split(split, ":")
-		if len(keyValue) != 2 {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula order %d deve ser no formato 'key:value'", index),
-			)
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(toBody(price)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	writeCacheable(w, r, body.Bytes(), price.Price.ReferenceMonth.Time())
+}
+
+// SearchText answers GET /vehicles/search, the API v1 representation of the matches of the q parameter.
+func (h VehicleHandler) SearchText(w http.ResponseWriter, r *http.Request) {
+	h.searchText(w, r, matchesV1Body)
+}
+
+// SearchTextV2 answers GET /v2/vehicles/search, the API v2 representation of the matches of the q
+// parameter.
+func (h VehicleHandler) SearchTextV2(w http.ResponseWriter, r *http.Request) {
+	h.searchText(w, r, matchesV2Body)
+}
+
+// searchText answers a free text search, e.g. q=gol 1.6 2015, see domain.TextSearch. Unlike get, offset
+// and limit are optional.
+func (h VehicleHandler) searchText(w http.ResponseWriter, r *http.Request, toBody matchesBody) {
+	w.Header().Set("Content-Type", "application/json")
+
+	offset, limit := 0, domain.DefaultLimit
+	if offsetString := r.URL.Query().Get("offset"); offsetString != "" {
+		var err error
+		if offset, err = strconv.Atoi(offsetString); err != nil {
+			response.Error(w, r, "Offset deve ser um numero inteiro", http.StatusBadRequest)
+			return
+		}
+	}
+	if limitString := r.URL.Query().Get("limit"); limitString != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitString); err != nil {
+			response.Error(w, r, "Limit deve ser um numero inteiro", http.StatusBadRequest)
+			return
 		}
+	}
+
+	search, errSearch := params.TextSearch(r.URL.Query().Get("q"), offset, limit)
+	if errSearch != nil {
+		response.AppError(w, r, errSearch)
+		return
+	}
+
+	matches, errMatches := h.vehicleService.SearchVehicles(r.Context(), search)
+	if errMatches != nil {
+		response.AppError(w, r, errMatches)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(toBody(matches)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	vehicles := make([]domain.Vehicle, 0, len(matches))
+	for _, match := range matches {
+		vehicles = append(vehicles, match.Vehicle)
+	}
+	writeCacheable(w, r, body.Bytes(), latestReferenceMonth(vehicles))
+}
 
-		if keyValue[0] == "" || keyValue[1] == "" {
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula order %d deve ser no formato 'key:value'", index),
-			)
+// Autocomplete answers GET /v2/autocomplete and the deprecated GET /autocomplete with the same body: the
+// values of the field parameter starting with the prefix parameter, narrowed by the brand and model already
+// picked in the form. The limit is optional.
+func (h VehicleHandler) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	limit := domain.DefaultLimit
+	if limitString := query.Get("limit"); limitString != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitString); err != nil {
+			response.Error(w, r, "Limit deve ser um numero inteiro", http.StatusBadRequest)
+			return
 		}
+	}
+
+	autocomplete, errAutocomplete := params.Autocomplete(
+		query.Get("field"), query.Get("prefix"), query.Get("brand"), query.Get("model"), limit,
+	)
+	if errAutocomplete != nil {
+		response.AppError(w, r, errAutocomplete)
+		return
+	}
+
+	values, errValues := h.vehicleService.Autocomplete(r.Context(), autocomplete)
+	if errValues != nil {
+		response.AppError(w, r, errValues)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(dto.AutocompleteResponseFromDomain(autocomplete, values)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	writeCacheable(w, r, body.Bytes(), time.Time{})
+}
+
+// Search answers POST /vehicles/search, the API v1 representation of the vehicles.
+func (h VehicleHandler) Search(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, vehiclesV1Body)
+}
 
-		key := strings.TrimSpace(keyValue[0])
-		value := strings.TrimSpace(keyValue[1])
+// SearchV2 answers POST /v2/vehicles/search, the API v2 representation of the vehicles.
+func (h VehicleHandler) SearchV2(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, vehiclesV2Body)
+}
 
-		switch value {
+// search answers a search whose body is a dto.SearchVehicleRequest. Unlike get, the filter is a tree
+// of and, or and not groups of conditions compared by any operator.
+func (h VehicleHandler) search(w http.ResponseWriter, r *http.Request, toBody vehiclesBody) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request dto.SearchVehicleRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSearchBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		response.Error(w, r, "Corpo da requisicao invalido", http.StatusBadRequest)
+		return
+	}
+	if request.Filter == nil {
+		response.Error(w, r, "Campo filter e obrigatorio", http.StatusBadRequest)
+		return
+	}
+
+	filter, errFilter := request.Filter.ToDomain()
+	if errFilter != nil {
+		response.AppError(w, r, errFilter)
+		return
+	}
+	builder := domain.NewVehicleQueryBuilder().Match(filter).Select(request.Fields...)
+	for index, sort := range request.Order {
+		switch sort.Direction {
 		case "asc":
-			orderBy[key] = false
+			builder.OrderBy(sort.Field, false)
 		case "desc":
-			orderBy[key] = true
+			builder.OrderBy(sort.Field, true)
 		default:
-			return nil, errs.NewBadRequestError(
-				fmt.Sprintf("Clausula order %d: Value deve ser asc ou desc", index),
-			)
+			response.Error(w, r, fmt.Sprintf("Ordenacao %d: direction deve ser asc ou desc", index), http.StatusBadRequest)
+			return
+		}
+	}
+	limit := domain.DefaultLimit
+	if request.Limit != nil {
+		limit = *request.Limit
+	}
+
+	query, errQuery := params.Build(builder.Page(request.Offset, limit))
+	if errQuery != nil {
+		response.AppError(w, r, errQuery)
+		return
+	}
+
+	vehicles, errGet := h.vehicleService.GetVehicle(r.Context(), query)
+	if errGet != nil {
+		response.AppError(w, r, errGet)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(toBody(vehicles, query.Fields())); err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+// batchBody converts the results of the lookups of a batch to the response body of an API version.
+type batchBody func(results []domain.VehicleLookupResult) any
+
+func batchV1Body(results []domain.VehicleLookupResult) any {
+	return dto.BatchVehicleResponseFromDomain(results)
+}
+
+func batchV2Body(results []domain.VehicleLookupResult) any {
+	return dto.BatchVehicleV2ResponseFromDomain(results)
+}
+
+// Batch answers POST /vehicles/batch, the API v1 representation of the lookups.
+func (h VehicleHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	h.batch(w, r, batchV1Body)
+}
+
+// BatchV2 answers POST /v2/vehicles/batch, the API v2 representation of the lookups.
+func (h VehicleHandler) BatchV2(w http.ResponseWriter, r *http.Request) {
+	h.batch(w, r, batchV2Body)
+}
+
+// batch answers the vehicle of each fipe code, year model and reference month of the request, see
+// VehicleService.LookupVehicles. Items not found or invalid are reported by their status rather than
+// failing the request.
+func (h VehicleHandler) batch(w http.ResponseWriter, r *http.Request, toBody batchBody) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request dto.BatchVehicleRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		response.Error(w, r, "Corpo da requisicao invalido", http.StatusBadRequest)
+		return
+	}
+	if len(request.Items) == 0 {
+		response.Error(w, r, "Campo items deve possuir no minimo 1 item", http.StatusBadRequest)
+		return
+	}
+
+	results, errLookup := h.vehicleService.LookupVehicles(r.Context(), request.ToDomain())
+	if errLookup != nil {
+		response.AppError(w, r, errLookup)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(toBody(results)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
+}
+
+// MatchListing answers GET /v2/vehicles/match with the catalog vehicles that may be the one of the dealer
+// listing of the description parameter, most confident first. The limit is optional.
+func (h VehicleHandler) MatchListing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	limit := domain.DefaultListingMatches
+	if limitString := query.Get("limit"); limitString != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitString); err != nil {
+			response.Error(w, r, "Limit deve ser um numero inteiro", http.StatusBadRequest)
+			return
 		}
 	}
 
-	return orderBy, nil
+	listing, errListing := params.Listing(domain.SystemClock, query.Get("description"))
+	if errListing != nil {
+		response.AppError(w, r, errListing)
+		return
+	}
+
+	matches, errMatches := h.vehicleService.MatchListing(r.Context(), listing, limit)
+	if errMatches != nil {
+		response.AppError(w, r, errMatches)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(dto.ListingMatchesResponseFromDomain(matches)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+		return
+	}
+	vehicles := make([]domain.Vehicle, 0, len(matches))
+	for _, match := range matches {
+		vehicles = append(vehicles, match.Vehicle)
+	}
+	writeCacheable(w, r, body.Bytes(), latestReferenceMonth(vehicles))
+}
+
+// MatchListings answers POST /v2/vehicles/match/batch with the matches of each listing of the request, see
+// VehicleService.MatchListings. Listings matching nothing or invalid are reported by their status rather
+// than failing the request.
+func (h VehicleHandler) MatchListings(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request dto.BatchListingRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxListingBatchBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		response.Error(w, r, "Corpo da requisicao invalido", http.StatusBadRequest)
+		return
+	}
+	if len(request.Items) == 0 {
+		response.Error(w, r, "Campo items deve possuir no minimo 1 item", http.StatusBadRequest)
+		return
+	}
+	limit := request.Limit
+	if limit == 0 {
+		limit = domain.DefaultListingMatches
+	}
+
+	results, errMatch := h.vehicleService.MatchListings(r.Context(), request.Descriptions(), limit)
+	if errMatch != nil {
+		response.AppError(w, r, errMatch)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(dto.BatchListingResponseFromDomain(results)); err != nil {
+		writeEncodeError(w, r, h.log, err)
+	}
 }
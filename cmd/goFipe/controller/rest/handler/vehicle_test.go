@@ -8,11 +8,15 @@ import (
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
 	"github.com/raffops/gofipe/cmd/goFipe/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,6 +34,7 @@ func TestNewHandler(t *testing.T) {
 
 	mockVehicleService, ctrl := getMockVehicleService(t)
 	t.Cleanup(ctrl.Finish)
+	nopLogger := logger.NewNop()
 
 	tests := []struct {
 		name string
@@ -39,12 +44,12 @@ func TestNewHandler(t *testing.T) {
 		{
 			name: "Single test",
 			args: args{vehicleService: mockVehicleService},
-			want: VehicleHandler{vehicleService: mockVehicleService},
+			want: VehicleHandler{vehicleService: mockVehicleService, log: nopLogger},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewVehicleHandler(tt.args.vehicleService); !reflect.DeepEqual(got, tt.want) {
+			if got := NewVehicleHandler(tt.args.vehicleService, nopLogger); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewVehicleHandler() = %v, want %v", got, tt.want)
 			}
 		})
@@ -77,12 +82,11 @@ func TestVehicleHandler_Get(t *testing.T) {
 				vehicleService: func(service *mockPort.MockVehicleService) {
 					service.EXPECT().
 						GetVehicle(
-							map[string]string{
-								"fipe_code": "111111-1",
-							},
-							map[string]bool{"year": false},
-							0,
-							1,
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("fipe_code", "111111-1").
+								OrderBy("year", false).
+								Page(0, 1).MustBuild(),
 						).Return(
 						[]domain.Vehicle{vehiclesExamples[0]},
 						nil,
@@ -92,6 +96,51 @@ func TestVehicleHandler_Get(t *testing.T) {
 			wantBody:       "[{\"ano\":2021,\"mes\":7,\"fipe_code\":\"111111-1\",\"marca\":\"Acura\",\"modelo\":\"Integra GS 1.8\",\"ano_modelo\":\"1992 Gasolina\",\"autenticacao\":\"1\",\"valor_medio\":700}]\n",
 			wantStatusCode: http.StatusOK,
 		},
+		{
+			name: "where by fipe_code from a reference month to another",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc,month:asc",
+				"from":   "2021-01",
+				"to":     "2021-07",
+				"offset": "0",
+				"limit":  "1",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {
+					service.EXPECT().
+						GetVehicle(
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("fipe_code", "111111-1").
+								OrderBy("year", false).
+								OrderBy("month", false).
+								Period("2021-01", "2021-07").
+								Page(0, 1).MustBuild(),
+						).Return(
+						[]domain.Vehicle{vehiclesExamples[0]},
+						nil,
+					)
+				},
+			},
+			wantBody:       "[{\"ano\":2021,\"mes\":7,\"fipe_code\":\"111111-1\",\"marca\":\"Acura\",\"modelo\":\"Integra GS 1.8\",\"ano_modelo\":\"1992 Gasolina\",\"autenticacao\":\"1\",\"valor_medio\":700}]\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Invalid from",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc",
+				"from":   "01/2021",
+				"offset": "0",
+				"limit":  "1",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {},
+			},
+			wantBody:       `{"message":"From must be a month formatted as YYYY-MM"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
 		{
 			name: "where by year and month",
 			args: map[string]interface{}{
@@ -104,13 +153,13 @@ func TestVehicleHandler_Get(t *testing.T) {
 				vehicleService: func(service *mockPort.MockVehicleService) {
 					service.EXPECT().
 						GetVehicle(
-							map[string]string{
-								"year":  "2021",
-								"month": "7",
-							},
-							map[string]bool{"year": false, "month": false},
-							0,
-							1,
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("year", "2021").
+								Where("month", "7").
+								OrderBy("year", false).
+								OrderBy("month", false).
+								Page(0, 1).MustBuild(),
 						).Return(
 						[]domain.Vehicle{vehiclesExamples[0]},
 						nil,
@@ -131,7 +180,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Clausula where 0 deve ser no formato 'key:value'\n",
+			wantBody:       `{"message":"Clausula where 0 deve ser no formato 'key:value'"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -145,7 +194,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Campo where deve possuir no minimo 1 clausula\n",
+			wantBody:       `{"message":"Campo where deve possuir no minimo 1 clausula"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -159,7 +208,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Clausula order 0: Value deve ser asc ou desc\n",
+			wantBody:       `{"message":"Clausula order 0: Value deve ser asc ou desc"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -173,7 +222,21 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Clausula order 1: Value deve ser asc ou desc\n",
+			wantBody:       `{"message":"Clausula order 1: Value deve ser asc ou desc"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Repeated order by column",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc,year:desc",
+				"offset": "0",
+				"limit":  "1",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {},
+			},
+			wantBody:       `{"message":"Duplicated column: year"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -187,7 +250,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Clausula order 1 deve ser no formato 'key:value'\n",
+			wantBody:       `{"message":"Clausula order 1 deve ser no formato 'key:value'"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -201,7 +264,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Campo order deve possuir no minimo 1 clausula\n",
+			wantBody:       `{"message":"Campo order deve possuir no minimo 1 clausula"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -215,7 +278,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Offset deve ser um numero inteiro\n",
+			wantBody:       `{"message":"Offset deve ser um numero inteiro"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
@@ -229,27 +292,80 @@ func TestVehicleHandler_Get(t *testing.T) {
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {},
 			},
-			wantBody:       "Limit deve ser um numero inteiro\n",
+			wantBody:       `{"message":"Limit deve ser um numero inteiro"}` + "\n",
 			wantStatusCode: http.StatusBadRequest,
 		},
 		{
-			name: "Unexpected error",
+			name: "Where breaks a domain rule",
 			args: map[string]interface{}{
 				"where":  "fipe_code:1",
 				"order":  "year:asc",
 				"offset": "0",
 				"limit":  "1",
 			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {},
+			},
+			wantBody:       `{"message":"Invalid fipe code"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Offset greater than limit",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc",
+				"offset": "20",
+				"limit":  "10",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {
+					service.EXPECT().
+						GetVehicle(
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("fipe_code", "111111-1").
+								OrderBy("year", false).
+								Page(20, 10).MustBuild(),
+						).Return(
+						[]domain.Vehicle{vehiclesExamples[0]},
+						nil,
+					)
+				},
+			},
+			wantBody:       "[{\"ano\":2021,\"mes\":7,\"fipe_code\":\"111111-1\",\"marca\":\"Acura\",\"modelo\":\"Integra GS 1.8\",\"ano_modelo\":\"1992 Gasolina\",\"autenticacao\":\"1\",\"valor_medio\":700}]\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Limit out of range",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc",
+				"offset": "0",
+				"limit":  "0",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {},
+			},
+			wantBody:       `{"message":"Limit must be between 1 and 100"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Unexpected error",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc",
+				"offset": "0",
+				"limit":  "1",
+			},
 			dependencies: Dependencies{
 				vehicleService: func(service *mockPort.MockVehicleService) {
 					service.EXPECT().
 						GetVehicle(
-							map[string]string{
-								"fipe_code": "1",
-							},
-							map[string]bool{"year": false},
-							0,
-							1,
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("fipe_code", "111111-1").
+								OrderBy("year", false).
+								Page(0, 1).MustBuild(),
 						).
 						Return(
 							nil,
@@ -257,9 +373,36 @@ func TestVehicleHandler_Get(t *testing.T) {
 						)
 				},
 			},
-			wantBody:       "Unexpected error\n",
+			wantBody:       `{"message":"Unexpected error"}` + "\n",
 			wantStatusCode: http.StatusInternalServerError,
 		},
+		{
+			name: "Query timeout",
+			args: map[string]interface{}{
+				"where":  "fipe_code:111111-1",
+				"order":  "year:asc",
+				"offset": "0",
+				"limit":  "1",
+			},
+			dependencies: Dependencies{
+				vehicleService: func(service *mockPort.MockVehicleService) {
+					service.EXPECT().
+						GetVehicle(
+							gomock.Any(),
+							domain.NewVehicleQueryBuilder().
+								Where("fipe_code", "111111-1").
+								OrderBy("year", false).
+								Page(0, 1).MustBuild(),
+						).
+						Return(
+							nil,
+							errs.NewGatewayTimeoutError("Database query timed out"),
+						)
+				},
+			},
+			wantBody:       `{"message":"Database query timed out"}` + "\n",
+			wantStatusCode: http.StatusGatewayTimeout,
+		},
 	}
 	appHost := os.Getenv("APP_HOST")
 	appPort := os.Getenv("APP_PORT")
@@ -280,7 +423,7 @@ func TestVehicleHandler_Get(t *testing.T) {
 			}
 			rr := httptest.NewRecorder()
 			tt.dependencies.vehicleService(mockVehicleService)
-			vehicleHandler := VehicleHandler{vehicleService: mockVehicleService}
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
 			handler := http.HandlerFunc(vehicleHandler.Get)
 			handler.ServeHTTP(rr, req)
 
@@ -290,109 +433,825 @@ func TestVehicleHandler_Get(t *testing.T) {
 	}
 }
 
-func Test_handleWhereParameter(t *testing.T) {
-	testCases := []struct {
-		name    string
-		input   string
-		want    map[string]string
-		wantErr *errs.AppError
+func TestVehicleHandler_Search(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+
+	tests := []struct {
+		name           string
+		body           string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
 	}{
 		{
-			name:  "Normal use case",
-			input: "fipe_code:1,year:2021,month:7",
-			want: map[string]string{
-				"fipe_code": "1",
-				"year":      "2021",
-				"month":     "7",
+			name: "Filter tree",
+			body: `{
+				"filter": {"and": [
+					{"or": [{"field": "brand", "op": "eq", "value": "Fiat"}, {"field": "brand", "op": "eq", "value": "VW"}]},
+					{"field": "mean_value", "op": "between", "value": [30000, 50000]},
+					{"field": "year", "op": "eq", "value": 2021}
+				]},
+				"order": [{"field": "mean_value", "direction": "desc"}],
+				"offset": 10,
+				"limit": 1
+			}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Match(domain.And(
+							domain.Or(domain.Compare("brand", "eq", "Fiat"), domain.Compare("brand", "eq", "VW")),
+							domain.Compare("mean_value", "between", "30000", "50000"),
+							domain.Compare("year", "eq", "2021"),
+						)).
+						OrderBy("mean_value", true).
+						Page(10, 1).
+						MustBuild()).
+					Return(vehicles[1:2], nil)
+			},
+			wantBody: `[{"ano":2021,"mes":6,"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL",` +
+				`"ano_modelo":"1991 Gasolina","autenticacao":"2","valor_medio":800}]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Default page",
+			body: `{"filter": {"field": "fipe_code", "op": "in", "value": ["111111-1", "222222-2"]}}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Match(domain.Compare("fipe_code", "in", "111111-1", "222222-2")).
+						MustBuild()).
+					Return(nil, errs.NewNotFoundError("Vehicles not found"))
 			},
-			wantErr: nil,
+			wantBody:       `{"message":"Vehicles not found"}` + "\n",
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid body",
+			body:           `{"filter": {"field": "year", "op": "eq", "value": 2021}, "where": "year:2021"}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Corpo da requisicao invalido"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing filter",
+			body:           `{"limit": 10}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Campo filter e obrigatorio"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Malformed filter",
+			body:           `{"filter": {"not": {"field": "year", "op": "eq", "value": 2021}, "or": []}}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Filtro deve possuir exatamente um de and, or, not ou field"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid direction",
+			body:           `{"filter": {"field": "year", "op": "eq", "value": 2021}, "order": [{"field": "year", "direction": "up"}]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Ordenacao 0: direction deve ser asc ou desc"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
 		},
 		{
-			name:    "Invalid where clause",
-			input:   "fipe_code:1,year:2021,month",
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Clausula where 2 deve ser no formato 'key:value'"),
+			name:           "Filter breaks a domain rule",
+			body:           `{"filter": {"field": "brand", "op": "gt", "value": "Fiat"}}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Operator gt does not apply to brand"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Limit out of range",
+			body:           `{"filter": {"field": "year", "op": "eq", "value": 2021}, "limit": 0}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Limit must be between 1 and 100"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("POST", "/vehicles/search", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
+			http.HandlerFunc(vehicleHandler.Search).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_GetHistory(t *testing.T) {
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	july2021 := june2021.AddMonths(1)
+	history := domain.VehicleHistory{
+		FipeCode: "222222-2",
+		Brand:    "Fiat",
+		Model:    "147 C/ CL",
+		Period:   domain.Period{From: june2021, To: july2021},
+		Prices: []domain.VehiclePrice{
+			{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800},
+			{ReferenceMonth: july2021, YearModel: "1991 Gasolina", MeanValue: 801},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		target           string
+		vehicleService   func(service *mockPort.MockVehicleService)
+		wantBody         string
+		wantStatusCode   int
+		wantLastModified string
+	}{
+		{
+			name:   "Prices from a reference month to another",
+			target: "/vehicles/222222-2/history?from=2021-06&to=2021-07",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetHistory(gomock.Any(), "222222-2", domain.Period{From: june2021, To: july2021}).
+					Return(history, nil)
+			},
+			wantBody: `{"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL","de":"2021-06","ate":"2021-07",` +
+				`"precos":[{"mes_referencia":"2021-06","ano_modelo":"1991 Gasolina","valor_medio":800},` +
+				`{"mes_referencia":"2021-07","ano_modelo":"1991 Gasolina","valor_medio":801}]}` + "\n",
+			wantStatusCode:   http.StatusOK,
+			wantLastModified: "Thu, 01 Jul 2021 00:00:00 GMT",
 		},
 		{
-			name:    "Empty where clause",
-			input:   "",
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Campo where deve possuir no minimo 1 clausula"),
+			name:   "Default period",
+			target: "/vehicles/222222-2/history",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetHistory(gomock.Any(), "222222-2", domain.Period{}).
+					Return(domain.VehicleHistory{}, errs.NewNotFoundError("Vehicles not found"))
+			},
+			wantBody:       `{"message":"Vehicles not found"}` + "\n",
+			wantStatusCode: http.StatusNotFound,
 		},
 		{
-			name:    "Invalid where clause",
-			input:   "fipe_code:1,year:2021,month:",
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Clausula where 2 deve ser no formato 'key:value'"),
+			name:           "Invalid from",
+			target:         "/vehicles/222222-2/history?from=2021-13",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"From must be a month formatted as YYYY-MM"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
 		},
 		{
-			name:    "Invalid where clause",
-			input:   "fipe_code:1,year:2021,month:7,",
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Clausula where 3 deve ser no formato 'key:value'"),
+			name:           "From after to",
+			target:         "/vehicles/222222-2/history?from=2021-08&to=2021-07",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"From must not be after to"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
 		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			router := mux.NewRouter()
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
+			router.HandleFunc("/vehicles/{fipe_code}/history", vehicleHandler.GetHistory)
+			req := httptest.NewRequest("GET", tt.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
 
-	for _, tt := range testCases {
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			assert.Equal(t, tt.wantLastModified, rr.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestVehicleHandler_GetPriceAsOf(t *testing.T) {
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	claimDate := time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		target         string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:   "Price of the latest reference month on or before the date",
+			target: "/vehicles/222222-2/price?as_of=2021-07-15&year_model=1991+Gasolina",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", claimDate).
+					Return(domain.VehiclePriceAsOf{
+						FipeCode: "222222-2",
+						Brand:    "Fiat",
+						Model:    "147 C/ CL",
+						AsOf:     claimDate,
+						Price:    domain.VehiclePrice{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800},
+					}, nil)
+			},
+			wantBody: `{"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL","ano_modelo":"1991 Gasolina",` +
+				`"data":"2021-07-15","mes_referencia":"2021-06","valor_medio":800}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "No price",
+			target: "/vehicles/222222-2/price?as_of=2021-07-15&year_model=1991+Gasolina",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", claimDate).
+					Return(domain.VehiclePriceAsOf{}, errs.NewNotFoundError("Price not found"))
+			},
+			wantBody:       `{"message":"Price not found"}` + "\n",
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "Missing as_of",
+			target:         "/vehicles/222222-2/price?year_model=1991+Gasolina",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"As_of deve ser uma data no formato YYYY-MM-DD"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid as_of",
+			target:         "/vehicles/222222-2/price?as_of=15/07/2021&year_model=1991+Gasolina",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"As_of deve ser uma data no formato YYYY-MM-DD"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, gotErr := handleWhereParameter(tt.input)
-			assert.Equalf(t, tt.want, got, "handleWhereParameter(%v)", tt.input)
-			assert.Equalf(t, tt.wantErr, gotErr, "handleWhereParameter(%v)", tt.input)
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			router := mux.NewRouter()
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
+			router.HandleFunc("/vehicles/{fipe_code}/price", vehicleHandler.GetPriceAsOf)
+			req := httptest.NewRequest("GET", tt.target, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
 		})
 	}
 }
 
-func Test_handleOrderByParameter(t *testing.T) {
-	type args struct {
-		orderByString string
+func TestVehicleHandler_Batch(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	found := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: june2021}
+	notFound := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1992 Gasolina", ReferenceMonth: june2021}
+	badMonth := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina"}
+
+	tests := []struct {
+		name           string
+		body           string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name: "Status of each item",
+			body: `{"items": [
+				{"fipe_code": "222222-2", "year_model": "1991 Gasolina", "reference_month": "2021-06"},
+				{"fipe_code": "222222-2", "year_model": "1992 Gasolina", "reference_month": "2021-06"},
+				{"fipe_code": "222222-2", "year_model": "1991 Gasolina", "reference_month": "06/2021"}
+			]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					LookupVehicles(gomock.Any(), []domain.VehicleLookup{found, notFound, badMonth}).
+					Return([]domain.VehicleLookupResult{
+						{Lookup: found, Status: domain.LookupFound, Vehicle: vehicles[1]},
+						{Lookup: notFound, Status: domain.LookupNotFound},
+						{Lookup: badMonth, Status: domain.LookupInvalid, Message: "Invalid reference month"},
+					}, nil)
+			},
+			wantBody: `{"items":[` +
+				`{"fipe_code":"222222-2","ano_modelo":"1991 Gasolina","mes_referencia":"2021-06","status":"found",` +
+				`"veiculo":{"ano":2021,"mes":6,"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL",` +
+				`"ano_modelo":"1991 Gasolina","autenticacao":"2","valor_medio":800}},` +
+				`{"fipe_code":"222222-2","ano_modelo":"1992 Gasolina","mes_referencia":"2021-06","status":"not_found"},` +
+				`{"fipe_code":"222222-2","ano_modelo":"1991 Gasolina","mes_referencia":"","status":"invalid",` +
+				`"mensagem":"Invalid reference month"}]}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Too many items",
+			body: `{"items": [{"fipe_code": "222222-2", "year_model": "1991 Gasolina", "reference_month": "2021-06"}]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					LookupVehicles(gomock.Any(), []domain.VehicleLookup{found}).
+					Return(nil, errs.NewValidationError("At most 500 lookups per batch"))
+			},
+			wantBody:       `{"message":"At most 500 lookups per batch"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "No items",
+			body:           `{"items": []}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Campo items deve possuir no minimo 1 item"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid body",
+			body:           `{"items": [{"fipe_code": "222222-2", "year": 2021}]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Corpo da requisicao invalido"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("POST", "/vehicles/batch", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
+			http.HandlerFunc(vehicleHandler.Batch).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_Versions(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	claimDate := time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC)
+	found := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: june2021}
+	notFound := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1992 Gasolina", ReferenceMonth: june2021}
+
 	tests := []struct {
-		name    string
-		args    args
-		want    map[string]bool
-		wantErr *errs.AppError
+		name           string
+		method         string
+		target         string
+		vars           map[string]string
+		body           string
+		handler        func(h VehicleHandler) http.HandlerFunc
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
 	}{
 		{
-			name: "Test normal use case",
-			args: args{
-				orderByString: "key1:asc,key2:desc",
+			name:    "v1 with fields",
+			method:  "GET",
+			target:  "/vehicles?where=fipe_code:222222-2&order=year:asc&fields=fipe_code,mean_value&offset=0&limit=1",
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.Get },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Where("fipe_code", "222222-2").
+						OrderBy("year", false).
+						Select("fipe_code", "mean_value").
+						Page(0, 1).
+						MustBuild()).
+					Return([]domain.Vehicle{{FipeCode: "222222-2", MeanValue: 800}}, nil)
 			},
-			want: map[string]bool{
-				"key1": false,
-				"key2": true,
+			wantBody:       `[{"fipe_code":"222222-2","valor_medio":800}]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:    "v2",
+			method:  "GET",
+			target:  "/v2/vehicles?where=fipe_code:222222-2&order=year:asc&offset=0&limit=1",
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.GetV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Where("fipe_code", "222222-2").
+						OrderBy("year", false).
+						Page(0, 1).
+						MustBuild()).
+					Return(vehicles[1:2], nil)
 			},
-			wantErr: nil,
+			wantBody: `[{"fipe_code":"222222-2","reference_month":{"year":2021,"month":6},` +
+				`"model":{"brand":"Fiat","name":"147 C/ CL","year_model":"1991 Gasolina"},` +
+				`"price":{"mean_value":800,"authentication":"2"}}]` + "\n",
+			wantStatusCode: http.StatusOK,
 		},
 		{
-			name: "Test with abnormal case, clauses with invalid format",
-			args: args{
-				orderByString: "key1:asc,key2:desc,key3",
+			name:           "v2 with an invalid field",
+			method:         "GET",
+			target:         "/v2/vehicles?where=fipe_code:222222-2&order=year:asc&fields=price&offset=0&limit=1",
+			handler:        func(h VehicleHandler) http.HandlerFunc { return h.GetV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Invalid field: price"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "v2 search with fields",
+			method:  "POST",
+			target:  "/v2/vehicles/search",
+			body:    `{"filter": {"field": "year", "op": "eq", "value": 2021}, "fields": ["year", "month", "brand"]}`,
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.SearchV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetVehicle(gomock.Any(), domain.NewVehicleQueryBuilder().
+						Match(domain.Compare("year", "eq", "2021")).
+						Select("year", "month", "brand").
+						MustBuild()).
+					Return([]domain.Vehicle{{Year: 2021, Month: 6, Brand: "Fiat"}}, nil)
+			},
+			wantBody:       `[{"reference_month":{"year":2021,"month":6},"model":{"brand":"Fiat"}}]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "v2 batch",
+			method: "POST",
+			target: "/v2/vehicles/batch",
+			body: `{"items": [
+				{"fipe_code": "222222-2", "year_model": "1991 Gasolina", "reference_month": "2021-06"},
+				{"fipe_code": "222222-2", "year_model": "1992 Gasolina", "reference_month": "2021-06"}
+			]}`,
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.BatchV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					LookupVehicles(gomock.Any(), []domain.VehicleLookup{found, notFound}).
+					Return([]domain.VehicleLookupResult{
+						{Lookup: found, Status: domain.LookupFound, Vehicle: vehicles[1]},
+						{Lookup: notFound, Status: domain.LookupNotFound, Message: "Vehicle not found"},
+					}, nil)
 			},
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Clausula order 2 deve ser no formato 'key:value'"),
+			wantBody: `{"items":[` +
+				`{"fipe_code":"222222-2","year_model":"1991 Gasolina","reference_month":"2021-06","status":"found",` +
+				`"vehicle":{"fipe_code":"222222-2","reference_month":{"year":2021,"month":6},` +
+				`"model":{"brand":"Fiat","name":"147 C/ CL","year_model":"1991 Gasolina"},` +
+				`"price":{"mean_value":800,"authentication":"2"}}},` +
+				`{"fipe_code":"222222-2","year_model":"1992 Gasolina","reference_month":"2021-06","status":"not_found",` +
+				`"message":"Vehicle not found"}]}` + "\n",
+			wantStatusCode: http.StatusOK,
 		},
 		{
-			name: "Test with abnormal case, empty value",
-			args: args{
-				orderByString: "key1:asc,key2:",
+			name:    "v2 history",
+			method:  "GET",
+			target:  "/v2/vehicles/222222-2/history?from=2021-06&to=2021-06",
+			vars:    map[string]string{"fipe_code": "222222-2"},
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.GetHistoryV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetHistory(gomock.Any(), "222222-2", domain.Period{From: june2021, To: june2021}).
+					Return(domain.VehicleHistory{
+						FipeCode: "222222-2",
+						Brand:    "Fiat",
+						Model:    "147 C/ CL",
+						Period:   domain.Period{From: june2021, To: june2021},
+						Prices:   []domain.VehiclePrice{{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800}},
+					}, nil)
 			},
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Clausula order 1 deve ser no formato 'key:value'"),
+			wantBody: `{"fipe_code":"222222-2","brand":"Fiat","model":"147 C/ CL","from":"2021-06","to":"2021-06",` +
+				`"prices":[{"reference_month":"2021-06","year_model":"1991 Gasolina","mean_value":800}]}` + "\n",
+			wantStatusCode: http.StatusOK,
 		},
 		{
-			name: "Test without clauses",
-			args: args{
-				orderByString: "",
+			name:    "v2 price as of a date",
+			method:  "GET",
+			target:  "/v2/vehicles/222222-2/price?as_of=2021-07-15&year_model=1991+Gasolina",
+			vars:    map[string]string{"fipe_code": "222222-2"},
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.GetPriceAsOfV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", claimDate).
+					Return(domain.VehiclePriceAsOf{
+						FipeCode: "222222-2",
+						Brand:    "Fiat",
+						Model:    "147 C/ CL",
+						AsOf:     claimDate,
+						Price:    domain.VehiclePrice{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800},
+					}, nil)
 			},
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Campo order deve possuir no minimo 1 clausula"),
+			wantBody: `{"fipe_code":"222222-2","brand":"Fiat","model":"147 C/ CL","year_model":"1991 Gasolina",` +
+				`"as_of":"2021-07-15","reference_month":"2021-06","mean_value":800}` + "\n",
+			wantStatusCode: http.StatusOK,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := handleOrderByParameter(tt.args.orderByString)
-			assert.Equalf(t, tt.want, got, "handleOrderByParameter(%v)", tt.args.orderByString)
-			assert.Equalf(t, tt.wantErr, got1, "handleOrderByParameter(%v)", tt.args.orderByString)
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)), tt.vars)
+			rr := httptest.NewRecorder()
+			tt.handler(NewVehicleHandler(mockVehicleService, logger.NewNop())).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_SearchText(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	mustSearch := func(text string, offset int, limit int) domain.TextSearch {
+		search, err := domain.NewTextSearch(text, offset, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return search
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		handler        func(h VehicleHandler) http.HandlerFunc
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:    "v1 with the default page",
+			target:  "/vehicles/search?q=fiat+147",
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.SearchText },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					SearchVehicles(gomock.Any(), mustSearch("fiat 147", 0, domain.DefaultLimit)).
+					Return([]domain.VehicleMatch{{Vehicle: vehicles[2], Score: 1}}, nil)
+			},
+			wantBody: `[{"veiculo":{"ano":2021,"mes":7,"fipe_code":"222222-2","marca":"Fiat","modelo":"147 C/ CL",` +
+				`"ano_modelo":"1991 Gasolina","autenticacao":"2","valor_medio":801},"relevancia":1}]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:    "v2 with a page",
+			target:  "/v2/vehicles/search?q=Integra&offset=10&limit=5",
+			handler: func(h VehicleHandler) http.HandlerFunc { return h.SearchTextV2 },
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					SearchVehicles(gomock.Any(), mustSearch("integra", 10, 5)).
+					Return([]domain.VehicleMatch{{Vehicle: vehicles[0], Score: 0.5}}, nil)
+			},
+			wantBody: `[{"vehicle":{"fipe_code":"111111-1","reference_month":{"year":2021,"month":7},` +
+				`"model":{"brand":"Acura","name":"Integra GS 1.8","year_model":"1992 Gasolina"},` +
+				`"price":{"mean_value":700,"authentication":"1"}},"score":0.5}]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Missing q",
+			target:         "/vehicles/search",
+			handler:        func(h VehicleHandler) http.HandlerFunc { return h.SearchText },
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Q must have between 1 and 100 characters"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			target:         "/vehicles/search?q=gol&limit=ten",
+			handler:        func(h VehicleHandler) http.HandlerFunc { return h.SearchText },
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Limit deve ser um numero inteiro"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("GET", tt.target, nil)
+			rr := httptest.NewRecorder()
+			tt.handler(NewVehicleHandler(mockVehicleService, logger.NewNop())).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_Autocomplete(t *testing.T) {
+	mustAutocomplete := func(field string, prefix string, brand string, model string, limit int) domain.Autocomplete {
+		autocomplete, err := domain.NewAutocomplete(field, prefix, brand, model, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return autocomplete
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:   "brands with the default limit",
+			target: "/v2/autocomplete?field=brand&prefix=fi",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					Autocomplete(gomock.Any(), mustAutocomplete("brand", "fi", "", "", domain.DefaultLimit)).
+					Return([]domain.AutocompleteValue{{Value: "Fiat", Count: 12}}, nil)
+			},
+			wantBody:       `{"field":"brand","values":[{"value":"Fiat","count":12}]}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "year models of a model, none found",
+			target: "/v2/autocomplete?field=year_model&prefix=2030&brand=Fiat&model=147+C%2F+CL&limit=5",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					Autocomplete(gomock.Any(), mustAutocomplete("year_model", "2030", "Fiat", "147 C/ CL", 5)).
+					Return([]domain.AutocompleteValue{}, nil)
+			},
+			wantBody:       `{"field":"year_model","values":[]}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid field",
+			target:         "/v2/autocomplete?field=mean_value&prefix=1",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Field must be one of brand, model or year_model"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Brand does not narrow brands",
+			target:         "/v2/autocomplete?field=brand&brand=Fiat",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Brand does not narrow brand"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			target:         "/v2/autocomplete?field=brand&limit=ten",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Limit deve ser um numero inteiro"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("GET", tt.target, nil)
+			rr := httptest.NewRecorder()
+			NewVehicleHandler(mockVehicleService, logger.NewNop()).Autocomplete(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_MatchListing(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	listing, errListing := domain.NewListing(domain.SystemClock, "FIAT 147 CL 1991 GAS")
+	if errListing != nil {
+		t.Fatal(errListing)
+	}
+
+	tests := []struct {
+		name           string
+		target         string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name:   "matches with the default limit",
+			target: "/v2/vehicles/match?description=FIAT+147+CL+1991+GAS",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					MatchListing(gomock.Any(), listing, domain.DefaultListingMatches).
+					Return([]domain.ListingMatch{{Vehicle: vehicles[2], Confidence: 0.91000001}}, nil)
+			},
+			wantBody: `[` +
+				`{"vehicle":{"fipe_code":"222222-2","reference_month":{"year":2021,"month":7},` +
+				`"model":{"brand":"Fiat","name":"147 C/ CL","year_model":"1991 Gasolina"},` +
+				`"price":{"mean_value":801,"authentication":"2"}},"confidence":0.91}` +
+				`]` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "no match",
+			target: "/v2/vehicles/match?description=FIAT+147+CL+1991+GAS&limit=3",
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					MatchListing(gomock.Any(), listing, 3).
+					Return(nil, errs.NewNotFoundError("Matches not found"))
+			},
+			wantBody:       `{"message":"Matches not found"}` + "\n",
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "Missing description",
+			target:         "/v2/vehicles/match",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Description must have between 1 and 200 characters"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			target:         "/v2/vehicles/match?description=gol&limit=ten",
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Limit deve ser um numero inteiro"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("GET", tt.target, nil)
+			rr := httptest.NewRecorder()
+			NewVehicleHandler(mockVehicleService, logger.NewNop()).MatchListing(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+		})
+	}
+}
+
+func TestVehicleHandler_MatchListings(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+
+	tests := []struct {
+		name           string
+		body           string
+		vehicleService func(service *mockPort.MockVehicleService)
+		wantBody       string
+		wantStatusCode int
+	}{
+		{
+			name: "Status of each item",
+			body: `{"items": [{"description": "FIAT 147 CL 1991 GAS"}, {"description": "2015 flex"}], "limit": 1}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					MatchListings(gomock.Any(), []string{"FIAT 147 CL 1991 GAS", "2015 flex"}, 1).
+					Return([]domain.ListingMatchResult{
+						{
+							Description: "FIAT 147 CL 1991 GAS",
+							Status:      domain.LookupFound,
+							Matches:     []domain.ListingMatch{{Vehicle: vehicles[2], Confidence: 0.91}},
+						},
+						{
+							Description: "2015 flex",
+							Status:      domain.LookupInvalid,
+							Message:     "Description must have at least 1 word of the brand or the model",
+						},
+					}, nil)
+			},
+			wantBody: `{"items":[` +
+				`{"description":"FIAT 147 CL 1991 GAS","status":"found","matches":[` +
+				`{"vehicle":{"fipe_code":"222222-2","reference_month":{"year":2021,"month":7},` +
+				`"model":{"brand":"Fiat","name":"147 C/ CL","year_model":"1991 Gasolina"},` +
+				`"price":{"mean_value":801,"authentication":"2"}},"confidence":0.91}` +
+				`]},` +
+				`{"description":"2015 flex","status":"invalid",` +
+				`"message":"Description must have at least 1 word of the brand or the model"}]}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "Default limit",
+			body: `{"items": [{"description": "Corcel II 1984"}]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {
+				service.EXPECT().
+					MatchListings(gomock.Any(), []string{"Corcel II 1984"}, domain.DefaultListingMatches).
+					Return([]domain.ListingMatchResult{{Description: "Corcel II 1984", Status: domain.LookupNotFound}}, nil)
+			},
+			wantBody:       `{"items":[{"description":"Corcel II 1984","status":"not_found"}]}` + "\n",
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "No items",
+			body:           `{"items": []}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Campo items deve possuir no minimo 1 item"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid body",
+			body:           `{"items": [{"descricao": "Corcel II 1984"}]}`,
+			vehicleService: func(service *mockPort.MockVehicleService) {},
+			wantBody:       `{"message":"Corpo da requisicao invalido"}` + "\n",
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleService, ctrl := getMockVehicleService(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleService(mockVehicleService)
+
+			req := httptest.NewRequest("POST", "/v2/vehicles/match/batch", strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			vehicleHandler := NewVehicleHandler(mockVehicleService, logger.NewNop())
+			http.HandlerFunc(vehicleHandler.MatchListings).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
 		})
 	}
 }
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+const (
+	ApiKeyHeader     = "X-API-Key"
+	AdminTokenHeader = "X-Admin-Token"
+)
+
+type apiKeyContextKey struct{}
+
+// ApiKeyFromContext returns the API key authenticated by AuthMiddleware, if any.
+func ApiKeyFromContext(ctx context.Context) (domain.ApiKey, bool) {
+	apiKey, ok := ctx.Value(apiKeyContextKey{}).(domain.ApiKey)
+	return apiKey, ok
+}
+
+// AuthMiddleware rejects requests without a valid API key in the X-API-Key header. The request-scoped
+// logger of accepted requests is extended with the key prefix. Accepted requests are accounted by
+// UsageMiddleware.
+func AuthMiddleware(apiKeyService ports.ApiKeyService, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			apiKey, err := apiKeyService.Authenticate(r.Context(), r.Header.Get(ApiKeyHeader))
+			requestLog := logger.FromContext(r.Context(), log)
+			if err != nil {
+				requestLog.Info("Request not authenticated",
+					logger.String("path", r.URL.EscapedPath()),
+					logger.String("reason", err.Message),
+				)
+				response.AppError(w, r, err)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), apiKeyContextKey{}, apiKey)
+			ctx = logger.NewContext(ctx, requestLog.With(logger.String("api_key", apiKey.Prefix)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// UsageMiddleware accounts the request in the monthly usage of the API key authenticated by
+// AuthMiddleware and rejects it with 429 Too Many Requests once the monthly quota is exhausted. It goes
+// after the rate limiters, so requests they reject are not accounted. Requests without an API key are
+// let through.
+func UsageMiddleware(apiKeyService ports.ApiKeyService, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			apiKey, ok := ApiKeyFromContext(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err := apiKeyService.AccountUsage(r.Context(), apiKey); err != nil {
+				logger.FromContext(r.Context(), log).Info("Request not accounted",
+					logger.String("path", r.URL.EscapedPath()),
+					logger.String("reason", err.Message),
+				)
+				response.AppError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// AdminMiddleware protects the management endpoints with a static token sent in the X-Admin-Token header.
+// If adminToken is empty the management endpoints are disabled.
+func AdminMiddleware(adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if adminToken == "" {
+				response.Error(w, r, "Admin endpoints are disabled", http.StatusForbidden)
+				return
+			}
+
+			token := r.Header.Get(AdminTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+				response.Error(w, r, "Invalid admin token", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
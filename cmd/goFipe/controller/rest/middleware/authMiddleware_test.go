@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// newApiChain chains the middlewares in the order the API routes use them.
+func newApiChain(apiKeyService *mockPort.MockApiKeyService, backend RateLimitBackend, apiKeyLimit RateLimit) http.Handler {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	log := logger.NewNop()
+	handler := UsageMiddleware(apiKeyService, log)(okHandler)
+	handler = RateLimitMiddleware(backend, apiKeyLimit, ApiKeyKey, log)(handler)
+	return AuthMiddleware(apiKeyService, log)(handler)
+}
+
+func TestUsageMiddleware(t *testing.T) {
+	apiKey := domain.ApiKey{ID: 7, Prefix: "gf_abc", MonthlyQuota: 10}
+
+	tests := []struct {
+		name            string
+		apiKeyLimit     RateLimit
+		apiKeyService   func(service *mockPort.MockApiKeyService)
+		wantStatusCodes []int
+	}{
+		{
+			name: "Accepted requests are accounted",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Authenticate(gomock.Any(), "gf_secret").Return(apiKey, nil).Times(2)
+				service.EXPECT().AccountUsage(gomock.Any(), apiKey).Return(nil).Times(2)
+			},
+			wantStatusCodes: []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:        "Throttled request is not accounted",
+			apiKeyLimit: RateLimit{Rate: 1, Burst: 1},
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Authenticate(gomock.Any(), "gf_secret").Return(apiKey, nil).Times(2)
+				service.EXPECT().AccountUsage(gomock.Any(), apiKey).Return(nil).Times(1)
+			},
+			wantStatusCodes: []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name: "Quota exceeded",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Authenticate(gomock.Any(), "gf_secret").Return(apiKey, nil).Times(1)
+				service.EXPECT().AccountUsage(gomock.Any(), apiKey).
+					Return(errs.NewTooManyRequestsError("Monthly quota exceeded")).Times(1)
+			},
+			wantStatusCodes: []int{http.StatusTooManyRequests},
+		},
+		{
+			name: "Unauthenticated request is not accounted",
+			apiKeyService: func(service *mockPort.MockApiKeyService) {
+				service.EXPECT().Authenticate(gomock.Any(), "gf_secret").
+					Return(domain.ApiKey{}, errs.NewUnauthorizedError("Invalid api key")).Times(1)
+				service.EXPECT().AccountUsage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantStatusCodes: []int{http.StatusUnauthorized},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			apiKeyService := mockPort.NewMockApiKeyService(ctrl)
+			tt.apiKeyService(apiKeyService)
+			backend, _ := newTestBackend()
+			handler := newApiChain(apiKeyService, backend, tt.apiKeyLimit)
+
+			for _, wantStatusCode := range tt.wantStatusCodes {
+				req := httptest.NewRequest("GET", "/vehicles", nil)
+				req.Header.Set(ApiKeyHeader, "gf_secret")
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+				assert.Equal(t, wantStatusCode, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DeprecationMiddleware marks the responses of deprecated routes with the Deprecation header and links the
+// same path under successorPrefix as their successor version, e.g. /vehicles to /v2/vehicles. The Sunset
+// header tells clients when the routes are removed, unless sunset is zero.
+func DeprecationMiddleware(successorPrefix string, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.Path))
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		sunset     time.Time
+		wantSunset string
+	}{
+		{
+			name:       "with a sunset",
+			sunset:     time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+			wantSunset: "Fri, 30 Apr 2027 00:00:00 GMT",
+		},
+		{
+			name: "without a sunset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := DeprecationMiddleware("/v2", tt.sunset)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("POST", "/vehicles/search", nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+			assert.Equal(t, `</v2/vehicles/search>; rel="successor-version"`, rr.Header().Get("Link"))
+			assert.Equal(t, tt.wantSunset, rr.Header().Get("Sunset"))
+		})
+	}
+}
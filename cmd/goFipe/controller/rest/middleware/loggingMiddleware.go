@@ -2,19 +2,43 @@ package middleware
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
 	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"io"
+	mathRand "math/rand"
 	"net/http"
-	"runtime/debug"
+	"regexp"
 	"time"
 )
 
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID restricts the accepted X-Request-ID values, so a client cannot inject arbitrary
+// content in the logs and in the response headers.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// BodyLogging adds the request and response bodies of a sample of the requests to the access log.
+// Sensitive JSON fields are redacted and bodies larger than MaxBytes are omitted.
+type BodyLogging struct {
+	SampleRate float64
+	MaxBytes   int
+}
+
+func (b BodyLogging) sample() bool {
+	return b.SampleRate > 0 && b.MaxBytes > 0 && mathRand.Float64() < b.SampleRate
+}
+
 // responseWriter is a minimal wrapper for http.ResponseWriter that allows the
-// written HTTP status code to be captured for logging.
+// written HTTP status code and body size to be captured for logging.
 type responseWriter struct {
 	http.ResponseWriter
 	status      int
 	wroteHeader bool
-	body        bytes.Buffer
+	size        int
+	// body captures the response only when it is logged.
+	body *cappedBuffer
 }
 
 func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
@@ -40,32 +64,87 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 		rw.WriteHeader(http.StatusOK)
 	}
 
-	rw.body.Write(b)
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += n
+	if rw.body != nil {
+		_, _ = rw.body.Write(b[:n])
+	}
+	return n, err
+}
+
+// cappedBuffer keeps at most max bytes and remembers whether more were written.
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(b []byte) (int, error) {
+	if room := c.max - c.Len(); len(b) > room {
+		c.truncated = true
+		b = b[:max(room, 0)]
+	}
+	c.Buffer.Write(b)
+	return len(b), nil
 }
 
-// LoggingMiddleware logs the request and response of each HTTP request.
-func LoggingMiddleware() func(http.Handler) http.Handler {
+// LoggingMiddleware writes an access log entry for each HTTP request. It identifies the request by the
+// X-Request-ID header, or by a generated ID when the header is missing or invalid, and returns the ID
+// in the response header and in the error bodies. A child of log carrying the ID is stored in the
+// request context, so every entry logged while serving the request carries it, see logger.FromContext.
+func LoggingMiddleware(log logger.Logger, bodyLogging BodyLogging) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
-			defer func() {
-				if err := recover(); err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-					logger.Error("Panic occurred", logger.String("error", string(debug.Stack())))
-				}
-			}()
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+			requestLog := log.With(logger.String("request_id", requestID))
+			ctx := response.WithRequestID(r.Context(), requestID)
+			r = r.WithContext(logger.NewContext(ctx, requestLog))
 
-			start := time.Now()
 			wrapped := wrapResponseWriter(w)
+			var requestBody *cappedBuffer
+			logBodies := bodyLogging.sample()
+			if logBodies {
+				requestBody = &cappedBuffer{max: bodyLogging.MaxBytes}
+				wrapped.body = &cappedBuffer{max: bodyLogging.MaxBytes}
+				r.Body = readCloser{Reader: io.TeeReader(r.Body, requestBody), Closer: r.Body}
+			}
+
+			start := time.Now()
 			next.ServeHTTP(wrapped, r)
-			logger.Info("Request completed",
+
+			fields := []logger.Field{
 				logger.Int("status", wrapped.status),
-				// logger.String("body", wrapped.body.String()),
 				logger.String("method", r.Method),
 				logger.String("path", r.URL.EscapedPath()),
-				logger.String("duration", time.Since(start).String()),
-			)
+				logger.String("query", redactQuery(r.URL.Query())),
+				logger.String("client_ip", clientIP(r)),
+				logger.String("user_agent", r.UserAgent()),
+				logger.Int("response_size", wrapped.size),
+				logger.Duration("duration", time.Since(start)),
+			}
+			if logBodies {
+				fields = append(fields,
+					logger.String("request_body", redactBody(requestBody)),
+					logger.String("response_body", redactBody(wrapped.body)),
+				)
+			}
+			requestLog.Info("Request completed", fields...)
 		}
 		return http.HandlerFunc(fn)
 	}
 }
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
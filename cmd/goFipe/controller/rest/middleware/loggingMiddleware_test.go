@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		bodyLogging   BodyLogging
+		wantRequestID func(t *testing.T, requestID string)
+		wantFields    map[string]any
+	}{
+		{
+			name:      "Request ID from header",
+			requestID: "client-id-1",
+			wantRequestID: func(t *testing.T, requestID string) {
+				assert.Equal(t, "client-id-1", requestID)
+			},
+			wantFields: map[string]any{
+				"status":        float64(http.StatusUnauthorized),
+				"method":        "POST",
+				"path":          "/admin/api-keys",
+				"query":         "dry_run=true&token=%5BREDACTED%5D",
+				"client_ip":     "192.0.2.1",
+				"user_agent":    "curl/8.0",
+				"response_size": float64(len(`{"message":"Invalid api key","request_id":"client-id-1"}` + "\n")),
+			},
+		},
+		{
+			name:      "Invalid request ID is replaced",
+			requestID: "id with spaces\r\n",
+			wantRequestID: func(t *testing.T, requestID string) {
+				assert.Len(t, requestID, 32)
+			},
+		},
+		{
+			name:        "Generated request ID and sampled bodies",
+			bodyLogging: BodyLogging{SampleRate: 1, MaxBytes: 1024},
+			wantRequestID: func(t *testing.T, requestID string) {
+				assert.Len(t, requestID, 32)
+			},
+			wantFields: map[string]any{
+				"request_body":  `{"email":"[REDACTED]","name":"Dealer"}`,
+				"response_body": `{"message":"Invalid api key","request_id":"[ID]"}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			log, _, err := logger.New(&output, logger.LevelInfo, logger.FormatJSON)
+			assert.Nil(t, err)
+
+			var contextRequestID string
+			handler := LoggingMiddleware(log, tt.bodyLogging)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contextRequestID, _ = response.RequestIDFromContext(r.Context())
+				_, _ = io.ReadAll(r.Body)
+				logger.FromContext(r.Context(), logger.NewNop()).Info("Handled")
+				response.Error(w, r, "Invalid api key", http.StatusUnauthorized)
+			}))
+
+			req := httptest.NewRequest("POST", "/admin/api-keys?token=secret&dry_run=true",
+				strings.NewReader(`{"name":"Dealer","email":"dealer@example.com"}`))
+			req.Header.Set("User-Agent", "curl/8.0")
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			tt.wantRequestID(t, contextRequestID)
+			assert.Equal(t, contextRequestID, rr.Header().Get(RequestIDHeader))
+			assert.Contains(t, rr.Body.String(), `"request_id":"`+contextRequestID+`"`)
+
+			decoder := json.NewDecoder(&output)
+			var handled, completed map[string]any
+			assert.Nil(t, decoder.Decode(&handled))
+			assert.Nil(t, decoder.Decode(&completed))
+			assert.Equal(t, "Handled", handled["msg"])
+			assert.Equal(t, contextRequestID, handled["request_id"])
+			assert.Equal(t, "Request completed", completed["msg"])
+			assert.Equal(t, contextRequestID, completed["request_id"])
+			for field, want := range tt.wantFields {
+				if body, ok := want.(string); ok {
+					want = strings.ReplaceAll(body, "[ID]", contextRequestID)
+				}
+				assert.Equal(t, want, completed[field], field)
+			}
+			if tt.bodyLogging.SampleRate == 0 {
+				assert.NotContains(t, completed, "request_body")
+			}
+		})
+	}
+}
+
+func Test_redactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		max  int
+		want string
+	}{
+		{name: "Empty", body: "", max: 100, want: ""},
+		{
+			name: "Nested sensitive fields",
+			body: `[{"id":1,"Key":"gf_secret","owner":{"cpf":"12345678900","name":"Ana"}}]`,
+			max:  100,
+			want: `[{"Key":"[REDACTED]","id":1,"owner":{"cpf":"[REDACTED]","name":"Ana"}}]`,
+		},
+		{name: "Not JSON", body: "Invalid api key\n", max: 100, want: "[omitted: body is not JSON]"},
+		{name: "Truncated", body: `{"password":"123456"}`, max: 10, want: "[omitted: body too large]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &cappedBuffer{max: tt.max}
+			_, _ = body.Write([]byte(tt.body))
+			assert.Equal(t, tt.want, redactBody(body))
+		})
+	}
+}
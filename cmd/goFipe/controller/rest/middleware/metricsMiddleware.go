@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+)
+
+// MetricsMiddleware records the number and latency of the HTTP requests by route template, method and status.
+// It must be installed with Router.Use so the matched route is available.
+func MetricsMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r)
+
+			status := wrapped.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			labels := []string{routeTemplate(r), r.Method, strconv.Itoa(status)}
+			metrics.HttpRequests.WithLabelValues(labels...).Inc()
+			metrics.HttpRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// routeTemplate returns the path template of the matched route, so paths with variables share a label.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+	return template
+}
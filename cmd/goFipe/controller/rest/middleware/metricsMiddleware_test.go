@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(MetricsMiddleware())
+	router.HandleFunc("/admin/api-keys/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+	router.HandleFunc("/vehicles", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	}).Methods("GET")
+
+	deleted := metrics.HttpRequests.WithLabelValues("/admin/api-keys/{id}", "DELETE", "204")
+	listed := metrics.HttpRequests.WithLabelValues("/vehicles", "GET", "200")
+	deletedBefore := testutil.ToFloat64(deleted)
+	listedBefore := testutil.ToFloat64(listed)
+
+	for _, path := range []string{"/admin/api-keys/1", "/admin/api-keys/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", path, nil))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/vehicles", nil))
+
+	assert.Equal(t, deletedBefore+2, testutil.ToFloat64(deleted), "paths must be grouped by route template")
+	assert.Equal(t, listedBefore+1, testutil.ToFloat64(listed), "implicit 200 must be recorded")
+}
@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+// RateLimit configures a token bucket that refills Rate tokens per second up to Burst tokens.
+// A RateLimit with a non-positive Rate or Burst disables limiting.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// RateLimitResult is the state of a bucket after a Take.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// RateLimitBackend stores the token buckets. The in-memory implementation only limits a single replica;
+// a backend shared by all replicas (e.g. Redis) makes the limits hold across the whole deployment.
+type RateLimitBackend interface {
+	Take(key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// RateLimitKeyFunc returns the bucket key of the request, or false if the request must not be limited.
+type RateLimitKeyFunc func(r *http.Request) (string, bool)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     RateLimit
+}
+
+// InMemoryRateLimitBackend keeps the token buckets in the process memory.
+type InMemoryRateLimitBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+const rateLimitSweepInterval = time.Minute
+
+func NewInMemoryRateLimitBackend() *InMemoryRateLimitBackend {
+	return &InMemoryRateLimitBackend{
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+// Take refills the bucket of the key and consumes one token if available.
+func (b *InMemoryRateLimitBackend) Take(key string, limit RateLimit) (RateLimitResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), updatedAt: now}
+		b.buckets[key] = bucket
+	}
+	bucket.limit = limit
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.Rate)
+	bucket.updatedAt = now
+
+	result := RateLimitResult{Limit: limit.Burst}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / limit.Rate)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.ResetAfter = secondsToDuration((float64(limit.Burst) - bucket.tokens) / limit.Rate)
+	return result, nil
+}
+
+// sweep drops the buckets that are already full again, so idle clients do not hold memory forever.
+func (b *InMemoryRateLimitBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	b.lastSweep = now
+	for key, bucket := range b.buckets {
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*bucket.limit.Rate >= float64(bucket.limit.Burst) {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// ClientIPKey limits requests by the client IP address.
+func ClientIPKey(r *http.Request) (string, bool) {
+	host := clientIP(r)
+	return "ip:" + host, host != ""
+}
+
+// clientIP returns the IP address of the connection the request was received on.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ApiKeyKey limits requests by the API key authenticated by AuthMiddleware.
+func ApiKeyKey(r *http.Request) (string, bool) {
+	apiKey, ok := ApiKeyFromContext(r.Context())
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("key:%d", apiKey.ID), true
+}
+
+// RateLimitMiddleware rejects requests with 429 Too Many Requests once the bucket selected by keyFunc is empty.
+// Every limited response carries the X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers,
+// and rejected responses also carry Retry-After. If the backend fails the request is let through.
+func RateLimitMiddleware(
+	backend RateLimitBackend,
+	limit RateLimit,
+	keyFunc RateLimitKeyFunc,
+	log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limit.enabled() {
+			return next
+		}
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			key, ok := keyFunc(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := backend.Take(key, limit)
+			if err != nil {
+				logger.FromContext(r.Context(), log).Error("Rate limit backend error", logger.Err(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				response.Error(w, r, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBackend() (*InMemoryRateLimitBackend, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)}
+	backend := NewInMemoryRateLimitBackend()
+	backend.now = clock.Now
+	return backend, clock
+}
+
+func TestInMemoryRateLimitBackend_Take(t *testing.T) {
+	backend, clock := newTestBackend()
+	limit := RateLimit{Rate: 1, Burst: 2}
+
+	first, _ := backend.Take("ip:1", limit)
+	assert.Equal(t, RateLimitResult{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Second}, first)
+
+	second, _ := backend.Take("ip:1", limit)
+	assert.Equal(t, RateLimitResult{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 2 * time.Second}, second)
+
+	third, _ := backend.Take("ip:1", limit)
+	assert.Equal(t, RateLimitResult{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: time.Second, ResetAfter: 2 * time.Second}, third)
+
+	other, _ := backend.Take("ip:2", limit)
+	assert.True(t, other.Allowed, "buckets must be independent per key")
+
+	clock.now = clock.now.Add(time.Second)
+	refilled, _ := backend.Take("ip:1", limit)
+	assert.True(t, refilled.Allowed)
+}
+
+func TestInMemoryRateLimitBackend_sweep(t *testing.T) {
+	backend, clock := newTestBackend()
+	limit := RateLimit{Rate: 1, Burst: 2}
+
+	_, _ = backend.Take("ip:1", limit)
+	assert.Len(t, backend.buckets, 1)
+
+	clock.now = clock.now.Add(rateLimitSweepInterval)
+	_, _ = backend.Take("ip:2", limit)
+	assert.Len(t, backend.buckets, 1)
+	assert.Contains(t, backend.buckets, "ip:2")
+}
+
+type failingBackend struct{}
+
+func (failingBackend) Take(string, RateLimit) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("backend unavailable")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	withApiKey := func(r *http.Request) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, domain.ApiKey{ID: 7}))
+	}
+
+	tests := []struct {
+		name           string
+		backend        RateLimitBackend
+		limit          RateLimit
+		keyFunc        RateLimitKeyFunc
+		prepare        func(r *http.Request) *http.Request
+		requests       int
+		wantStatusCode int
+		wantHeaders    map[string]string
+	}{
+		{
+			name:           "Within limit",
+			limit:          RateLimit{Rate: 1, Burst: 2},
+			keyFunc:        ClientIPKey,
+			requests:       2,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"X-RateLimit-Limit": "2", "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "2"},
+		},
+		{
+			name:           "Ip limit exceeded",
+			limit:          RateLimit{Rate: 0.5, Burst: 1},
+			keyFunc:        ClientIPKey,
+			requests:       2,
+			wantStatusCode: http.StatusTooManyRequests,
+			wantHeaders:    map[string]string{"Retry-After": "2", "X-RateLimit-Remaining": "0"},
+		},
+		{
+			name:           "Api key limit exceeded",
+			limit:          RateLimit{Rate: 1, Burst: 1},
+			keyFunc:        ApiKeyKey,
+			prepare:        withApiKey,
+			requests:       2,
+			wantStatusCode: http.StatusTooManyRequests,
+			wantHeaders:    map[string]string{"Retry-After": "1"},
+		},
+		{
+			name:           "Request without api key is not limited by key",
+			limit:          RateLimit{Rate: 1, Burst: 1},
+			keyFunc:        ApiKeyKey,
+			requests:       3,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"X-RateLimit-Limit": ""},
+		},
+		{
+			name:           "Disabled limit",
+			limit:          RateLimit{},
+			keyFunc:        ClientIPKey,
+			requests:       3,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"X-RateLimit-Limit": ""},
+		},
+		{
+			name:           "Backend failure lets request through",
+			backend:        failingBackend{},
+			limit:          RateLimit{Rate: 1, Burst: 1},
+			keyFunc:        ClientIPKey,
+			requests:       3,
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"X-RateLimit-Limit": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := tt.backend
+			if backend == nil {
+				backend, _ = newTestBackend()
+			}
+			handler := RateLimitMiddleware(backend, tt.limit, tt.keyFunc, logger.NewNop())(okHandler)
+
+			var rr *httptest.ResponseRecorder
+			for i := 0; i < tt.requests; i++ {
+				req := httptest.NewRequest("GET", "/vehicles", nil)
+				if tt.prepare != nil {
+					req = tt.prepare(req)
+				}
+				rr = httptest.NewRecorder()
+				handler.ServeHTTP(rr, req)
+			}
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			for header, value := range tt.wantHeaders {
+				assert.Equal(t, value, rr.Header().Get(header), header)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+)
+
+// PanicReporter forwards a recovered panic to an error-reporting service. ctx is the request context.
+type PanicReporter func(ctx context.Context, value any, stack []byte)
+
+// RecoveryMiddleware recovers the panics of the next handlers. It logs the panic value and the stack,
+// counts the panic by route, forwards it to reporter, if any, and answers 500 with a problem details
+// body unless the response was already started. http.ErrAbortHandler is propagated, as it is meant to
+// abort the response silently. It must be installed with Router.Use so the matched route is available.
+func RecoveryMiddleware(log logger.Logger, reporter PanicReporter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			wrapped := wrapResponseWriter(w)
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+
+				stack := debug.Stack()
+				route := routeTemplate(r)
+				metrics.Panics.WithLabelValues(route).Inc()
+				logger.FromContext(r.Context(), log).Error("Panic occurred",
+					logger.String("panic", fmt.Sprint(value)),
+					logger.String("stack", string(stack)),
+					logger.String("route", route),
+					logger.Bool("response_started", wrapped.wroteHeader),
+				)
+				if reporter != nil {
+					reporter(r.Context(), value, stack)
+				}
+
+				if !wrapped.wroteHeader {
+					response.Problem(wrapped, r, http.StatusInternalServerError, "Erro interno do servidor")
+				}
+			}()
+			next.ServeHTTP(wrapped, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/response"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		wantStatusCode  int
+		wantBody        string
+		wantContentType string
+		wantStarted     bool
+	}{
+		{
+			name: "Panic before writing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				panic("nil map")
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantBody: `{"type":"about:blank","title":"Internal Server Error","status":500,` +
+				`"detail":"Erro interno do servidor","instance":"/vehicles","request_id":"abc"}` + "\n",
+			wantContentType: "application/problem+json",
+		},
+		{
+			name: "Panic after writing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte("[{"))
+				panic("encoder failed")
+			},
+			wantStatusCode:  http.StatusOK,
+			wantBody:        "[{",
+			wantContentType: "application/json",
+			wantStarted:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			log, _, err := logger.New(&output, logger.LevelInfo, logger.FormatJSON)
+			assert.Nil(t, err)
+			var reported any
+			reporter := func(_ context.Context, value any, stack []byte) {
+				reported = value
+				assert.NotEmpty(t, stack)
+			}
+
+			router := mux.NewRouter()
+			router.Use(RecoveryMiddleware(log, reporter))
+			router.HandleFunc("/vehicles", tt.handler).Methods("GET")
+			panics := metrics.Panics.WithLabelValues("/vehicles")
+			panicsBefore := testutil.ToFloat64(panics)
+
+			req := httptest.NewRequest("GET", "/vehicles", nil)
+			req = req.WithContext(response.WithRequestID(req.Context(), "abc"))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatusCode, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			assert.Equal(t, tt.wantContentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, panicsBefore+1, testutil.ToFloat64(panics))
+			assert.NotNil(t, reported)
+
+			var entry map[string]any
+			assert.Nil(t, json.Unmarshal(output.Bytes(), &entry))
+			assert.Equal(t, "Panic occurred", entry["msg"])
+			assert.Equal(t, reported, entry["panic"])
+			assert.Contains(t, entry["stack"], "runtime/debug.Stack")
+			assert.Equal(t, tt.wantStarted, entry["response_started"])
+		})
+	}
+}
+
+func TestRecoveryMiddleware_AbortHandler(t *testing.T) {
+	handler := RecoveryMiddleware(logger.NewNop(), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/vehicles", nil))
+	})
+}
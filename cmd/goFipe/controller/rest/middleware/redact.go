@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are the query parameters and JSON fields, compared case-insensitively, whose values
+// are never logged: credentials and personal data.
+var sensitiveFields = map[string]bool{
+	"key":           true,
+	"api_key":       true,
+	"apikey":        true,
+	"token":         true,
+	"admin_token":   true,
+	"access_token":  true,
+	"password":      true,
+	"secret":        true,
+	"authorization": true,
+	"email":         true,
+	"cpf":           true,
+	"cnpj":          true,
+	"phone":         true,
+	"telefone":      true,
+}
+
+func isSensitive(field string) bool {
+	return sensitiveFields[strings.ToLower(field)]
+}
+
+// redactQuery encodes the query with the values of the sensitive parameters replaced.
+func redactQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	for _, key := range keys {
+		for _, value := range query[key] {
+			if builder.Len() > 0 {
+				builder.WriteByte('&')
+			}
+			if isSensitive(key) {
+				value = redacted
+			}
+			builder.WriteString(url.QueryEscape(key) + "=" + url.QueryEscape(value))
+		}
+	}
+	return builder.String()
+}
+
+// redactBody returns the JSON body with the values of the sensitive fields replaced. Bodies that are
+// truncated or are not JSON cannot be redacted reliably and are omitted.
+func redactBody(body *cappedBuffer) string {
+	if body.Len() == 0 {
+		return ""
+	}
+	if body.truncated {
+		return "[omitted: body too large]"
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body.Bytes()))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil || decoder.More() {
+		return "[omitted: body is not JSON]"
+	}
+	encoded, err := json.Marshal(redactValue(document))
+	if err != nil {
+		return "[omitted: body is not JSON]"
+	}
+	return string(encoded)
+}
+
+func redactValue(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for field, fieldValue := range typed {
+			if isSensitive(field) {
+				typed[field] = redacted
+				continue
+			}
+			typed[field] = redactValue(fieldValue)
+		}
+	case []any:
+		for index, item := range typed {
+			typed[index] = redactValue(item)
+		}
+	}
+	return value
+}
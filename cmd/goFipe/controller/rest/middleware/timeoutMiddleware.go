@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TimeoutMiddleware bounds the request context with a deadline, so database queries of slow or abandoned
+// requests are cancelled instead of piling up. routeTimeouts overrides defaultTimeout by route template and
+// a timeout of 0 disables the deadline. It must be installed with Router.Use so the matched route is available.
+func TimeoutMiddleware(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			timeout, ok := routeTimeouts[routeTemplate(r)]
+			if !ok {
+				timeout = defaultTimeout
+			}
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
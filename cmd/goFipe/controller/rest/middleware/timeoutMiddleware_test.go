@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		routeTimeouts map[string]time.Duration
+		wantDeadline  bool
+		wantTimeout   time.Duration
+	}{
+		{
+			name:         "Default timeout",
+			path:         "/vehicles",
+			wantDeadline: true,
+			wantTimeout:  time.Second,
+		},
+		{
+			name:          "Route timeout",
+			path:          "/admin/api-keys/1",
+			routeTimeouts: map[string]time.Duration{"/admin/api-keys/{id}": time.Minute},
+			wantDeadline:  true,
+			wantTimeout:   time.Minute,
+		},
+		{
+			name:          "Disabled route timeout",
+			path:          "/vehicles",
+			routeTimeouts: map[string]time.Duration{"/vehicles": 0},
+			wantDeadline:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline time.Time
+			var hasDeadline bool
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+			}
+
+			router := mux.NewRouter()
+			router.Use(TimeoutMiddleware(time.Second, tt.routeTimeouts))
+			router.HandleFunc("/vehicles", handler).Methods("GET")
+			router.HandleFunc("/admin/api-keys/{id}", handler).Methods("GET")
+
+			start := time.Now()
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.path, nil))
+
+			assert.Equal(t, tt.wantDeadline, hasDeadline)
+			if tt.wantDeadline {
+				assert.WithinDuration(t, start.Add(tt.wantTimeout), deadline, 100*time.Millisecond)
+			}
+		})
+	}
+}
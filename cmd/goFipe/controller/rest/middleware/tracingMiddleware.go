@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/raffops/gofipe/cmd/goFipe/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// TracingMiddleware starts a server span for each request, continuing the trace received in the
+// W3C traceparent/tracestate headers. It must be installed with Router.Use so the matched route is available.
+func TracingMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			route := routeTemplate(r)
+			ctx, span := tracing.StartServer(ctx, r.Method+" "+route,
+				semconv.HTTPMethod(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(r.URL.Path),
+			)
+			defer span.End()
+
+			wrapped := wrapResponseWriter(w)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			status := wrapped.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(semconv.HTTPStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
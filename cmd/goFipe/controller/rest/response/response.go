@@ -0,0 +1,62 @@
+// Package response writes the error bodies shared by the handlers and the middlewares. Every error body
+// carries the ID of the request, so a client reporting an error can be matched with the server logs.
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/raffops/gofipe/cmd/goFipe/controller/rest/dto"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+)
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDContextKey{}).(string)
+	return requestID, ok
+}
+
+// Error replies to the request with the message and the request ID in a JSON body. Like http.Error,
+// it does not end the request; the caller must not write to w afterwards.
+func Error(w http.ResponseWriter, r *http.Request, message string, code int) {
+	requestID, _ := RequestIDFromContext(r.Context())
+	body, _ := json.Marshal(dto.ErrorResponse{Message: message, RequestID: requestID})
+
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// AppError replies to the request with the message and the status code of err.
+func AppError(w http.ResponseWriter, r *http.Request, err *errs.AppError) {
+	Error(w, r, err.Message, err.Code)
+}
+
+// Problem replies to the request with an RFC 9457 problem details body.
+func Problem(w http.ResponseWriter, r *http.Request, code int, detail string) {
+	requestID, _ := RequestIDFromContext(r.Context())
+	body, _ := json.Marshal(dto.ProblemResponse{
+		Type:      "about:blank",
+		Title:     http.StatusText(code),
+		Status:    code,
+		Detail:    detail,
+		Instance:  r.URL.EscapedPath(),
+		RequestID: requestID,
+	})
+
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(body, '\n'))
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		wantBody  string
+	}{
+		{
+			name:      "With request ID",
+			requestID: "abc",
+			wantBody:  `{"message":"Invalid api key","request_id":"abc"}` + "\n",
+		},
+		{
+			name:     "Without request ID",
+			wantBody: `{"message":"Invalid api key"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/vehicles", nil)
+			if tt.requestID != "" {
+				req = req.WithContext(WithRequestID(req.Context(), tt.requestID))
+			}
+			rr := httptest.NewRecorder()
+			rr.Header().Set("Content-Length", "10")
+
+			AppError(rr, req, errs.NewUnauthorizedError("Invalid api key"))
+
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+			assert.Equal(t, tt.wantBody, rr.Body.String())
+			assert.Equal(t, "application/json; charset=utf-8", rr.Header().Get("Content-Type"))
+			assert.Empty(t, rr.Header().Get("Content-Length"))
+		})
+	}
+}
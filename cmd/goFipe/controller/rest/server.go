@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+func newServer(handler http.Handler, config config.ServerConfig) *http.Server {
+	return &http.Server{
+		Addr:              config.Addr(),
+		Handler:           handler,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+}
+
+// serve accepts connections on the listener until ctx is done. It then stops accepting new connections
+// and waits up to shutdownTimeout for the in-flight requests to finish before closing the remaining ones.
+func serve(
+	ctx context.Context,
+	server *http.Server,
+	listener net.Listener,
+	shutdownTimeout time.Duration,
+	log logger.Logger) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serving http: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down server", logger.Duration("timeout", shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		_ = server.Close()
+		return fmt.Errorf("draining in-flight requests: %w", err)
+	}
+
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("serving http: %w", err)
+	}
+	log.Info("Server stopped")
+	return nil
+}
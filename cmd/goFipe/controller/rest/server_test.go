@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serve(t *testing.T) {
+	tests := []struct {
+		name            string
+		handlerDelay    time.Duration
+		shutdownTimeout time.Duration
+		wantErr         bool
+		wantStatusCode  int
+	}{
+		{
+			name:            "Drains in-flight request",
+			handlerDelay:    100 * time.Millisecond,
+			shutdownTimeout: 5 * time.Second,
+			wantErr:         false,
+			wantStatusCode:  http.StatusOK,
+		},
+		{
+			name:            "Shutdown deadline exceeded",
+			handlerDelay:    5 * time.Second,
+			shutdownTimeout: 50 * time.Millisecond,
+			wantErr:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.handlerDelay):
+					_, _ = w.Write([]byte("Ok"))
+				case <-r.Context().Done():
+				}
+			})
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.Nil(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- serve(ctx, newServer(handler, config.ServerConfig{}), listener, tt.shutdownTimeout, logger.NewNop())
+			}()
+
+			type response struct {
+				statusCode int
+				err        error
+			}
+			responses := make(chan response, 1)
+			go func() {
+				resp, err := http.Get("http://" + listener.Addr().String())
+				if err != nil {
+					responses <- response{err: err}
+					return
+				}
+				defer resp.Body.Close()
+				_, _ = io.ReadAll(resp.Body)
+				responses <- response{statusCode: resp.StatusCode}
+			}()
+
+			<-started
+			cancel()
+
+			err = <-serveErr
+			assert.Equal(t, tt.wantErr, err != nil)
+			got := <-responses
+			if tt.wantStatusCode != 0 {
+				assert.Nil(t, got.err)
+				assert.Equal(t, tt.wantStatusCode, got.statusCode)
+			}
+
+			_, err = http.Get("http://" + listener.Addr().String())
+			assert.NotNil(t, err, "new connections must be refused after shutdown")
+		})
+	}
+}
@@ -1,58 +1,147 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
 	postgresDb "gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
-// sanityCheck checks if the environment variables are set.
-func sanityCheck() {
-	if _, ok := os.LookupEnv("POSTGRES_HOST"); !ok {
-		panic("POSTGRES_HOST environment variable is not set")
+// replicatedTables are read from the replica when one is configured. The api key tables always use the
+// primary, so a revoked key or an exhausted quota is seen immediately. The ingestions are read from the
+// replica like the vehicles, so a cache never sees an ingestion before its vehicles.
+var replicatedTables = []interface{}{"vehicles", "vehicle_ingestions"}
+
+// GetPostgresConnection returns a PostgreSQL database connection using the given configuration.
+// The connection is retried with exponential backoff, so the application survives starting before
+// the database. It gives up after config.ConnectAttempts attempts or when ctx is done.
+func GetPostgresConnection(ctx context.Context, config config.PostgresConfig, log logger.Logger) (*gorm.DB, error) {
+	var conn *gorm.DB
+	err := retry(ctx, log, config.ConnectAttempts, config.ConnectBackoff, config.ConnectMaxBackoff, func() error {
+		var err error
+		conn, err = connect(config)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connecting to postgres at %s:%d: %w", config.Host, config.Port, err)
 	}
+	return conn, nil
+}
 
-	if _, ok := os.LookupEnv("POSTGRES_USER"); !ok {
-		panic("POSTGRES_USER environment variable is not set")
+func connect(config config.PostgresConfig) (*gorm.DB, error) {
+	DB, err := gorm.Open(
+		postgresDb.Open(dsn(config, config.Host, config.Port)),
+		&gorm.Config{},
+	)
+	if err != nil {
+		if DB != nil {
+			if sqlDB, errDB := DB.DB(); errDB == nil {
+				_ = sqlDB.Close()
+			}
+		}
+		return nil, err
 	}
 
-	if _, ok := os.LookupEnv("POSTGRES_PASSWORD"); !ok {
-		panic("POSTGRES_PASSWORD environment variable is not set")
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	if _, ok := os.LookupEnv("POSTGRES_DB"); !ok {
-		panic("POSTGRES_DB environment variable is not set")
+	if config.ReplicaHost != "" {
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: []gorm.Dialector{postgresDb.Open(dsn(config, config.ReplicaHost, config.ReplicaPort))},
+		}, replicatedTables...).
+			SetMaxOpenConns(config.MaxOpenConns).
+			SetMaxIdleConns(config.MaxIdleConns).
+			SetConnMaxLifetime(config.ConnMaxLifetime).
+			SetConnMaxIdleTime(config.ConnMaxIdleTime)
+		if err := DB.Use(resolver); err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("connecting to replica at %s:%d: %w", config.ReplicaHost, config.ReplicaPort, err)
+		}
 	}
+	return DB, nil
 }
 
-// GetPostgresConnection returns a PostgreSQL database connection using the environment variables:
-// POSTGRES_HOST, POSTGRES_USER, POSTGRES_PASSWORD and POSTGRES_DB.
-func GetPostgresConnection() *gorm.DB {
-	sanityCheck()
-	host := os.Getenv("POSTGRES_HOST")
-	user := os.Getenv("POSTGRES_USER")
-	password := os.Getenv("POSTGRES_PASSWORD")
-	database := os.Getenv("POSTGRES_DB")
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=5432 sslmode=disable TimeZone=UTC",
-		host,
-		user,
-		password,
-		database,
-	)
-	DB, err := gorm.Open(
-		postgresDb.Open(dsn),
-		&gorm.Config{},
-	)
-	if err != nil {
-		panic("Unable to connect to database")
+// dsn builds the connection string of the given host. Values are quoted, so passwords may contain spaces.
+func dsn(config config.PostgresConfig, host string, port int) string {
+	parameters := []string{
+		"host=" + quote(host),
+		fmt.Sprintf("port=%d", port),
+		"user=" + quote(config.User),
+		"password=" + quote(config.Password),
+		"dbname=" + quote(config.Database),
+		"sslmode=" + quote(config.SSLMode),
+	}
+	if config.SSLRootCert != "" {
+		parameters = append(parameters, "sslrootcert="+quote(config.SSLRootCert))
+	}
+	if config.SSLCert != "" {
+		parameters = append(parameters, "sslcert="+quote(config.SSLCert), "sslkey="+quote(config.SSLKey))
+	}
+	parameters = append(parameters, "TimeZone=UTC")
+	return strings.Join(parameters, " ")
+}
+
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// retry calls fn up to attempts times, waiting backoff after the first failure and doubling the wait
+// up to maxBackoff after each further failure. It returns the last error.
+func retry(ctx context.Context, log logger.Logger, attempts int, backoff time.Duration, maxBackoff time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		log.Info("Database not available, retrying",
+			logger.Int("attempt", attempt),
+			logger.Duration("backoff", backoff),
+			logger.Err(err),
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
 	}
-	return DB
 }
 
-func ClosePostgresConnection(conn *gorm.DB) {
-	sqlDB, _ := conn.DB()
-	_ = sqlDB.Close()
+// ClosePostgresConnection closes the connection pools of the primary and of the replica, if any,
+// waiting for the queries in progress to finish.
+func ClosePostgresConnection(conn *gorm.DB) error {
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return err
+	}
+
+	if resolver, ok := conn.Config.Plugins[(&dbresolver.DBResolver{}).Name()].(*dbresolver.DBResolver); ok {
+		err = resolver.Call(func(connPool gorm.ConnPool) error {
+			if replicaDB, ok := connPool.(*sql.DB); ok && replicaDB != sqlDB {
+				return replicaDB.Close()
+			}
+			return nil
+		})
+	}
+	return errors.Join(err, sqlDB.Close())
 }
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dsn(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.PostgresConfig
+		host   string
+		port   int
+		want   string
+	}{
+		{
+			name:   "Without TLS",
+			config: config.PostgresConfig{User: "test", Password: "test", Database: "test", SSLMode: "disable"},
+			host:   "localhost",
+			port:   5432,
+			want: "host='localhost' port=5432 user='test' password='test' dbname='test' sslmode='disable' " +
+				"TimeZone=UTC",
+		},
+		{
+			name: "With TLS client certificate and quoted password",
+			config: config.PostgresConfig{
+				User:        "gofipe",
+				Password:    `it's a \secret`,
+				Database:    "fipe",
+				SSLMode:     "verify-full",
+				SSLRootCert: "/certs/ca.pem",
+				SSLCert:     "/certs/client.pem",
+				SSLKey:      "/certs/client.key",
+			},
+			host: "replica",
+			port: 6432,
+			want: `host='replica' port=6432 user='gofipe' password='it\'s a \\secret' dbname='fipe' ` +
+				`sslmode='verify-full' sslrootcert='/certs/ca.pem' sslcert='/certs/client.pem' ` +
+				`sslkey='/certs/client.key' TimeZone=UTC`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dsn(tt.config, tt.host, tt.port))
+		})
+	}
+}
+
+func Test_retry(t *testing.T) {
+	errUnavailable := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int
+		cancel    bool
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "Succeeds after failures", attempts: 5, failures: 2, wantCalls: 3, wantErr: nil},
+		{name: "Gives up after attempts", attempts: 3, failures: 10, wantCalls: 3, wantErr: errUnavailable},
+		{name: "Stops when canceled", attempts: 5, failures: 10, cancel: true, wantCalls: 1, wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			calls := 0
+			err := retry(ctx, logger.NewNop(), tt.attempts, time.Millisecond, 2*time.Millisecond, func() error {
+				calls++
+				if calls <= tt.failures {
+					return errUnavailable
+				}
+				return nil
+			})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	// ApiKeyPrefix is prepended to every generated API key so leaked keys are easy to recognize.
+	ApiKeyPrefix = "gf_"
+	// apiKeyIdentifierLength is the number of characters, after ApiKeyPrefix, stored in clear text
+	// to identify a key without exposing it.
+	apiKeyIdentifierLength = 8
+	apiKeySecretBytes      = 32
+)
+
+type ApiKey struct {
+	ID           uint
+	Name         string
+	Prefix       string
+	Hash         string
+	MonthlyQuota int
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID uint
+	Year     int
+	Month    int
+	Requests int
+}
+
+// IsRevoked returns true if the API key was revoked.
+func (k ApiKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// GenerateApiKey returns a new random API key in the format gf_<hex secret>.
+func GenerateApiKey() (string, error) {
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return ApiKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+// HashApiKey returns the hex encoded SHA-256 hash of the raw API key. Only the hash is stored at rest.
+func HashApiKey(rawKey string) string {
+	hash := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(hash[:])
+}
+
+// GetApiKeyIdentifier returns the clear text part of the API key used to identify it in listings and logs.
+func GetApiKeyIdentifier(rawKey string) string {
+	identifier := strings.TrimPrefix(rawKey, ApiKeyPrefix)
+	if len(identifier) > apiKeyIdentifierLength {
+		identifier = identifier[:apiKeyIdentifierLength]
+	}
+	return ApiKeyPrefix + identifier
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateApiKey(t *testing.T) {
+	first, err := GenerateApiKey()
+	assert.Nil(t, err)
+	second, err := GenerateApiKey()
+	assert.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(first, ApiKeyPrefix))
+	assert.Len(t, first, len(ApiKeyPrefix)+2*apiKeySecretBytes)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashApiKey(t *testing.T) {
+	hash := HashApiKey("gf_secret")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, HashApiKey("gf_secret"))
+	assert.NotEqual(t, hash, HashApiKey("gf_other"))
+}
+
+func TestGetApiKeyIdentifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		rawKey string
+		want   string
+	}{
+		{name: "Generated key", rawKey: "gf_0123456789abcdef", want: "gf_01234567"},
+		{name: "Short key", rawKey: "gf_0123", want: "gf_0123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetApiKeyIdentifier(tt.rawKey))
+		})
+	}
+}
+
+func TestApiKey_IsRevoked(t *testing.T) {
+	revokedAt := time.Now()
+	assert.False(t, ApiKey{}.IsRevoked())
+	assert.True(t, ApiKey{RevokedAt: &revokedAt}.IsRevoked())
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAutocompletePrefixLength is how many characters the prefix of an Autocomplete may have.
+const MaxAutocompletePrefixLength = 50
+
+// AutocompleteFields are the fields an Autocomplete suggests values of, in the order a form picks them:
+// the suggestions of a field may be narrowed by the values picked for the fields before it.
+var AutocompleteFields = []VehicleField{VehicleFieldBrand, VehicleFieldModel, VehicleFieldYearModel}
+
+// Autocomplete suggests the distinct values of Field starting with Prefix, ignoring case and accents, of
+// the vehicles of Brand and Model if they are set. It is only built by NewAutocomplete, so an
+// Autocomplete is always valid.
+type Autocomplete struct {
+	field  VehicleField
+	prefix string
+	brand  string
+	model  string
+	limit  int
+}
+
+// NewAutocomplete suggests at most limit values of field starting with prefix. brand narrows the values
+// of model and year_model, model the values of year_model.
+func NewAutocomplete(field string, prefix string, brand string, model string, limit int) (Autocomplete, *QueryError) {
+	index := slices.Index(AutocompleteFields, VehicleField(field))
+	switch {
+	case index < 0:
+		return Autocomplete{}, &QueryError{
+			Reason:  "invalid_autocomplete_field",
+			Message: "Field must be one of brand, model or year_model",
+		}
+	case utf8.RuneCountInString(prefix) > MaxAutocompletePrefixLength:
+		return Autocomplete{}, &QueryError{
+			Reason:  "invalid_prefix",
+			Message: fmt.Sprintf("Prefix must have at most %d characters", MaxAutocompletePrefixLength),
+		}
+	case brand != "" && index < slices.Index(AutocompleteFields, VehicleFieldModel):
+		return Autocomplete{}, &QueryError{
+			Reason:  "invalid_autocomplete_filter",
+			Message: fmt.Sprintf("Brand does not narrow %s", field),
+		}
+	case model != "" && index < slices.Index(AutocompleteFields, VehicleFieldYearModel):
+		return Autocomplete{}, &QueryError{
+			Reason:  "invalid_autocomplete_filter",
+			Message: fmt.Sprintf("Model does not narrow %s", field),
+		}
+	}
+	if _, err := NewPagination(0, limit); err != nil {
+		return Autocomplete{}, err
+	}
+	return Autocomplete{
+		field:  VehicleField(field),
+		prefix: FoldText(strings.TrimSpace(prefix)),
+		brand:  brand,
+		model:  model,
+		limit:  limit,
+	}, nil
+}
+
+func (a Autocomplete) Field() VehicleField {
+	return a.field
+}
+
+// Prefix returns the prefix folded by FoldText, empty to suggest any value.
+func (a Autocomplete) Prefix() string {
+	return a.prefix
+}
+
+// Brand returns the brand the suggested values must be of, empty for any brand.
+func (a Autocomplete) Brand() string {
+	return a.brand
+}
+
+// Model returns the model the suggested values must be of, empty for any model.
+func (a Autocomplete) Model() string {
+	return a.model
+}
+
+func (a Autocomplete) Limit() int {
+	return a.limit
+}
+
+// String formats the autocomplete, e.g. model:"gol" brand="VW - VolksWagen" model="" limit=10.
+func (a Autocomplete) String() string {
+	return fmt.Sprintf("%s:%q brand=%q model=%q limit=%d", a.field, a.prefix, a.brand, a.model, a.limit)
+}
+
+// AutocompleteValue is a suggested value with the number of fipe codes that have it.
+type AutocompleteValue struct {
+	Value string
+	Count int
+}
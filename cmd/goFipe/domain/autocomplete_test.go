@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAutocomplete(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		prefix     string
+		brand      string
+		model      string
+		limit      int
+		wantPrefix string
+		wantString string
+		wantErr    *QueryError
+	}{
+		{
+			name:       "prefix is folded",
+			field:      "brand",
+			prefix:     " Citroë ",
+			limit:      10,
+			wantPrefix: "citroe",
+			wantString: `brand:"citroe" brand="" model="" limit=10`,
+		},
+		{
+			name:       "brand and model narrow year models",
+			field:      "year_model",
+			brand:      "Fiat",
+			model:      "147 C/ CL",
+			limit:      5,
+			wantString: `year_model:"" brand="Fiat" model="147 C/ CL" limit=5`,
+		},
+		{
+			name:    "invalid field",
+			field:   "mean_value",
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_autocomplete_field", Message: "Field must be one of brand, model or year_model"},
+		},
+		{
+			name:    "prefix too long",
+			field:   "model",
+			prefix:  strings.Repeat("a", MaxAutocompletePrefixLength+1),
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_prefix", Message: "Prefix must have at most 50 characters"},
+		},
+		{
+			name:    "brand does not narrow brands",
+			field:   "brand",
+			brand:   "Fiat",
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_autocomplete_filter", Message: "Brand does not narrow brand"},
+		},
+		{
+			name:    "model does not narrow models",
+			field:   "model",
+			model:   "147 C/ CL",
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_autocomplete_filter", Message: "Model does not narrow model"},
+		},
+		{
+			name:    "invalid limit",
+			field:   "brand",
+			limit:   MaxLimit + 1,
+			wantErr: &QueryError{Reason: "invalid_limit", Message: fmt.Sprintf("Limit must be between 1 and %d", MaxLimit)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAutocomplete(tt.field, tt.prefix, tt.brand, tt.model, tt.limit)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, VehicleField(tt.field), got.Field())
+			assert.Equal(t, tt.wantPrefix, got.Prefix())
+			assert.Equal(t, tt.wantString, got.String())
+		})
+	}
+}
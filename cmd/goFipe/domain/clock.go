@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// Clock tells the current time. Rules depending on the current reference month take a Clock, so tests
+// can fix it with a FixedClock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock is the Clock of the system time.
+var SystemClock Clock = systemClock{}
+
+// FixedClock is a Clock stopped at a time.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time {
+	return time.Time(c)
+}
@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Operator compares a field of Vehicle with the values of a Condition.
+type Operator string
+
+const (
+	OperatorEq      Operator = "eq"
+	OperatorNe      Operator = "ne"
+	OperatorGt      Operator = "gt"
+	OperatorGte     Operator = "gte"
+	OperatorLt      Operator = "lt"
+	OperatorLte     Operator = "lte"
+	OperatorIn      Operator = "in"
+	OperatorBetween Operator = "between"
+)
+
+const (
+	// MaxFilterDepth is how deeply the groups of a FilterNode may be nested.
+	MaxFilterDepth = 8
+	// MaxFilterConditions is how many conditions a FilterNode may have.
+	MaxFilterConditions = 50
+)
+
+var (
+	// Operators are every operator of a Condition.
+	Operators = []Operator{
+		OperatorEq, OperatorNe, OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorIn, OperatorBetween,
+	}
+	// RangeVehicleFields are the fields compared by gt, gte, lt, lte and between. The other fields are
+	// only compared by eq, ne and in.
+	RangeVehicleFields = []VehicleField{VehicleFieldYear, VehicleFieldMonth, VehicleFieldMeanValue}
+)
+
+// FilterKind tells how a FilterNode matches the vehicles.
+type FilterKind string
+
+const (
+	// FilterAnd matches the vehicles matching every child.
+	FilterAnd FilterKind = "and"
+	// FilterOr matches the vehicles matching any child.
+	FilterOr FilterKind = "or"
+	// FilterNot matches the vehicles not matching its only child.
+	FilterNot FilterKind = "not"
+	// FilterCondition matches the vehicles matching the condition.
+	FilterCondition FilterKind = "condition"
+)
+
+// Condition compares Field with Values by Operator. between takes the lower and the upper bound, in
+// takes any number of values and the other operators take one value.
+type Condition struct {
+	Field    VehicleField
+	Operator Operator
+	Values   []any
+}
+
+// FilterNode is a boolean tree of conditions, built by And, Or, Not and Compare. The values of a tree
+// built by Compare are its string arguments; VehicleQueryBuilder.Match validates the tree and types
+// the values as the values of a Filter.
+type FilterNode struct {
+	Kind      FilterKind
+	Children  []FilterNode
+	Condition Condition
+}
+
+func And(nodes ...FilterNode) FilterNode {
+	return FilterNode{Kind: FilterAnd, Children: nodes}
+}
+
+func Or(nodes ...FilterNode) FilterNode {
+	return FilterNode{Kind: FilterOr, Children: nodes}
+}
+
+func Not(node FilterNode) FilterNode {
+	return FilterNode{Kind: FilterNot, Children: []FilterNode{node}}
+}
+
+// Compare compares field with values by operator, e.g. Compare("mean_value", "between", "30000", "50000").
+func Compare(field string, operator string, values ...string) FilterNode {
+	anyValues := make([]any, 0, len(values))
+	for _, value := range values {
+		anyValues = append(anyValues, value)
+	}
+	return FilterNode{
+		Kind:      FilterCondition,
+		Condition: Condition{Field: VehicleField(field), Operator: Operator(operator), Values: anyValues},
+	}
+}
+
+// String formats the tree, e.g. and(or(brand eq "Fiat", brand eq "VW"), year eq 2021). The zero
+// FilterNode formats as an empty string.
+func (n FilterNode) String() string {
+	switch n.Kind {
+	case "":
+		return ""
+	case FilterCondition:
+		values := make([]string, 0, len(n.Condition.Values))
+		for _, value := range n.Condition.Values {
+			if text, ok := value.(string); ok {
+				values = append(values, strconv.Quote(text))
+			} else {
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		return fmt.Sprintf("%s %s %s", n.Condition.Field, n.Condition.Operator, strings.Join(values, " "))
+	}
+
+	children := make([]string, 0, len(n.Children))
+	for _, child := range n.Children {
+		children = append(children, child.String())
+	}
+	return fmt.Sprintf("%s(%s)", n.Kind, strings.Join(children, ", "))
+}
+
+// filterValidator validates a FilterNode, counting its conditions across the whole tree. Years are
+// validated against clock.
+type filterValidator struct {
+	clock      Clock
+	conditions int
+}
+
+// validate returns a copy of node whose values are typed.
+func (v *filterValidator) validate(node FilterNode, depth int) (FilterNode, *QueryError) {
+	if depth > MaxFilterDepth {
+		return FilterNode{}, &QueryError{
+			Reason:  "filter_too_deep",
+			Message: fmt.Sprintf("Filter must be at most %d levels deep", MaxFilterDepth),
+		}
+	}
+
+	switch node.Kind {
+	case FilterCondition:
+		v.conditions++
+		if v.conditions > MaxFilterConditions {
+			return FilterNode{}, &QueryError{
+				Reason:  "too_many_filter_conditions",
+				Message: fmt.Sprintf("Filter must have at most %d conditions", MaxFilterConditions),
+			}
+		}
+		condition, err := validateCondition(v.clock, node.Condition)
+		if err != nil {
+			return FilterNode{}, err
+		}
+		return FilterNode{Kind: FilterCondition, Condition: condition}, nil
+	case FilterAnd, FilterOr, FilterNot:
+		if len(node.Children) == 0 || (node.Kind == FilterNot && len(node.Children) != 1) {
+			return FilterNode{}, &QueryError{
+				Reason:  "invalid_filter_group",
+				Message: fmt.Sprintf("Filter group %s must have %s", node.Kind, groupArity(node.Kind)),
+			}
+		}
+		children := make([]FilterNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			validChild, err := v.validate(child, depth+1)
+			if err != nil {
+				return FilterNode{}, err
+			}
+			children = append(children, validChild)
+		}
+		return FilterNode{Kind: node.Kind, Children: children}, nil
+	default:
+		return FilterNode{}, &QueryError{Reason: "invalid_filter_group", Message: fmt.Sprintf("Invalid filter group: %s", node.Kind)}
+	}
+}
+
+func groupArity(kind FilterKind) string {
+	if kind == FilterNot {
+		return "exactly 1 filter"
+	}
+	return "at least 1 filter"
+}
+
+func validateCondition(clock Clock, condition Condition) (Condition, *QueryError) {
+	if !slices.Contains(VehicleFields, condition.Field) {
+		return Condition{}, &QueryError{
+			Reason:  "invalid_filter_field",
+			Message: fmt.Sprintf("Invalid field: %s", condition.Field),
+		}
+	}
+	if !slices.Contains(Operators, condition.Operator) {
+		return Condition{}, &QueryError{
+			Reason:  "invalid_filter_operator",
+			Message: fmt.Sprintf("Invalid operator: %s", condition.Operator),
+		}
+	}
+	if condition.Operator != OperatorEq && condition.Operator != OperatorNe && condition.Operator != OperatorIn &&
+		!slices.Contains(RangeVehicleFields, condition.Field) {
+		return Condition{}, &QueryError{
+			Reason:  "invalid_filter_operator",
+			Message: fmt.Sprintf("Operator %s does not apply to %s", condition.Operator, condition.Field),
+		}
+	}
+
+	switch {
+	case condition.Operator == OperatorBetween && len(condition.Values) != 2:
+		return Condition{}, &QueryError{Reason: "invalid_filter_values", Message: "Operator between takes 2 values"}
+	case condition.Operator == OperatorIn && (len(condition.Values) == 0 || len(condition.Values) > MaxLimit):
+		return Condition{}, &QueryError{
+			Reason:  "invalid_filter_values",
+			Message: fmt.Sprintf("Operator in takes between 1 and %d values", MaxLimit),
+		}
+	case condition.Operator != OperatorBetween && condition.Operator != OperatorIn && len(condition.Values) != 1:
+		return Condition{}, &QueryError{
+			Reason:  "invalid_filter_values",
+			Message: fmt.Sprintf("Operator %s takes 1 value", condition.Operator),
+		}
+	}
+
+	values := make([]any, 0, len(condition.Values))
+	for _, value := range condition.Values {
+		text, ok := value.(string)
+		if !ok {
+			text = fmt.Sprint(value)
+		}
+		typedValue, err := parseFieldValue(clock, condition.Field, text)
+		if err != nil {
+			return Condition{}, err
+		}
+		values = append(values, typedValue)
+	}
+	return Condition{Field: condition.Field, Operator: condition.Operator, Values: values}, nil
+}
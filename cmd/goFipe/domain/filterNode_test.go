@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleQueryBuilder_Match(t *testing.T) {
+	tooDeep := Compare("year", "eq", "2021")
+	for i := 0; i < MaxFilterDepth; i++ {
+		tooDeep = Not(tooDeep)
+	}
+	tooMany := make([]FilterNode, 0, MaxFilterConditions+1)
+	for i := 0; i <= MaxFilterConditions; i++ {
+		tooMany = append(tooMany, Compare("year", "eq", "2021"))
+	}
+
+	tests := []struct {
+		name    string
+		node    FilterNode
+		want    FilterNode
+		wantErr *QueryError
+	}{
+		{
+			name: "typed values",
+			node: And(
+				Or(Compare("brand", "eq", "Fiat"), Compare("brand", "eq", "VW")),
+				Compare("mean_value", "between", "30000", "50000.5"),
+				Not(Compare("month", "in", "1", "2")),
+			),
+			want: FilterNode{Kind: FilterAnd, Children: []FilterNode{
+				{Kind: FilterOr, Children: []FilterNode{
+					{Kind: FilterCondition, Condition: Condition{Field: VehicleFieldBrand, Operator: OperatorEq, Values: []any{"Fiat"}}},
+					{Kind: FilterCondition, Condition: Condition{Field: VehicleFieldBrand, Operator: OperatorEq, Values: []any{"VW"}}},
+				}},
+				{Kind: FilterCondition, Condition: Condition{Field: VehicleFieldMeanValue, Operator: OperatorBetween, Values: []any{30000.0, 50000.5}}},
+				{Kind: FilterNot, Children: []FilterNode{
+					{Kind: FilterCondition, Condition: Condition{Field: VehicleFieldMonth, Operator: OperatorIn, Values: []any{1, 2}}},
+				}},
+			}},
+		},
+		{
+			name:    "invalid field",
+			node:    Compare("price", "eq", "1"),
+			wantErr: &QueryError{Reason: "invalid_filter_field", Message: "Invalid field: price"},
+		},
+		{
+			name:    "invalid operator",
+			node:    Compare("year", "like", "2021"),
+			wantErr: &QueryError{Reason: "invalid_filter_operator", Message: "Invalid operator: like"},
+		},
+		{
+			name:    "range operator on a text field",
+			node:    Compare("brand", "gt", "Fiat"),
+			wantErr: &QueryError{Reason: "invalid_filter_operator", Message: "Operator gt does not apply to brand"},
+		},
+		{
+			name:    "between takes 2 values",
+			node:    Compare("year", "between", "2020"),
+			wantErr: &QueryError{Reason: "invalid_filter_values", Message: "Operator between takes 2 values"},
+		},
+		{
+			name:    "in takes at least 1 value",
+			node:    Compare("year", "in"),
+			wantErr: &QueryError{Reason: "invalid_filter_values", Message: fmt.Sprintf("Operator in takes between 1 and %d values", MaxLimit)},
+		},
+		{
+			name:    "eq takes 1 value",
+			node:    Compare("year", "eq", "2020", "2021"),
+			wantErr: &QueryError{Reason: "invalid_filter_values", Message: "Operator eq takes 1 value"},
+		},
+		{
+			name:    "invalid value",
+			node:    Or(Compare("year", "eq", "2021"), Compare("fipe_code", "ne", "1")),
+			wantErr: &QueryError{Reason: "invalid_fipe_code", Message: "Invalid fipe code"},
+		},
+		{
+			name:    "empty text",
+			node:    Compare("model", "eq", ""),
+			wantErr: &QueryError{Reason: "invalid_model", Message: "Invalid model"},
+		},
+		{
+			name:    "empty group",
+			node:    And(),
+			wantErr: &QueryError{Reason: "invalid_filter_group", Message: "Filter group and must have at least 1 filter"},
+		},
+		{
+			name:    "not with several children",
+			node:    FilterNode{Kind: FilterNot, Children: []FilterNode{Compare("year", "eq", "2021"), Compare("month", "eq", "7")}},
+			wantErr: &QueryError{Reason: "invalid_filter_group", Message: "Filter group not must have exactly 1 filter"},
+		},
+		{
+			name:    "too deep",
+			node:    tooDeep,
+			wantErr: &QueryError{Reason: "filter_too_deep", Message: fmt.Sprintf("Filter must be at most %d levels deep", MaxFilterDepth)},
+		},
+		{
+			name:    "too many conditions",
+			node:    Or(tooMany...),
+			wantErr: &QueryError{Reason: "too_many_filter_conditions", Message: fmt.Sprintf("Filter must have at most %d conditions", MaxFilterConditions)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewVehicleQueryBuilder().Match(tt.node).Build()
+			assert.Equal(t, tt.wantErr, err)
+			got, ok := query.Match()
+			assert.Equal(t, tt.wantErr == nil, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVehicleQueryBuilder_MatchAgain(t *testing.T) {
+	query := NewVehicleQueryBuilder().
+		Match(Compare("brand", "eq", "Fiat")).
+		Match(Compare("year", "gte", "2020")).
+		MustBuild()
+
+	got, ok := query.Match()
+	assert.True(t, ok)
+	assert.Equal(t, `and(brand eq "Fiat", year gte 2020)`, got.String())
+}
+
+func TestFilterNode_String(t *testing.T) {
+	assert.Equal(t, "", FilterNode{}.String())
+	assert.Equal(t,
+		`and(or(brand eq "Fiat", brand eq "VW"), not(mean_value between "30000" "50000"))`,
+		And(
+			Or(Compare("brand", "eq", "Fiat"), Compare("brand", "eq", "VW")),
+			Not(Compare("mean_value", "between", "30000", "50000")),
+		).String(),
+	)
+}
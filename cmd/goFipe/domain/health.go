@@ -0,0 +1,29 @@
+package domain
+
+type HealthStatus string
+
+const (
+	HealthStatusUp   HealthStatus = "up"
+	HealthStatusDown HealthStatus = "down"
+)
+
+// DependencyHealth is the result of checking a single dependency of the application.
+type DependencyHealth struct {
+	Name    string
+	Status  HealthStatus
+	Message string
+}
+
+// Health aggregates the dependency checks. It is up only when every dependency is up.
+type Health struct {
+	Dependencies []DependencyHealth
+}
+
+func (h Health) Status() HealthStatus {
+	for _, dependency := range h.Dependencies {
+		if dependency.Status != HealthStatusUp {
+			return HealthStatusDown
+		}
+	}
+	return HealthStatusUp
+}
@@ -0,0 +1,284 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxListingLength is how many characters the description of a Listing may have.
+	MaxListingLength = 200
+	// MaxListingMatches is how many matches may be asked for a Listing.
+	MaxListingMatches = 20
+	// DefaultListingMatches is how many matches of a Listing are returned if not asked otherwise.
+	DefaultListingMatches = 5
+	// MaxListingBatch is how many listings a batch may have.
+	MaxListingBatch = 100
+	// MaxListingCandidates is how many catalog vehicles are scored for a Listing.
+	MaxListingCandidates = 200
+)
+
+// The weights of each part of a listing in the confidence of a match. The year and the fuel only weigh
+// if the listing has them.
+const (
+	listingBrandWeight = 0.25
+	listingModelWeight = 0.45
+	listingYearWeight  = 0.2
+	listingFuelWeight  = 0.1
+)
+
+// listingFuels maps the ways listings write a fuel to the word FIPE writes in its year models or models.
+var listingFuels = map[string]string{
+	"gas":      "gasolina",
+	"gasolina": "gasolina",
+	"alc":      "alcool",
+	"alcool":   "alcool",
+	"etanol":   "alcool",
+	"dies":     "diesel",
+	"diesel":   "diesel",
+	"flex":     "flex",
+}
+
+// Listing is a dealer's free text description of a vehicle, e.g. "FIAT 147 CL 1991 GAS", normalized into
+// the words of its brand and model, its year model and its fuel. It is only built by NewListing, so a
+// Listing always has at least one word.
+type Listing struct {
+	description string
+	words       []string
+	year        string
+	fuel        string
+}
+
+// NewListing normalizes description like a TextSearch. The last year of the description is its year model,
+// along with the year of manufacture right before it, as in 2015/2016; fuels such as gas or flex are told
+// apart from the other words. Without a 4-digit year, a 2-digit one ending the description, fuel aside, is
+// the year model, as in "FIAT 147 GLS 86 ALC", of the century told by clock.
+func NewListing(clock Clock, description string) (Listing, *QueryError) {
+	if length := utf8.RuneCountInString(strings.TrimSpace(description)); length == 0 || length > MaxListingLength {
+		return Listing{}, &QueryError{
+			Reason:  "invalid_description",
+			Message: fmt.Sprintf("Description must have between 1 and %d characters", MaxListingLength),
+		}
+	}
+
+	listing := Listing{description: strings.TrimSpace(description)}
+	terms := splitTerms(FoldText(description))
+	yearIndex, year := listingYearIndex(clock, terms)
+	for index, term := range terms {
+		switch {
+		case index == yearIndex:
+			listing.year = strconv.Itoa(year)
+		case index == yearIndex-1 && isManufactureYear(clock, term, len(terms[yearIndex]), year):
+		case listingFuels[term] != "" && listing.fuel == "":
+			listing.fuel = listingFuels[term]
+		case !slices.Contains(listing.words, term):
+			listing.words = append(listing.words, term)
+		}
+	}
+	if len(listing.words) == 0 {
+		return Listing{}, &QueryError{
+			Reason:  "invalid_description",
+			Message: "Description must have at least 1 word of the brand or the model",
+		}
+	}
+	return listing, nil
+}
+
+// listingYearIndex returns the index of the year model among terms and the year it is, or -1 if there is
+// none. A 2-digit year is only looked for in the last term that is not a fuel, since such numbers are
+// often model words, as in Renault 19 RN.
+func listingYearIndex(clock Clock, terms []string) (int, int) {
+	for index := len(terms) - 1; index >= 0; index-- {
+		if year, ok := parseListingYear(clock, terms[index], 4); ok {
+			return index, year
+		}
+	}
+	for index := len(terms) - 1; index >= 0; index-- {
+		if listingFuels[terms[index]] != "" {
+			continue
+		}
+		if year, ok := parseListingYear(clock, terms[index], 2); ok {
+			return index, year
+		}
+		break
+	}
+	return -1, 0
+}
+
+// parseListingYear parses term as a year of digits digits a vehicle may be of. A 2-digit year is of the
+// current century of clock unless that is after next year, e.g. 15 is 2015 and 86 is 1986.
+func parseListingYear(clock Clock, term string, digits int) (int, bool) {
+	year, err := strconv.Atoi(term)
+	if err != nil || len(term) != digits {
+		return 0, false
+	}
+	if digits == 2 {
+		year += 2000
+		if year > clock.Now().Year()+1 {
+			year -= 100
+		}
+	}
+	return year, year >= MinReferenceYear && year < 2100
+}
+
+// isManufactureYear reports whether term is the year of manufacture written before the year model, with
+// as many digits.
+func isManufactureYear(clock Clock, term string, digits int, year int) bool {
+	manufacture, ok := parseListingYear(clock, term, digits)
+	return ok && (year == manufacture || year == manufacture+1)
+}
+
+// Description returns the description as written, without surrounding spaces.
+func (l Listing) Description() string {
+	return l.description
+}
+
+// Words returns the folded words of the brand and the model, without the year and the fuel.
+func (l Listing) Words() []string {
+	return slices.Clone(l.words)
+}
+
+// Year returns the year model, e.g. 1991, or an empty string if the description has none.
+func (l Listing) Year() string {
+	return l.year
+}
+
+// Fuel returns the fuel as FIPE writes it folded, e.g. gasolina, or an empty string if the description
+// has none.
+func (l Listing) Fuel() string {
+	return l.fuel
+}
+
+// Text returns the normalized description, e.g. "fiat 147 cl 1991 gasolina".
+func (l Listing) Text() string {
+	parts := slices.Clone(l.words)
+	if l.year != "" {
+		parts = append(parts, l.year)
+	}
+	if l.fuel != "" {
+		parts = append(parts, l.fuel)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Confidence rates from 0 to 1 how likely vehicle is the one of the listing, weighing whether a word is
+// its brand, e.g. chev for GM - Chevrolet, how many words its model shares with the listing, its year
+// model and its fuel. A vehicle whose brand and model share no word with the listing has no confidence.
+func (l Listing) Confidence(vehicle Vehicle) float64 {
+	brandWords := splitTerms(FoldText(vehicle.Brand))
+	modelWords := splitTerms(FoldText(vehicle.Model))
+	yearModelWords := splitTerms(FoldText(vehicle.YearModel))
+
+	var brand float64
+	words := make([]string, 0, len(l.words))
+	for _, word := range l.words {
+		if match := bestWordMatch(word, brandWords); match > 0 && !slices.Contains(modelWords, word) {
+			brand = max(brand, match)
+			continue
+		}
+		words = append(words, word)
+	}
+
+	model := modelSimilarity(words, modelWords)
+	if brand == 0 && model == 0 {
+		return 0
+	}
+
+	score := listingBrandWeight*brand + listingModelWeight*model
+	weight := listingBrandWeight + listingModelWeight
+	if l.year != "" {
+		weight += listingYearWeight
+		if len(yearModelWords) > 0 && yearModelWords[0] == l.year {
+			score += listingYearWeight
+		}
+	}
+	if l.fuel != "" {
+		weight += listingFuelWeight
+		if slices.Contains(yearModelWords, l.fuel) || slices.Contains(modelWords, l.fuel) {
+			score += listingFuelWeight
+		}
+	}
+	return score / weight
+}
+
+// modelSimilarity is the Dice coefficient of the words of a listing and the words of a model, counting
+// partial matches such as aut for automatico by how well they match.
+func modelSimilarity(words []string, modelWords []string) float64 {
+	if len(words) == 0 || len(modelWords) == 0 {
+		return 0
+	}
+	var matched float64
+	for _, word := range words {
+		matched += bestWordMatch(word, modelWords)
+	}
+	for _, modelWord := range modelWords {
+		matched += bestWordMatch(modelWord, words)
+	}
+	return matched / float64(len(words)+len(modelWords))
+}
+
+// bestWordMatch rates how well word matches the best of candidates: 1 if it is one of them or of their
+// synonyms, 0.75 if one starts with the other and the shorter has at least 3 characters, 0 otherwise.
+func bestWordMatch(word string, candidates []string) float64 {
+	var best float64
+	for _, candidate := range candidates {
+		switch {
+		case word == candidate || slices.Contains(textSearchSynonyms[word], candidate):
+			return 1
+		case min(len(word), len(candidate)) >= 3 &&
+			(strings.HasPrefix(candidate, word) || strings.HasPrefix(word, candidate)):
+			best = 0.75
+		}
+	}
+	return best
+}
+
+// ListingMatch is a catalog vehicle that may be the one of a Listing, with the Confidence it is.
+type ListingMatch struct {
+	Vehicle    Vehicle
+	Confidence float64
+}
+
+// MatchListing scores the candidates against the listing and returns at most limit of them, most
+// confident first. A fipe code and year model is only matched in its latest reference month, and
+// candidates of no confidence are left out.
+func MatchListing(listing Listing, candidates []Vehicle, limit int) []ListingMatch {
+	type modelKey struct{ fipeCode, yearModel string }
+	latest := map[modelKey]Vehicle{}
+	for _, vehicle := range candidates {
+		key := modelKey{vehicle.FipeCode, vehicle.YearModel}
+		if current, ok := latest[key]; !ok || vehicle.ReferenceMonth().After(current.ReferenceMonth()) {
+			latest[key] = vehicle
+		}
+	}
+
+	matches := make([]ListingMatch, 0, len(latest))
+	for _, vehicle := range latest {
+		if confidence := listing.Confidence(vehicle); confidence > 0 {
+			matches = append(matches, ListingMatch{Vehicle: vehicle, Confidence: confidence})
+		}
+	}
+	slices.SortFunc(matches, func(a ListingMatch, b ListingMatch) int {
+		if byConfidence := cmp.Compare(b.Confidence, a.Confidence); byConfidence != 0 {
+			return byConfidence
+		}
+		if byFipeCode := cmp.Compare(a.Vehicle.FipeCode, b.Vehicle.FipeCode); byFipeCode != 0 {
+			return byFipeCode
+		}
+		return cmp.Compare(a.Vehicle.YearModel, b.Vehicle.YearModel)
+	})
+	return matches[:min(limit, len(matches))]
+}
+
+// ListingMatchResult is the outcome of a listing of a batch: its matches, possibly none, or why its
+// description is invalid.
+type ListingMatchResult struct {
+	Description string
+	Status      LookupStatus
+	Message     string
+	Matches     []ListingMatch
+}
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The accuracy MatchListing must keep on the labelled listings of testdata/listings.json: how many
+// have the labelled vehicle as the first match, and among the first 3 matches.
+const (
+	minListingTop1Accuracy = 0.9
+	minListingTop3Accuracy = 0.95
+)
+
+// testListingClock fixes the century of the 2-digit years of the listings.
+var testListingClock = FixedClock(time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC))
+
+func TestNewListing(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		wantWords   []string
+		wantYear    string
+		wantFuel    string
+		wantText    string
+		wantErr     *QueryError
+	}{
+		{
+			name:        "year and fuel",
+			description: " FIAT 147 CL 1991 GAS ",
+			wantWords:   []string{"fiat", "147", "cl"},
+			wantYear:    "1991",
+			wantFuel:    "gasolina",
+			wantText:    "fiat 147 cl 1991 gasolina",
+		},
+		{
+			name:        "year of manufacture and year model",
+			description: "VW Voyage 1,6 2014/2015 Álcool",
+			wantWords:   []string{"vw", "voyage", "1.6"},
+			wantYear:    "2015",
+			wantFuel:    "alcool",
+			wantText:    "vw voyage 1.6 2015 alcool",
+		},
+		{
+			name:        "a model named as a year",
+			description: "Peugeot 2008 Griffe 2017",
+			wantWords:   []string{"peugeot", "2008", "griffe"},
+			wantYear:    "2017",
+			wantText:    "peugeot 2008 griffe 2017",
+		},
+		{
+			name:        "2-digit year before the fuel",
+			description: "FIAT 147 GLS 86 ALC",
+			wantWords:   []string{"fiat", "147", "gls"},
+			wantYear:    "1986",
+			wantFuel:    "alcool",
+			wantText:    "fiat 147 gls 1986 alcool",
+		},
+		{
+			name:        "2-digit years of manufacture and year model",
+			description: "Gol 1.0 14/15",
+			wantWords:   []string{"gol", "1.0"},
+			wantYear:    "2015",
+			wantText:    "gol 1.0 2015",
+		},
+		{
+			name:        "2-digit model word",
+			description: "Renault 19 RN 1.8",
+			wantWords:   []string{"renault", "19", "rn", "1.8"},
+			wantText:    "renault 19 rn 1.8",
+		},
+		{
+			name:        "2-digit number with a 4-digit year",
+			description: "Chevrolet D-20 Custom 1995",
+			wantWords:   []string{"chevrolet", "d", "20", "custom"},
+			wantYear:    "1995",
+			wantText:    "chevrolet d 20 custom 1995",
+		},
+		{
+			name:        "no year nor fuel",
+			description: "Fusca Fusca 1600",
+			wantWords:   []string{"fusca", "1600"},
+			wantText:    "fusca 1600",
+		},
+		{
+			name:        "empty",
+			description: "  ",
+			wantErr:     &QueryError{Reason: "invalid_description", Message: "Description must have between 1 and 200 characters"},
+		},
+		{
+			name:        "too long",
+			description: strings.Repeat("a", MaxListingLength+1),
+			wantErr:     &QueryError{Reason: "invalid_description", Message: "Description must have between 1 and 200 characters"},
+		},
+		{
+			name:        "only a year and a fuel",
+			description: "2015 flex",
+			wantErr:     &QueryError{Reason: "invalid_description", Message: "Description must have at least 1 word of the brand or the model"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewListing(testListingClock, tt.description)
+			assert.Equal(t, tt.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, strings.TrimSpace(tt.description), got.Description())
+			assert.Equal(t, tt.wantWords, got.Words())
+			assert.Equal(t, tt.wantYear, got.Year())
+			assert.Equal(t, tt.wantFuel, got.Fuel())
+			assert.Equal(t, tt.wantText, got.Text())
+		})
+	}
+}
+
+func TestNewListing_TwoDigitYearPivot(t *testing.T) {
+	tests := []struct {
+		name        string
+		now         time.Time
+		description string
+		wantYear    string
+	}{
+		{
+			name:        "next year",
+			now:         time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+			description: "Gol 1.0 27",
+			wantYear:    "2027",
+		},
+		{
+			name:        "after next year",
+			now:         time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC),
+			description: "Gol 1.0 28",
+			wantYear:    "1928",
+		},
+		{
+			name:        "next year once the year turned",
+			now:         time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			description: "Gol 1.0 28",
+			wantYear:    "2028",
+		},
+		{
+			name:        "last century",
+			now:         time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			description: "Gol 1.0 86",
+			wantYear:    "1986",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewListing(FixedClock(tt.now), tt.description)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantYear, got.Year())
+		})
+	}
+}
+
+func TestListing_Confidence(t *testing.T) {
+	vehicle := Vehicle{Brand: "Fiat", Model: "147 C/ CL", YearModel: "1991 Gasolina"}
+	tests := []struct {
+		name        string
+		description string
+		want        float64
+	}{
+		{name: "every word", description: "FIAT 147 C CL 1991 GAS", want: 1},
+		{name: "no year nor fuel", description: "FIAT 147 C CL", want: 1},
+		{name: "another year", description: "FIAT 147 C CL 1992 GAS", want: 0.8},
+		{name: "no brand", description: "147 C CL 1991 GAS", want: 0.75},
+		{name: "another vehicle of the same year and fuel", description: "Corcel II 1991 gasolina", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing, err := NewListing(testListingClock, tt.description)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.InDelta(t, tt.want, listing.Confidence(vehicle), 1e-9)
+		})
+	}
+}
+
+func TestMatchListing(t *testing.T) {
+	vehicles := GetDomainVehiclesExamples()
+	listing, err := NewListing(testListingClock, "Fiat 147 CL 1991 gasolina")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := MatchListing(listing, vehicles, 5)
+	if assert.Len(t, got, 2, "the Acura only shares the fuel") {
+		assert.Equal(t, vehicles[2], got[0].Vehicle, "latest reference month")
+		assert.Equal(t, vehicles[3], got[1].Vehicle)
+		assert.InDelta(t, 0.91, got[0].Confidence, 1e-9)
+	}
+
+	assert.Empty(t, MatchListing(listing, vehicles, 0))
+}
+
+// listingFixture is testdata/listings.json: a catalog and listings labelled with the fipe code and the
+// year model of the catalog they describe. The catalog also has decoys of the same brands differing by a
+// trim, an engine, a body, a gearbox, a fuel or a year, and some listings leave out what tells them apart.
+type listingFixture struct {
+	Catalog []struct {
+		FipeCode  string `json:"fipe_code"`
+		Brand     string `json:"brand"`
+		Model     string `json:"model"`
+		YearModel string `json:"year_model"`
+	} `json:"catalog"`
+	Listings []struct {
+		Description string `json:"description"`
+		FipeCode    string `json:"fipe_code"`
+		YearModel   string `json:"year_model"`
+	} `json:"listings"`
+}
+
+func TestMatchListing_Accuracy(t *testing.T) {
+	content, err := os.ReadFile("testdata/listings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture listingFixture
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		t.Fatal(err)
+	}
+	catalog := make([]Vehicle, 0, len(fixture.Catalog))
+	for _, entry := range fixture.Catalog {
+		catalog = append(catalog, Vehicle{
+			Year: 2021, Month: 7, FipeCode: entry.FipeCode, Brand: entry.Brand, Model: entry.Model, YearModel: entry.YearModel,
+		})
+	}
+
+	var top1, top3 int
+	for _, labelled := range fixture.Listings {
+		listing, errListing := NewListing(testListingClock, labelled.Description)
+		if errListing != nil {
+			t.Fatalf("%q: %v", labelled.Description, errListing)
+		}
+		matches := MatchListing(listing, catalog, 3)
+		for rank, match := range matches {
+			if match.Vehicle.FipeCode != labelled.FipeCode || match.Vehicle.YearModel != labelled.YearModel {
+				continue
+			}
+			if rank == 0 {
+				top1++
+			}
+			top3++
+		}
+		if len(matches) == 0 || matches[0].Vehicle.FipeCode != labelled.FipeCode || matches[0].Vehicle.YearModel != labelled.YearModel {
+			t.Logf("%q: first match is not %s %s: %v", labelled.Description, labelled.FipeCode, labelled.YearModel, matches)
+		}
+	}
+
+	top1Accuracy := float64(top1) / float64(len(fixture.Listings))
+	top3Accuracy := float64(top3) / float64(len(fixture.Listings))
+	t.Logf("%d listings, top 1 accuracy %.3f, top 3 accuracy %.3f", len(fixture.Listings), top1Accuracy, top3Accuracy)
+	assert.GreaterOrEqual(t, top1Accuracy, minListingTop1Accuracy)
+	assert.GreaterOrEqual(t, top3Accuracy, minListingTop3Accuracy)
+}
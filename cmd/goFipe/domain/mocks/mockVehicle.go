@@ -5,7 +5,9 @@
 package mock_ports
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/raffops/gofipe/cmd/goFipe/domain"
@@ -35,19 +37,138 @@ func (m *MockVehicleService) EXPECT() *MockVehicleServiceMockRecorder {
 	return m.recorder
 }
 
+// Autocomplete mocks base method.
+func (m *MockVehicleService) Autocomplete(ctx context.Context, autocomplete domain.Autocomplete) ([]domain.AutocompleteValue, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autocomplete", ctx, autocomplete)
+	ret0, _ := ret[0].([]domain.AutocompleteValue)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// Autocomplete indicates an expected call of Autocomplete.
+func (mr *MockVehicleServiceMockRecorder) Autocomplete(ctx, autocomplete interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autocomplete", reflect.TypeOf((*MockVehicleService)(nil).Autocomplete), ctx, autocomplete)
+}
+
+// GetHistory mocks base method.
+func (m *MockVehicleService) GetHistory(ctx context.Context, fipeCode string, period domain.Period) (domain.VehicleHistory, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, fipeCode, period)
+	ret0, _ := ret[0].(domain.VehicleHistory)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockVehicleServiceMockRecorder) GetHistory(ctx, fipeCode, period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockVehicleService)(nil).GetHistory), ctx, fipeCode, period)
+}
+
+// GetPriceAsOf mocks base method.
+func (m *MockVehicleService) GetPriceAsOf(ctx context.Context, fipeCode, yearModel string, asOf time.Time) (domain.VehiclePriceAsOf, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceAsOf", ctx, fipeCode, yearModel, asOf)
+	ret0, _ := ret[0].(domain.VehiclePriceAsOf)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetPriceAsOf indicates an expected call of GetPriceAsOf.
+func (mr *MockVehicleServiceMockRecorder) GetPriceAsOf(ctx, fipeCode, yearModel, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceAsOf", reflect.TypeOf((*MockVehicleService)(nil).GetPriceAsOf), ctx, fipeCode, yearModel, asOf)
+}
+
 // GetVehicle mocks base method.
-func (m *MockVehicleService) GetVehicle(where map[string]string, orderBy map[string]bool, limit, offset int) ([]domain.Vehicle, *errs.AppError) {
+func (m *MockVehicleService) GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetVehicle", where, orderBy, limit, offset)
+	ret := m.ctrl.Call(m, "GetVehicle", ctx, query)
 	ret0, _ := ret[0].([]domain.Vehicle)
 	ret1, _ := ret[1].(*errs.AppError)
 	return ret0, ret1
 }
 
 // GetVehicle indicates an expected call of GetVehicle.
-func (mr *MockVehicleServiceMockRecorder) GetVehicle(where, orderBy, limit, offset interface{}) *gomock.Call {
+func (mr *MockVehicleServiceMockRecorder) GetVehicle(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicle", reflect.TypeOf((*MockVehicleService)(nil).GetVehicle), ctx, query)
+}
+
+// LookupVehicles mocks base method.
+func (m *MockVehicleService) LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.VehicleLookupResult, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupVehicles", ctx, lookups)
+	ret0, _ := ret[0].([]domain.VehicleLookupResult)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// LookupVehicles indicates an expected call of LookupVehicles.
+func (mr *MockVehicleServiceMockRecorder) LookupVehicles(ctx, lookups interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupVehicles", reflect.TypeOf((*MockVehicleService)(nil).LookupVehicles), ctx, lookups)
+}
+
+// MatchListing mocks base method.
+func (m *MockVehicleService) MatchListing(ctx context.Context, listing domain.Listing, limit int) ([]domain.ListingMatch, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchListing", ctx, listing, limit)
+	ret0, _ := ret[0].([]domain.ListingMatch)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// MatchListing indicates an expected call of MatchListing.
+func (mr *MockVehicleServiceMockRecorder) MatchListing(ctx, listing, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchListing", reflect.TypeOf((*MockVehicleService)(nil).MatchListing), ctx, listing, limit)
+}
+
+// MatchListings mocks base method.
+func (m *MockVehicleService) MatchListings(ctx context.Context, descriptions []string, limit int) ([]domain.ListingMatchResult, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchListings", ctx, descriptions, limit)
+	ret0, _ := ret[0].([]domain.ListingMatchResult)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// MatchListings indicates an expected call of MatchListings.
+func (mr *MockVehicleServiceMockRecorder) MatchListings(ctx, descriptions, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchListings", reflect.TypeOf((*MockVehicleService)(nil).MatchListings), ctx, descriptions, limit)
+}
+
+// SaveVehicles mocks base method.
+func (m *MockVehicleService) SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVehicles", ctx, vehicles)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// SaveVehicles indicates an expected call of SaveVehicles.
+func (mr *MockVehicleServiceMockRecorder) SaveVehicles(ctx, vehicles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicle", reflect.TypeOf((*MockVehicleService)(nil).GetVehicle), where, orderBy, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVehicles", reflect.TypeOf((*MockVehicleService)(nil).SaveVehicles), ctx, vehicles)
+}
+
+// SearchVehicles mocks base method.
+func (m *MockVehicleService) SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchVehicles", ctx, search)
+	ret0, _ := ret[0].([]domain.VehicleMatch)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// SearchVehicles indicates an expected call of SearchVehicles.
+func (mr *MockVehicleServiceMockRecorder) SearchVehicles(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchVehicles", reflect.TypeOf((*MockVehicleService)(nil).SearchVehicles), ctx, search)
 }
 
 // MockVehicleRepository is a mock of VehicleRepository interface.
@@ -73,17 +194,466 @@ func (m *MockVehicleRepository) EXPECT() *MockVehicleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Autocomplete mocks base method.
+func (m *MockVehicleRepository) Autocomplete(ctx context.Context, autocomplete domain.Autocomplete) ([]domain.AutocompleteValue, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autocomplete", ctx, autocomplete)
+	ret0, _ := ret[0].([]domain.AutocompleteValue)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// Autocomplete indicates an expected call of Autocomplete.
+func (mr *MockVehicleRepositoryMockRecorder) Autocomplete(ctx, autocomplete interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autocomplete", reflect.TypeOf((*MockVehicleRepository)(nil).Autocomplete), ctx, autocomplete)
+}
+
+// GetIngestionVersion mocks base method.
+func (m *MockVehicleRepository) GetIngestionVersion(ctx context.Context) (int64, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngestionVersion", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetIngestionVersion indicates an expected call of GetIngestionVersion.
+func (mr *MockVehicleRepositoryMockRecorder) GetIngestionVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngestionVersion", reflect.TypeOf((*MockVehicleRepository)(nil).GetIngestionVersion), ctx)
+}
+
+// GetListingCandidates mocks base method.
+func (m *MockVehicleRepository) GetListingCandidates(ctx context.Context, listing domain.Listing) ([]domain.Vehicle, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListingCandidates", ctx, listing)
+	ret0, _ := ret[0].([]domain.Vehicle)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetListingCandidates indicates an expected call of GetListingCandidates.
+func (mr *MockVehicleRepositoryMockRecorder) GetListingCandidates(ctx, listing interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListingCandidates", reflect.TypeOf((*MockVehicleRepository)(nil).GetListingCandidates), ctx, listing)
+}
+
+// GetPriceAsOf mocks base method.
+func (m *MockVehicleRepository) GetPriceAsOf(ctx context.Context, fipeCode, yearModel string, referenceMonth domain.ReferenceMonth) (domain.Vehicle, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPriceAsOf", ctx, fipeCode, yearModel, referenceMonth)
+	ret0, _ := ret[0].(domain.Vehicle)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetPriceAsOf indicates an expected call of GetPriceAsOf.
+func (mr *MockVehicleRepositoryMockRecorder) GetPriceAsOf(ctx, fipeCode, yearModel, referenceMonth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceAsOf", reflect.TypeOf((*MockVehicleRepository)(nil).GetPriceAsOf), ctx, fipeCode, yearModel, referenceMonth)
+}
+
 // GetVehicle mocks base method.
-func (m *MockVehicleRepository) GetVehicle(whereClauses []domain.WhereClause, orderByClauses []domain.OrderByClause, pagination domain.Pagination) ([]domain.Vehicle, *errs.AppError) {
+func (m *MockVehicleRepository) GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetVehicle", whereClauses, orderByClauses, pagination)
+	ret := m.ctrl.Call(m, "GetVehicle", ctx, query)
 	ret0, _ := ret[0].([]domain.Vehicle)
 	ret1, _ := ret[1].(*errs.AppError)
 	return ret0, ret1
 }
 
 // GetVehicle indicates an expected call of GetVehicle.
-func (mr *MockVehicleRepositoryMockRecorder) GetVehicle(whereClauses, orderByClauses, pagination interface{}) *gomock.Call {
+func (mr *MockVehicleRepositoryMockRecorder) GetVehicle(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicle", reflect.TypeOf((*MockVehicleRepository)(nil).GetVehicle), ctx, query)
+}
+
+// LookupVehicles mocks base method.
+func (m *MockVehicleRepository) LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.Vehicle, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupVehicles", ctx, lookups)
+	ret0, _ := ret[0].([]domain.Vehicle)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// LookupVehicles indicates an expected call of LookupVehicles.
+func (mr *MockVehicleRepositoryMockRecorder) LookupVehicles(ctx, lookups interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupVehicles", reflect.TypeOf((*MockVehicleRepository)(nil).LookupVehicles), ctx, lookups)
+}
+
+// SaveVehicles mocks base method.
+func (m *MockVehicleRepository) SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVehicles", ctx, vehicles)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// SaveVehicles indicates an expected call of SaveVehicles.
+func (mr *MockVehicleRepositoryMockRecorder) SaveVehicles(ctx, vehicles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVehicles", reflect.TypeOf((*MockVehicleRepository)(nil).SaveVehicles), ctx, vehicles)
+}
+
+// SearchVehicles mocks base method.
+func (m *MockVehicleRepository) SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchVehicles", ctx, search)
+	ret0, _ := ret[0].([]domain.VehicleMatch)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// SearchVehicles indicates an expected call of SearchVehicles.
+func (mr *MockVehicleRepositoryMockRecorder) SearchVehicles(ctx, search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchVehicles", reflect.TypeOf((*MockVehicleRepository)(nil).SearchVehicles), ctx, search)
+}
+
+// MockApiKeyService is a mock of ApiKeyService interface.
+type MockApiKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyServiceMockRecorder
+}
+
+// MockApiKeyServiceMockRecorder is the mock recorder for MockApiKeyService.
+type MockApiKeyServiceMockRecorder struct {
+	mock *MockApiKeyService
+}
+
+// NewMockApiKeyService creates a new mock instance.
+func NewMockApiKeyService(ctrl *gomock.Controller) *MockApiKeyService {
+	mock := &MockApiKeyService{ctrl: ctrl}
+	mock.recorder = &MockApiKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKeyService) EXPECT() *MockApiKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// AccountUsage mocks base method.
+func (m *MockApiKeyService) AccountUsage(ctx context.Context, apiKey domain.ApiKey) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountUsage", ctx, apiKey)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// AccountUsage indicates an expected call of AccountUsage.
+func (mr *MockApiKeyServiceMockRecorder) AccountUsage(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountUsage", reflect.TypeOf((*MockApiKeyService)(nil).AccountUsage), ctx, apiKey)
+}
+
+// Authenticate mocks base method.
+func (m *MockApiKeyService) Authenticate(ctx context.Context, rawKey string) (domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, rawKey)
+	ret0, _ := ret[0].(domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockApiKeyServiceMockRecorder) Authenticate(ctx, rawKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockApiKeyService)(nil).Authenticate), ctx, rawKey)
+}
+
+// Create mocks base method.
+func (m *MockApiKeyService) Create(ctx context.Context, name string, monthlyQuota int) (domain.ApiKey, string, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, name, monthlyQuota)
+	ret0, _ := ret[0].(domain.ApiKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(*errs.AppError)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApiKeyServiceMockRecorder) Create(ctx, name, monthlyQuota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApiKeyService)(nil).Create), ctx, name, monthlyQuota)
+}
+
+// GetUsage mocks base method.
+func (m *MockApiKeyService) GetUsage(ctx context.Context, id uint, year, month int) (domain.ApiKeyUsage, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, id, year, month)
+	ret0, _ := ret[0].(domain.ApiKeyUsage)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockApiKeyServiceMockRecorder) GetUsage(ctx, id, year, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockApiKeyService)(nil).GetUsage), ctx, id, year, month)
+}
+
+// List mocks base method.
+func (m *MockApiKeyService) List(ctx context.Context) ([]domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockApiKeyServiceMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApiKeyService)(nil).List), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockApiKeyService) Revoke(ctx context.Context, id uint) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockApiKeyServiceMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockApiKeyService)(nil).Revoke), ctx, id)
+}
+
+// MockApiKeyRepository is a mock of ApiKeyRepository interface.
+type MockApiKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockApiKeyRepositoryMockRecorder
+}
+
+// MockApiKeyRepositoryMockRecorder is the mock recorder for MockApiKeyRepository.
+type MockApiKeyRepositoryMockRecorder struct {
+	mock *MockApiKeyRepository
+}
+
+// NewMockApiKeyRepository creates a new mock instance.
+func NewMockApiKeyRepository(ctrl *gomock.Controller) *MockApiKeyRepository {
+	mock := &MockApiKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockApiKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockApiKeyRepository) EXPECT() *MockApiKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockApiKeyRepository) Create(ctx context.Context, apiKey domain.ApiKey) (domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, apiKey)
+	ret0, _ := ret[0].(domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockApiKeyRepositoryMockRecorder) Create(ctx, apiKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockApiKeyRepository)(nil).Create), ctx, apiKey)
+}
+
+// GetByHash mocks base method.
+func (m *MockApiKeyRepository) GetByHash(ctx context.Context, hash string) (domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, hash)
+	ret0, _ := ret[0].(domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockApiKeyRepositoryMockRecorder) GetByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockApiKeyRepository)(nil).GetByHash), ctx, hash)
+}
+
+// GetByID mocks base method.
+func (m *MockApiKeyRepository) GetByID(ctx context.Context, id uint) (domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockApiKeyRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockApiKeyRepository)(nil).GetByID), ctx, id)
+}
+
+// GetUsage mocks base method.
+func (m *MockApiKeyRepository) GetUsage(ctx context.Context, id uint, year, month int) (domain.ApiKeyUsage, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, id, year, month)
+	ret0, _ := ret[0].(domain.ApiKeyUsage)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage.
+func (mr *MockApiKeyRepositoryMockRecorder) GetUsage(ctx, id, year, month interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockApiKeyRepository)(nil).GetUsage), ctx, id, year, month)
+}
+
+// IncrementUsage mocks base method.
+func (m *MockApiKeyRepository) IncrementUsage(ctx context.Context, id uint, year, month, monthlyQuota int) (domain.ApiKeyUsage, bool, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementUsage", ctx, id, year, month, monthlyQuota)
+	ret0, _ := ret[0].(domain.ApiKeyUsage)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(*errs.AppError)
+	return ret0, ret1, ret2
+}
+
+// IncrementUsage indicates an expected call of IncrementUsage.
+func (mr *MockApiKeyRepositoryMockRecorder) IncrementUsage(ctx, id, year, month, monthlyQuota interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUsage", reflect.TypeOf((*MockApiKeyRepository)(nil).IncrementUsage), ctx, id, year, month, monthlyQuota)
+}
+
+// List mocks base method.
+func (m *MockApiKeyRepository) List(ctx context.Context) ([]domain.ApiKey, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domain.ApiKey)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockApiKeyRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockApiKeyRepository)(nil).List), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockApiKeyRepository) Revoke(ctx context.Context, id uint, revokedAt time.Time) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, revokedAt)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockApiKeyRepositoryMockRecorder) Revoke(ctx, id, revokedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockApiKeyRepository)(nil).Revoke), ctx, id, revokedAt)
+}
+
+// MockHealthService is a mock of HealthService interface.
+type MockHealthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthServiceMockRecorder
+}
+
+// MockHealthServiceMockRecorder is the mock recorder for MockHealthService.
+type MockHealthServiceMockRecorder struct {
+	mock *MockHealthService
+}
+
+// NewMockHealthService creates a new mock instance.
+func NewMockHealthService(ctrl *gomock.Controller) *MockHealthService {
+	mock := &MockHealthService{ctrl: ctrl}
+	mock.recorder = &MockHealthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthService) EXPECT() *MockHealthServiceMockRecorder {
+	return m.recorder
+}
+
+// Readiness mocks base method.
+func (m *MockHealthService) Readiness(ctx context.Context) domain.Health {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", ctx)
+	ret0, _ := ret[0].(domain.Health)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockHealthServiceMockRecorder) Readiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockHealthService)(nil).Readiness), ctx)
+}
+
+// MockHealthRepository is a mock of HealthRepository interface.
+type MockHealthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthRepositoryMockRecorder
+}
+
+// MockHealthRepositoryMockRecorder is the mock recorder for MockHealthRepository.
+type MockHealthRepositoryMockRecorder struct {
+	mock *MockHealthRepository
+}
+
+// NewMockHealthRepository creates a new mock instance.
+func NewMockHealthRepository(ctrl *gomock.Controller) *MockHealthRepository {
+	mock := &MockHealthRepository{ctrl: ctrl}
+	mock.recorder = &MockHealthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthRepository) EXPECT() *MockHealthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetLatestReferenceMonth mocks base method.
+func (m *MockHealthRepository) GetLatestReferenceMonth(ctx context.Context) (int, int, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestReferenceMonth", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(*errs.AppError)
+	return ret0, ret1, ret2
+}
+
+// GetLatestReferenceMonth indicates an expected call of GetLatestReferenceMonth.
+func (mr *MockHealthRepositoryMockRecorder) GetLatestReferenceMonth(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestReferenceMonth", reflect.TypeOf((*MockHealthRepository)(nil).GetLatestReferenceMonth), ctx)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockHealthRepository) GetSchemaVersion(ctx context.Context) (int, *errs.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(*errs.AppError)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockHealthRepositoryMockRecorder) GetSchemaVersion(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockHealthRepository)(nil).GetSchemaVersion), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockHealthRepository) Ping(ctx context.Context) *errs.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(*errs.AppError)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockHealthRepositoryMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVehicle", reflect.TypeOf((*MockVehicleRepository)(nil).GetVehicle), whereClauses, orderByClauses, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockHealthRepository)(nil).Ping), ctx)
 }
@@ -1,6 +1,9 @@
 package ports
 
 import (
+	"context"
+	"time"
+
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	"github.com/raffops/gofipe/cmd/goFipe/errs"
 )
@@ -8,18 +11,53 @@ import (
 //go:generate mockgen -source ports.go -destination ../mocks/mockVehicle.go
 
 type VehicleService interface {
-	GetVehicle(
-		where map[string]string,
-		orderBy map[string]bool,
-		limit int,
-		offset int,
-	) ([]domain.Vehicle, *errs.AppError)
+	GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError)
+	SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError
+	GetHistory(ctx context.Context, fipeCode string, period domain.Period) (domain.VehicleHistory, *errs.AppError)
+	GetPriceAsOf(ctx context.Context, fipeCode string, yearModel string, asOf time.Time) (domain.VehiclePriceAsOf, *errs.AppError)
+	LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.VehicleLookupResult, *errs.AppError)
+	SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError)
+	Autocomplete(ctx context.Context, autocomplete domain.Autocomplete) ([]domain.AutocompleteValue, *errs.AppError)
+	MatchListing(ctx context.Context, listing domain.Listing, limit int) ([]domain.ListingMatch, *errs.AppError)
+	MatchListings(ctx context.Context, descriptions []string, limit int) ([]domain.ListingMatchResult, *errs.AppError)
 }
 
 type VehicleRepository interface {
-	GetVehicle(
-		whereClauses []domain.WhereClause,
-		orderByClauses []domain.OrderByClause,
-		pagination domain.Pagination,
-	) ([]domain.Vehicle, *errs.AppError)
+	GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError)
+	SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError
+	GetPriceAsOf(ctx context.Context, fipeCode string, yearModel string, referenceMonth domain.ReferenceMonth) (domain.Vehicle, *errs.AppError)
+	LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.Vehicle, *errs.AppError)
+	SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError)
+	Autocomplete(ctx context.Context, autocomplete domain.Autocomplete) ([]domain.AutocompleteValue, *errs.AppError)
+	GetListingCandidates(ctx context.Context, listing domain.Listing) ([]domain.Vehicle, *errs.AppError)
+	GetIngestionVersion(ctx context.Context) (int64, *errs.AppError)
+}
+
+type ApiKeyService interface {
+	Create(ctx context.Context, name string, monthlyQuota int) (domain.ApiKey, string, *errs.AppError)
+	Authenticate(ctx context.Context, rawKey string) (domain.ApiKey, *errs.AppError)
+	AccountUsage(ctx context.Context, apiKey domain.ApiKey) *errs.AppError
+	List(ctx context.Context) ([]domain.ApiKey, *errs.AppError)
+	Revoke(ctx context.Context, id uint) *errs.AppError
+	GetUsage(ctx context.Context, id uint, year int, month int) (domain.ApiKeyUsage, *errs.AppError)
+}
+
+type ApiKeyRepository interface {
+	Create(ctx context.Context, apiKey domain.ApiKey) (domain.ApiKey, *errs.AppError)
+	GetByID(ctx context.Context, id uint) (domain.ApiKey, *errs.AppError)
+	GetByHash(ctx context.Context, hash string) (domain.ApiKey, *errs.AppError)
+	List(ctx context.Context) ([]domain.ApiKey, *errs.AppError)
+	Revoke(ctx context.Context, id uint, revokedAt time.Time) *errs.AppError
+	GetUsage(ctx context.Context, id uint, year int, month int) (domain.ApiKeyUsage, *errs.AppError)
+	IncrementUsage(ctx context.Context, id uint, year int, month int, monthlyQuota int) (domain.ApiKeyUsage, bool, *errs.AppError)
+}
+
+type HealthService interface {
+	Readiness(ctx context.Context) domain.Health
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) *errs.AppError
+	GetSchemaVersion(ctx context.Context) (int, *errs.AppError)
+	GetLatestReferenceMonth(ctx context.Context) (year int, month int, err *errs.AppError)
 }
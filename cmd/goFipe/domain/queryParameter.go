@@ -1,19 +1,22 @@
 package domain
 
-const MaxLimit = 100
+import "fmt"
 
-type WhereClause struct {
-	Column   string
-	Operator string
-	Value    interface{}
-}
+const MaxLimit = 100
 
 type Pagination struct {
 	Offset int
 	Limit  int
 }
 
-type OrderByClause struct {
-	Column string
-	IsDesc bool
+// NewPagination skips offset vehicles, from 0 and possibly more than limit, and returns at most limit,
+// between 1 and MaxLimit.
+func NewPagination(offset int, limit int) (Pagination, *QueryError) {
+	if offset < 0 {
+		return Pagination{}, &QueryError{Reason: "invalid_offset", Message: "Offset must be greater or equal than 0"}
+	}
+	if limit < 1 || limit > MaxLimit {
+		return Pagination{}, &QueryError{Reason: "invalid_limit", Message: fmt.Sprintf("Limit must be between 1 and %d", MaxLimit)}
+	}
+	return Pagination{Offset: offset, Limit: limit}, nil
 }
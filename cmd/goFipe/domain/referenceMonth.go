@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+)
+
+// MinReferenceYear is the first year a reference month may be in.
+const MinReferenceYear = 1900
+
+// referenceMonthLayout formats a ReferenceMonth as YYYY-MM.
+const referenceMonthLayout = "2006-01"
+
+// ReferenceMonth is the month a FIPE price refers to, e.g. 2021-07. The zero ReferenceMonth is no
+// month, e.g. the open end of a Period.
+type ReferenceMonth struct {
+	year  int
+	month time.Month
+}
+
+// NewReferenceMonth returns the reference month of year and month, which must be between 1 and 12.
+func NewReferenceMonth(year int, month int) (ReferenceMonth, error) {
+	if year < MinReferenceYear || year > 9999 || !IsValidMonth(month) {
+		return ReferenceMonth{}, fmt.Errorf("invalid reference month %04d-%02d", year, month)
+	}
+	return ReferenceMonth{year: year, month: time.Month(month)}, nil
+}
+
+// ParseReferenceMonth parses a reference month formatted as YYYY-MM, e.g. 2021-07.
+func ParseReferenceMonth(value string) (ReferenceMonth, error) {
+	parsed, err := time.Parse(referenceMonthLayout, value)
+	if err != nil {
+		return ReferenceMonth{}, fmt.Errorf("invalid reference month %q, must be YYYY-MM", value)
+	}
+	return NewReferenceMonth(parsed.Year(), int(parsed.Month()))
+}
+
+// ReferenceMonthOf returns the reference month of t in UTC.
+func ReferenceMonthOf(t time.Time) ReferenceMonth {
+	year, month, _ := t.UTC().Date()
+	return ReferenceMonth{year: year, month: month}
+}
+
+// CurrentReferenceMonth returns the reference month of the current time of clock.
+func CurrentReferenceMonth(clock Clock) ReferenceMonth {
+	return ReferenceMonthOf(clock.Now())
+}
+
+func (r ReferenceMonth) Year() int {
+	return r.year
+}
+
+func (r ReferenceMonth) Month() int {
+	return int(r.month)
+}
+
+func (r ReferenceMonth) IsZero() bool {
+	return r == ReferenceMonth{}
+}
+
+// Compare returns -1 if r is before other, 0 if they are the same month and +1 if r is after other.
+func (r ReferenceMonth) Compare(other ReferenceMonth) int {
+	return cmp.Compare(r.index(), other.index())
+}
+
+func (r ReferenceMonth) Before(other ReferenceMonth) bool {
+	return r.Compare(other) < 0
+}
+
+func (r ReferenceMonth) After(other ReferenceMonth) bool {
+	return r.Compare(other) > 0
+}
+
+// AddMonths returns the reference month n months after r, or before it if n is negative.
+func (r ReferenceMonth) AddMonths(n int) ReferenceMonth {
+	index := r.index() + n
+	return ReferenceMonth{year: index / 12, month: time.Month(index%12 + 1)}
+}
+
+// MonthsSince returns how many months r is after other, negative if r is before it.
+func (r ReferenceMonth) MonthsSince(other ReferenceMonth) int {
+	return r.index() - other.index()
+}
+
+// IsFuture reports whether r is after the current reference month of clock.
+func (r ReferenceMonth) IsFuture(clock Clock) bool {
+	return r.After(CurrentReferenceMonth(clock))
+}
+
+// Time returns the first instant of r in UTC.
+func (r ReferenceMonth) Time() time.Time {
+	return time.Date(r.year, r.month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// String formats r as YYYY-MM, or as an empty string if r is zero.
+func (r ReferenceMonth) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	return r.Time().Format(referenceMonthLayout)
+}
+
+func (r ReferenceMonth) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *ReferenceMonth) UnmarshalText(text []byte) error {
+	parsed, err := ParseReferenceMonth(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// index counts the months since January of year 0.
+func (r ReferenceMonth) index() int {
+	return r.year*12 + int(r.month) - 1
+}
+
+// Period is the reference months from From to To, both included. A zero From or To leaves that end of
+// the period open, so the zero Period contains every month.
+type Period struct {
+	From ReferenceMonth
+	To   ReferenceMonth
+}
+
+// ParsePeriod parses the period from from to to, both included and formatted as YYYY-MM. An empty from
+// or to leaves that end of the period open.
+func ParsePeriod(from string, to string) (Period, *QueryError) {
+	var period Period
+	if from != "" {
+		referenceMonth, err := ParseReferenceMonth(from)
+		if err != nil {
+			return Period{}, &QueryError{Reason: "invalid_from", Message: "From must be a month formatted as YYYY-MM"}
+		}
+		period.From = referenceMonth
+	}
+	if to != "" {
+		referenceMonth, err := ParseReferenceMonth(to)
+		if err != nil {
+			return Period{}, &QueryError{Reason: "invalid_to", Message: "To must be a month formatted as YYYY-MM"}
+		}
+		period.To = referenceMonth
+	}
+	if !period.From.IsZero() && !period.To.IsZero() && period.From.After(period.To) {
+		return Period{}, &QueryError{Reason: "invalid_period", Message: "From must not be after to"}
+	}
+	return period, nil
+}
+
+func (p Period) IsZero() bool {
+	return p == Period{}
+}
+
+// Contains reports whether referenceMonth is in the period.
+func (p Period) Contains(referenceMonth ReferenceMonth) bool {
+	return (p.From.IsZero() || !referenceMonth.Before(p.From)) && (p.To.IsZero() || !referenceMonth.After(p.To))
+}
+
+// String formats the period as from..to, e.g. 2021-01..2021-07 or 2021-01.. if it has no end.
+func (p Period) String() string {
+	return p.From.String() + ".." + p.To.String()
+}
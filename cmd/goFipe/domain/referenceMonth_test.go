@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReferenceMonth(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    ReferenceMonth
+		wantErr bool
+	}{
+		{name: "valid", value: "2021-07", want: ReferenceMonth{year: 2021, month: time.July}},
+		{name: "month without leading zero", value: "2021-7", wantErr: true},
+		{name: "invalid month", value: "2021-13", wantErr: true},
+		{name: "before MinReferenceYear", value: "1899-12", wantErr: true},
+		{name: "date", value: "2021-07-01", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReferenceMonth(tt.value)
+			assert.Equal(t, tt.wantErr, err != nil, "ParseReferenceMonth(%q) error = %v", tt.value, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReferenceMonth(t *testing.T) {
+	july, _ := NewReferenceMonth(2021, 7)
+	december, _ := NewReferenceMonth(2020, 12)
+
+	assert.Equal(t, "2021-07", july.String())
+	assert.Equal(t, 2021, july.Year())
+	assert.Equal(t, 7, july.Month())
+	assert.Equal(t, "", ReferenceMonth{}.String())
+	assert.True(t, ReferenceMonth{}.IsZero())
+
+	assert.Equal(t, 1, july.Compare(december))
+	assert.Equal(t, -1, december.Compare(july))
+	assert.Equal(t, 0, july.Compare(july))
+	assert.True(t, december.Before(july))
+	assert.True(t, july.After(december))
+
+	assert.Equal(t, "2022-01", july.AddMonths(6).String())
+	assert.Equal(t, december, july.AddMonths(-7))
+	assert.Equal(t, 7, july.MonthsSince(december))
+	assert.Equal(t, -7, december.MonthsSince(july))
+
+	assert.Equal(t, time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC), july.Time())
+	assert.Equal(t, july, ReferenceMonthOf(time.Date(2021, time.July, 31, 23, 59, 0, 0, time.UTC)))
+
+	clock := FixedClock(time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, july, CurrentReferenceMonth(clock))
+	assert.False(t, july.IsFuture(clock))
+	assert.True(t, july.AddMonths(1).IsFuture(clock))
+
+	_, err := NewReferenceMonth(2021, 0)
+	assert.NotNil(t, err)
+}
+
+func TestReferenceMonth_JSON(t *testing.T) {
+	var decoded struct {
+		ReferenceMonth ReferenceMonth `json:"reference_month"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"reference_month":"2021-07"}`), &decoded))
+	assert.Equal(t, ReferenceMonth{year: 2021, month: time.July}, decoded.ReferenceMonth)
+
+	encoded, err := json.Marshal(decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"reference_month":"2021-07"}`, string(encoded))
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"reference_month":"07/2021"}`), &decoded))
+}
+
+func TestPeriod(t *testing.T) {
+	january, _ := NewReferenceMonth(2021, 1)
+	july, _ := NewReferenceMonth(2021, 7)
+
+	period := Period{From: january, To: july}
+	assert.True(t, period.Contains(january))
+	assert.True(t, period.Contains(july))
+	assert.False(t, period.Contains(july.AddMonths(1)))
+	assert.False(t, period.Contains(january.AddMonths(-1)))
+	assert.Equal(t, "2021-01..2021-07", period.String())
+
+	assert.True(t, Period{From: january}.Contains(july.AddMonths(100)))
+	assert.True(t, Period{}.Contains(january))
+	assert.True(t, Period{}.IsZero())
+	assert.Equal(t, "2021-01..", Period{From: january}.String())
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTextSearchLength is how many characters the text of a TextSearch may have.
+	MaxTextSearchLength = 100
+	// MaxTextSearchTerms is how many terms the text of a TextSearch may have.
+	MaxTextSearchTerms = 8
+)
+
+// AccentedLetters fold to the letter of UnaccentedLetters at the same position. Both are exported so
+// that indexes built by the database fold the same letters as FoldText.
+const (
+	AccentedLetters   = "ÁÀÂÃÄÅáàâãäåÉÈÊËéèêëÍÌÎÏíìîïÓÒÔÕÖóòôõöÚÙÛÜúùûüÇçÑñÝýÿ"
+	UnaccentedLetters = "AAAAAAaaaaaaEEEEeeeeIIIIiiiiOOOOOoooooUUUUuuuuCcNnYyy"
+)
+
+var accentFolder = func() *strings.Replacer {
+	accented, unaccented := []rune(AccentedLetters), []rune(UnaccentedLetters)
+	pairs := make([]string, 0, 2*len(accented))
+	for i := range accented {
+		pairs = append(pairs, string(accented[i]), string(unaccented[i]))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// textSearchSynonyms maps a term to the other ways FIPE writes it, e.g. the abbreviations of its model
+// names. A term matches a vehicle if any of them does.
+var textSearchSynonyms = map[string][]string{
+	"vw":         {"volkswagen"},
+	"volkswagen": {"vw"},
+	"gm":         {"chevrolet"},
+	"chevrolet":  {"gm"},
+	"automatico": {"aut"},
+	"automatica": {"aut"},
+	"mecanico":   {"mec"},
+	"cabine":     {"cab"},
+	"alcool":     {"alc"},
+}
+
+// FoldText folds the accents and the case of text, e.g. Citroën to citroen.
+func FoldText(text string) string {
+	return strings.ToLower(accentFolder.Replace(text))
+}
+
+// VehicleSearchText is the folded text a TextSearch matches: the brand, the model and the year model.
+func VehicleSearchText(vehicle Vehicle) string {
+	return FoldText(vehicle.Brand + " " + vehicle.Model + " " + vehicle.YearModel)
+}
+
+// TextSearch looks vehicles up by free text, e.g. "gol 1.6 2015". A vehicle matches if its search
+// text contains every term, written as typed or as any of its synonyms. It is only built by
+// NewTextSearch, so a TextSearch is always valid.
+type TextSearch struct {
+	terms      [][]string
+	pagination Pagination
+}
+
+// NewTextSearch splits text into folded terms of letters and digits, keeping decimal separators such as
+// the one of 1.6, and pages the matches.
+func NewTextSearch(text string, offset int, limit int) (TextSearch, *QueryError) {
+	if length := utf8.RuneCountInString(strings.TrimSpace(text)); length == 0 || length > MaxTextSearchLength {
+		return TextSearch{}, &QueryError{
+			Reason:  "invalid_q",
+			Message: fmt.Sprintf("Q must have between 1 and %d characters", MaxTextSearchLength),
+		}
+	}
+	pagination, err := NewPagination(offset, limit)
+	if err != nil {
+		return TextSearch{}, err
+	}
+
+	var terms [][]string
+	for _, term := range splitTerms(FoldText(text)) {
+		if slices.ContainsFunc(terms, func(alternatives []string) bool { return alternatives[0] == term }) {
+			continue
+		}
+		terms = append(terms, append([]string{term}, textSearchSynonyms[term]...))
+	}
+	switch {
+	case len(terms) == 0:
+		return TextSearch{}, &QueryError{Reason: "invalid_q", Message: "Q must have at least 1 letter or digit"}
+	case len(terms) > MaxTextSearchTerms:
+		return TextSearch{}, &QueryError{
+			Reason:  "too_many_terms",
+			Message: fmt.Sprintf("Q must have at most %d terms", MaxTextSearchTerms),
+		}
+	}
+	return TextSearch{terms: terms, pagination: pagination}, nil
+}
+
+// splitTerms splits folded text at every rune but letters, digits and the decimal separators between
+// digits; a decimal comma is written as a point, as FIPE does.
+func splitTerms(text string) []string {
+	runes := []rune(text)
+	var terms []string
+	var term strings.Builder
+	for i, r := range runes {
+		isDecimalSeparator := (r == '.' || r == ',') && i > 0 && i < len(runes)-1 &&
+			unicode.IsDigit(runes[i-1]) && unicode.IsDigit(runes[i+1])
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			term.WriteRune(r)
+		case isDecimalSeparator:
+			term.WriteRune('.')
+		case term.Len() > 0:
+			terms = append(terms, term.String())
+			term.Reset()
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// Terms returns each term followed by its synonyms.
+func (s TextSearch) Terms() [][]string {
+	terms := make([][]string, 0, len(s.terms))
+	for _, alternatives := range s.terms {
+		terms = append(terms, slices.Clone(alternatives))
+	}
+	return terms
+}
+
+// Text returns the terms as typed, without their synonyms, e.g. "gol 1.6 2015".
+func (s TextSearch) Text() string {
+	terms := make([]string, 0, len(s.terms))
+	for _, alternatives := range s.terms {
+		terms = append(terms, alternatives[0])
+	}
+	return strings.Join(terms, " ")
+}
+
+func (s TextSearch) Pagination() Pagination {
+	return s.pagination
+}
+
+// Score rates how well vehicle matches the search, from 0 if it misses any term to 1 if every term is
+// a word of its search text. A term scores 1 as a word, 0.75 as the start of a word and 0.5 anywhere
+// else in the text, taking its best alternative.
+func (s TextSearch) Score(vehicle Vehicle) float64 {
+	text := VehicleSearchText(vehicle)
+	words := splitTerms(text)
+
+	var score float64
+	for _, alternatives := range s.terms {
+		var termScore float64
+		for _, alternative := range alternatives {
+			switch {
+			case slices.Contains(words, alternative):
+				termScore = max(termScore, 1)
+			case slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, alternative) }):
+				termScore = max(termScore, 0.75)
+			case strings.Contains(text, alternative):
+				termScore = max(termScore, 0.5)
+			}
+		}
+		if termScore == 0 {
+			return 0
+		}
+		score += termScore
+	}
+	return score / float64(len(s.terms))
+}
+
+// VehicleMatch is a vehicle matching a TextSearch, in its latest reference month, with the score from 0 to
+// 1 it was ranked by, such as TextSearch.Score.
+type VehicleMatch struct {
+	Vehicle Vehicle
+	Score   float64
+}
+
+// RankVehicles is the text search of the backends without a text index: it scores the vehicles, keeps
+// the latest reference month of each fipe code and year model and returns the page of the search, best
+// matches first.
+func RankVehicles(vehicles []Vehicle, search TextSearch) []VehicleMatch {
+	type modelKey struct{ fipeCode, yearModel string }
+	latest := map[modelKey]VehicleMatch{}
+	for _, vehicle := range vehicles {
+		score := search.Score(vehicle)
+		if score == 0 {
+			continue
+		}
+		key := modelKey{vehicle.FipeCode, vehicle.YearModel}
+		if match, ok := latest[key]; !ok || vehicle.ReferenceMonth().After(match.Vehicle.ReferenceMonth()) {
+			latest[key] = VehicleMatch{Vehicle: vehicle, Score: score}
+		}
+	}
+
+	matches := make([]VehicleMatch, 0, len(latest))
+	for _, match := range latest {
+		matches = append(matches, match)
+	}
+	slices.SortFunc(matches, func(a VehicleMatch, b VehicleMatch) int {
+		if byScore := cmp.Compare(b.Score, a.Score); byScore != 0 {
+			return byScore
+		}
+		if byFipeCode := cmp.Compare(a.Vehicle.FipeCode, b.Vehicle.FipeCode); byFipeCode != 0 {
+			return byFipeCode
+		}
+		return cmp.Compare(a.Vehicle.YearModel, b.Vehicle.YearModel)
+	})
+
+	pagination := search.Pagination()
+	start := min(pagination.Offset, len(matches))
+	end := min(start+pagination.Limit, len(matches))
+	return matches[start:end]
+}
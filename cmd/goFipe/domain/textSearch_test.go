@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldText(t *testing.T) {
+	assert.Equal(t, utf8.RuneCountInString(AccentedLetters), utf8.RuneCountInString(UnaccentedLetters))
+	assert.Equal(t, "citroen c4 picasso 2.0 automatico", FoldText("Citroën C4 PICASSO 2.0 Automático"))
+}
+
+func TestNewTextSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		offset    int
+		limit     int
+		wantTerms [][]string
+		wantText  string
+		wantErr   *QueryError
+	}{
+		{
+			name:      "terms are folded and decimal separators kept",
+			text:      "  Gol 1,6 (2015)/Citroën ",
+			limit:     10,
+			wantTerms: [][]string{{"gol"}, {"1.6"}, {"2015"}, {"citroen"}},
+			wantText:  "gol 1.6 2015 citroen",
+		},
+		{
+			name:      "synonyms and repeated terms",
+			text:      "VW automático vw",
+			limit:     10,
+			wantTerms: [][]string{{"vw", "volkswagen"}, {"automatico", "aut"}},
+			wantText:  "vw automatico",
+		},
+		{
+			name:    "empty text",
+			text:    "   ",
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_q", Message: fmt.Sprintf("Q must have between 1 and %d characters", MaxTextSearchLength)},
+		},
+		{
+			name:    "too long text",
+			text:    strings.Repeat("a", MaxTextSearchLength+1),
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_q", Message: fmt.Sprintf("Q must have between 1 and %d characters", MaxTextSearchLength)},
+		},
+		{
+			name:    "no letter or digit",
+			text:    "-- / --",
+			limit:   10,
+			wantErr: &QueryError{Reason: "invalid_q", Message: "Q must have at least 1 letter or digit"},
+		},
+		{
+			name:    "too many terms",
+			text:    "a b c d e f g h i",
+			limit:   10,
+			wantErr: &QueryError{Reason: "too_many_terms", Message: fmt.Sprintf("Q must have at most %d terms", MaxTextSearchTerms)},
+		},
+		{
+			name:    "invalid limit",
+			text:    "gol",
+			wantErr: &QueryError{Reason: "invalid_limit", Message: fmt.Sprintf("Limit must be between 1 and %d", MaxLimit)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search, err := NewTextSearch(tt.text, tt.offset, tt.limit)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.wantTerms, search.Terms())
+				assert.Equal(t, tt.wantText, search.Text())
+				assert.Equal(t, Pagination{Offset: tt.offset, Limit: tt.limit}, search.Pagination())
+			}
+		})
+	}
+}
+
+func TestTextSearch_Score(t *testing.T) {
+	gol := Vehicle{Brand: "VW - VolksWagen", Model: "Gol 1.6 Mi Power 8V Aut.", YearModel: "2015 Gasolina"}
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{text: "gol 1.6 2015", want: 1},
+		{text: "volkswagen gol automatico", want: 1},
+		{text: "gol pow", want: 0.875},
+		{text: "olks", want: 0.5},
+		{text: "gol 1.8", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			search, err := NewTextSearch(tt.text, 0, 10)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, search.Score(gol))
+		})
+	}
+}
+
+func TestRankVehicles(t *testing.T) {
+	integra := Vehicle{Year: 2021, Month: 7, FipeCode: "111111-1", Brand: "Acura", Model: "Integra GS 1.8", YearModel: "1992 Gasolina"}
+	integraJune := integra
+	integraJune.Month = 6
+	integraGSR := Vehicle{Year: 2021, Month: 7, FipeCode: "111112-1", Brand: "Acura", Model: "Integra GSR 1.8", YearModel: "1997 Gasolina"}
+	fiat := GetDomainVehiclesExamples()[1]
+
+	search, _ := NewTextSearch("integra gs", 0, 10)
+	assert.Equal(t,
+		[]VehicleMatch{{Vehicle: integra, Score: 1}, {Vehicle: integraGSR, Score: 0.875}},
+		RankVehicles([]Vehicle{integraJune, integraGSR, fiat, integra}, search),
+	)
+
+	page, _ := NewTextSearch("integra", 1, 1)
+	assert.Equal(t, []VehicleMatch{{Vehicle: integraGSR, Score: 1}}, RankVehicles([]Vehicle{integra, integraGSR}, page))
+
+	pastTheEnd, _ := NewTextSearch("integra", 5, 1)
+	assert.Empty(t, RankVehicles([]Vehicle{integra, integraGSR}, pastTheEnd))
+}
@@ -18,42 +18,38 @@ type Vehicle struct {
 	MeanValue      float32
 }
 
-// Validate validates the vehicle struct
-func (v *Vehicle) Validate() error {
+// ReferenceMonth returns the reference month of the Year and Month of the vehicle.
+func (v Vehicle) ReferenceMonth() ReferenceMonth {
+	return ReferenceMonth{year: v.Year, month: time.Month(v.Month)}
+}
+
+// Validate validates the vehicle struct. The reference month must not be after the current one of clock.
+func (v *Vehicle) Validate(clock Clock) error {
 	validate := validator.New()
 	_ = validate.RegisterValidation("validateFipeCode", validateFipeCode)
-	validate.RegisterStructValidation(validateYearMonth, Vehicle{})
+	validate.RegisterStructValidation(func(sl validator.StructLevel) { validateYearMonth(sl, clock) }, Vehicle{})
 	return validate.Struct(v)
 }
 
-// validateYearMonth validates if the year/month are in the future
-func validateYearMonth(sl validator.StructLevel) {
+// validateYearMonth reports the year and the month if they are not a valid reference month
+func validateYearMonth(sl validator.StructLevel, clock Clock) {
 	vehicle := sl.Current().Interface().(Vehicle)
 
-	if IsValidYearMonth(vehicle.Year, vehicle.Month) {
+	if !IsValidYearMonth(clock, vehicle.Year, vehicle.Month) {
 		sl.ReportError(vehicle.Year, "Ano", "Year", "yearfuture", "")
 		sl.ReportError(vehicle.Month, "Mes", "Month", "monthfuture", "")
 	}
 }
 
-// IsValidYearMonth validates if the year/month are in the future or with invalid values
-func IsValidYearMonth(year int, month int) bool {
-	currentYear, currentMonth, _ := time.Now().Date()
-	if year > currentYear {
-		return false
-	}
-	if year == currentYear && month > int(currentMonth) {
-		return false
-	}
-	if year < 1900 || month < 1 || month > 12 {
-		return false
-	}
-	return true
+// IsValidYearMonth validates if the year/month are a reference month not after the current one of clock
+func IsValidYearMonth(clock Clock, year int, month int) bool {
+	referenceMonth, err := NewReferenceMonth(year, month)
+	return err == nil && !referenceMonth.IsFuture(clock)
 }
 
-func IsValidYear(year int) bool {
-	currentYear, _, _ := time.Now().Date()
-	return year >= 1900 && year <= currentYear
+// IsValidYear validates if the year is between MinReferenceYear and the current year of clock
+func IsValidYear(clock Clock, year int) bool {
+	return year >= MinReferenceYear && year <= CurrentReferenceMonth(clock).Year()
 }
 
 func IsValidMonth(month int) bool {
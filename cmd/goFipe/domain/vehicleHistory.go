@@ -0,0 +1,21 @@
+package domain
+
+// DefaultHistoryMonths is how many reference months a history covers when its period has no start.
+const DefaultHistoryMonths = 12
+
+// VehiclePrice is the mean value of a model year of a vehicle in a reference month.
+type VehiclePrice struct {
+	ReferenceMonth ReferenceMonth
+	YearModel      string
+	MeanValue      float32
+}
+
+// VehicleHistory is the prices of a fipe code in a period, in chronological order. Brand and Model
+// are the ones of the latest price.
+type VehicleHistory struct {
+	FipeCode string
+	Brand    string
+	Model    string
+	Period   Period
+	Prices   []VehiclePrice
+}
@@ -0,0 +1,29 @@
+package domain
+
+// MaxVehicleLookups is how many lookups a batch may have.
+const MaxVehicleLookups = 500
+
+// VehicleLookup identifies the price of a year model of a fipe code in a reference month.
+type VehicleLookup struct {
+	FipeCode       string
+	YearModel      string
+	ReferenceMonth ReferenceMonth
+}
+
+// LookupStatus tells whether a VehicleLookup matched a vehicle.
+type LookupStatus string
+
+const (
+	LookupFound    LookupStatus = "found"
+	LookupNotFound LookupStatus = "not_found"
+	LookupInvalid  LookupStatus = "invalid"
+)
+
+// VehicleLookupResult is the outcome of a VehicleLookup: the vehicle it matched if found, or why it is
+// invalid.
+type VehicleLookupResult struct {
+	Lookup  VehicleLookup
+	Status  LookupStatus
+	Message string
+	Vehicle Vehicle
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// AsOfLayout formats the date of a price lookup as YYYY-MM-DD.
+const AsOfLayout = "2006-01-02"
+
+// VehiclePriceAsOf is the price of a year model of a fipe code on the date AsOf, taken from the latest
+// reference month on or before it. Price.ReferenceMonth tells which month that was.
+type VehiclePriceAsOf struct {
+	FipeCode string
+	Brand    string
+	Model    string
+	AsOf     time.Time
+	Price    VehiclePrice
+}
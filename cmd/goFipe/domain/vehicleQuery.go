@@ -0,0 +1,297 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// VehicleField is a field of Vehicle that queries filter, sort or select by.
+type VehicleField string
+
+const (
+	VehicleFieldYear           VehicleField = "year"
+	VehicleFieldMonth          VehicleField = "month"
+	VehicleFieldFipeCode       VehicleField = "fipe_code"
+	VehicleFieldBrand          VehicleField = "brand"
+	VehicleFieldModel          VehicleField = "model"
+	VehicleFieldYearModel      VehicleField = "year_model"
+	VehicleFieldAuthentication VehicleField = "authentication"
+	VehicleFieldMeanValue      VehicleField = "mean_value"
+)
+
+// DefaultLimit is the page size of a VehicleQuery built without Page.
+const DefaultLimit = 10
+
+var (
+	// VehicleFields are every field of Vehicle, in the order they are returned.
+	VehicleFields = []VehicleField{
+		VehicleFieldYear,
+		VehicleFieldMonth,
+		VehicleFieldFipeCode,
+		VehicleFieldBrand,
+		VehicleFieldModel,
+		VehicleFieldYearModel,
+		VehicleFieldAuthentication,
+		VehicleFieldMeanValue,
+	}
+	// FilterableVehicleFields are the fields a VehicleQuery filters and sorts by.
+	FilterableVehicleFields = []VehicleField{
+		VehicleFieldFipeCode,
+		VehicleFieldYear,
+		VehicleFieldMonth,
+		VehicleFieldMeanValue,
+	}
+)
+
+// Filter matches the vehicles whose Field is equal to any of Values. Values are typed by field: int
+// for year and month, float64 for mean_value and string for the other fields.
+type Filter struct {
+	Field  VehicleField
+	Values []any
+}
+
+// Sort orders the vehicles by Field, descending if Desc is true.
+type Sort struct {
+	Field VehicleField
+	Desc  bool
+}
+
+// VehicleQuery selects vehicles of the period matching every filter and the filter tree, ordered by the
+// sorts in decreasing priority. It is only built by VehicleQueryBuilder, so a VehicleQuery is always valid.
+type VehicleQuery struct {
+	filters    []Filter
+	match      *FilterNode
+	period     Period
+	sorts      []Sort
+	pagination Pagination
+	fields     []VehicleField
+}
+
+func (q VehicleQuery) Filters() []Filter {
+	return slices.Clone(q.filters)
+}
+
+// Match returns the filter tree the vehicles must match besides the filters, if any.
+func (q VehicleQuery) Match() (FilterNode, bool) {
+	if q.match == nil {
+		return FilterNode{}, false
+	}
+	return *q.match, true
+}
+
+// Period returns the reference months of the vehicles, the zero Period if any month matches.
+func (q VehicleQuery) Period() Period {
+	return q.period
+}
+
+func (q VehicleQuery) Sorts() []Sort {
+	return slices.Clone(q.sorts)
+}
+
+func (q VehicleQuery) Pagination() Pagination {
+	return q.pagination
+}
+
+// Fields returns the fields to return, every field if the query does not select any.
+func (q VehicleQuery) Fields() []VehicleField {
+	if len(q.fields) == 0 {
+		return slices.Clone(VehicleFields)
+	}
+	return slices.Clone(q.fields)
+}
+
+// NextPage returns the query of the page following this one.
+func (q VehicleQuery) NextPage() VehicleQuery {
+	q.pagination.Offset += q.pagination.Limit
+	return q
+}
+
+// QueryError is a rule broken by the arguments of a VehicleQueryBuilder. Reason identifies the rule,
+// e.g. invalid_year, and Message describes it.
+type QueryError struct {
+	Reason  string
+	Message string
+}
+
+func (e *QueryError) Error() string {
+	return e.Message
+}
+
+// VehicleQueryBuilder builds a VehicleQuery from untyped arguments, such as query string parameters.
+// The first invalid argument is reported by Build and the following ones are ignored.
+type VehicleQueryBuilder struct {
+	query     VehicleQuery
+	clock     Clock
+	validator filterValidator
+	err       *QueryError
+}
+
+// NewVehicleQueryBuilder returns a builder validating years against SystemClock.
+func NewVehicleQueryBuilder() *VehicleQueryBuilder {
+	return NewVehicleQueryBuilderWithClock(SystemClock)
+}
+
+// NewVehicleQueryBuilderWithClock returns a builder validating years against clock.
+func NewVehicleQueryBuilderWithClock(clock Clock) *VehicleQueryBuilder {
+	return &VehicleQueryBuilder{
+		query:     VehicleQuery{pagination: Pagination{Offset: 0, Limit: DefaultLimit}},
+		clock:     clock,
+		validator: filterValidator{clock: clock},
+	}
+}
+
+// Where filters by field equal to value. Filtering the same field again matches any of the values.
+func (b *VehicleQueryBuilder) Where(field string, value string) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	typedValue, err := parseFilterValue(b.clock, VehicleField(field), value)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for index, filter := range b.query.filters {
+		if filter.Field == VehicleField(field) {
+			b.query.filters[index].Values = append(filter.Values, typedValue)
+			return b
+		}
+	}
+	b.query.filters = append(b.query.filters, Filter{Field: VehicleField(field), Values: []any{typedValue}})
+	return b
+}
+
+// Match filters by the tree node, see FilterNode. Matching again requires the vehicles to match every
+// tree; MaxFilterConditions counts the conditions of every tree.
+func (b *VehicleQueryBuilder) Match(node FilterNode) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	validNode, err := b.validator.validate(node, 1)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	if b.query.match != nil {
+		validNode = And(*b.query.match, validNode)
+	}
+	b.query.match = &validNode
+	return b
+}
+
+// Period restricts the vehicles to the reference months from from to to, see ParsePeriod.
+func (b *VehicleQueryBuilder) Period(from string, to string) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	period, err := ParsePeriod(from, to)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.query.period = period
+	return b
+}
+
+// OrderBy sorts by field after the sorts added before it. A field can only be sorted by once.
+func (b *VehicleQueryBuilder) OrderBy(field string, desc bool) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	if !slices.Contains(FilterableVehicleFields, VehicleField(field)) {
+		b.err = &QueryError{Reason: "invalid_order_by_column", Message: fmt.Sprintf("Invalid column: %s", field)}
+		return b
+	}
+	if slices.ContainsFunc(b.query.sorts, func(sort Sort) bool { return sort.Field == VehicleField(field) }) {
+		b.err = &QueryError{Reason: "duplicated_order_by_column", Message: fmt.Sprintf("Duplicated column: %s", field)}
+		return b
+	}
+	b.query.sorts = append(b.query.sorts, Sort{Field: VehicleField(field), Desc: desc})
+	return b
+}
+
+// Page skips offset vehicles and returns at most limit, between 1 and MaxLimit.
+func (b *VehicleQueryBuilder) Page(offset int, limit int) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.query.pagination, b.err = NewPagination(offset, limit)
+	return b
+}
+
+// Select restricts the returned fields to fields. Without Select every field is returned.
+func (b *VehicleQueryBuilder) Select(fields ...string) *VehicleQueryBuilder {
+	if b.err != nil {
+		return b
+	}
+	for _, field := range fields {
+		if !slices.Contains(VehicleFields, VehicleField(field)) {
+			b.err = &QueryError{Reason: "invalid_field", Message: fmt.Sprintf("Invalid field: %s", field)}
+			return b
+		}
+		if !slices.Contains(b.query.fields, VehicleField(field)) {
+			b.query.fields = append(b.query.fields, VehicleField(field))
+		}
+	}
+	return b
+}
+
+func (b *VehicleQueryBuilder) Build() (VehicleQuery, *QueryError) {
+	if b.err != nil {
+		return VehicleQuery{}, b.err
+	}
+	return b.query, nil
+}
+
+// MustBuild is like Build but panics if an argument is invalid. It is meant for queries of constant
+// arguments, such as the ones of tests.
+func (b *VehicleQueryBuilder) MustBuild() VehicleQuery {
+	query, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return query
+}
+
+func parseFilterValue(clock Clock, field VehicleField, value string) (any, *QueryError) {
+	if !slices.Contains(FilterableVehicleFields, field) {
+		return nil, &QueryError{Reason: "invalid_where_column", Message: "Invalid Column"}
+	}
+	return parseFieldValue(clock, field, value)
+}
+
+// parseFieldValue types value as the values of field, see Filter.
+func parseFieldValue(clock Clock, field VehicleField, value string) (any, *QueryError) {
+	switch field {
+	case VehicleFieldFipeCode:
+		if !IsValidFipeCode(value) {
+			return nil, &QueryError{Reason: "invalid_fipe_code", Message: "Invalid fipe code"}
+		}
+		return value, nil
+	case VehicleFieldYear:
+		year, err := strconv.Atoi(value)
+		if err != nil || !IsValidYear(clock, year) {
+			return nil, &QueryError{Reason: "invalid_year", Message: "Invalid year"}
+		}
+		return year, nil
+	case VehicleFieldMonth:
+		month, err := strconv.Atoi(value)
+		if err != nil || !IsValidMonth(month) {
+			return nil, &QueryError{Reason: "invalid_month", Message: "Invalid month"}
+		}
+		return month, nil
+	case VehicleFieldMeanValue:
+		meanValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, &QueryError{Reason: "invalid_mean_value", Message: "Invalid mean value"}
+		}
+		return meanValue, nil
+	default:
+		if value == "" {
+			return nil, &QueryError{Reason: "invalid_" + string(field), Message: fmt.Sprintf("Invalid %s", field)}
+		}
+		return value, nil
+	}
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVehicleQueryBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder
+		want    VehicleQuery
+		wantErr *QueryError
+	}{
+		{
+			name:  "defaults",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder },
+			want:  VehicleQuery{pagination: Pagination{Offset: 0, Limit: DefaultLimit}},
+		},
+		{
+			name: "typed filters, ordered sorts, page and fields",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder {
+				return builder.
+					Where("fipe_code", "111111-1").
+					Where("year", "2021").
+					Where("month", "7").
+					Where("mean_value", "700.5").
+					OrderBy("year", true).
+					OrderBy("month", true).
+					OrderBy("mean_value", false).
+					Page(20, 10).
+					Select("fipe_code", "mean_value", "fipe_code")
+			},
+			want: VehicleQuery{
+				filters: []Filter{
+					{Field: VehicleFieldFipeCode, Values: []any{"111111-1"}},
+					{Field: VehicleFieldYear, Values: []any{2021}},
+					{Field: VehicleFieldMonth, Values: []any{7}},
+					{Field: VehicleFieldMeanValue, Values: []any{700.5}},
+				},
+				sorts: []Sort{
+					{Field: VehicleFieldYear, Desc: true},
+					{Field: VehicleFieldMonth, Desc: true},
+					{Field: VehicleFieldMeanValue, Desc: false},
+				},
+				pagination: Pagination{Offset: 20, Limit: 10},
+				fields:     []VehicleField{VehicleFieldFipeCode, VehicleFieldMeanValue},
+			},
+		},
+		{
+			name: "repeated filter matches any of the values",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder {
+				return builder.Where("year", "2020").Where("month", "7").Where("year", "2021")
+			},
+			want: VehicleQuery{
+				filters: []Filter{
+					{Field: VehicleFieldYear, Values: []any{2020, 2021}},
+					{Field: VehicleFieldMonth, Values: []any{7}},
+				},
+				pagination: Pagination{Offset: 0, Limit: DefaultLimit},
+			},
+		},
+		{
+			name:    "invalid fipe code",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Where("fipe_code", "invalid") },
+			wantErr: &QueryError{Reason: "invalid_fipe_code", Message: "Invalid fipe code"},
+		},
+		{
+			name:    "invalid year",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Where("year", "1800") },
+			wantErr: &QueryError{Reason: "invalid_year", Message: "Invalid year"},
+		},
+		{
+			name:    "invalid month",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Where("month", "13") },
+			wantErr: &QueryError{Reason: "invalid_month", Message: "Invalid month"},
+		},
+		{
+			name:    "invalid mean value",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Where("mean_value", "cheap") },
+			wantErr: &QueryError{Reason: "invalid_mean_value", Message: "Invalid mean value"},
+		},
+		{
+			name:    "invalid where column",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Where("brand", "Fiat") },
+			wantErr: &QueryError{Reason: "invalid_where_column", Message: "Invalid Column"},
+		},
+		{
+			name:    "invalid order by column",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.OrderBy("brand", false) },
+			wantErr: &QueryError{Reason: "invalid_order_by_column", Message: "Invalid column: brand"},
+		},
+		{
+			name: "duplicated order by column",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder {
+				return builder.OrderBy("year", true).OrderBy("year", false)
+			},
+			wantErr: &QueryError{Reason: "duplicated_order_by_column", Message: "Duplicated column: year"},
+		},
+		{
+			name:    "negative offset",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Page(-1, 10) },
+			wantErr: &QueryError{Reason: "invalid_offset", Message: "Offset must be greater or equal than 0"},
+		},
+		{
+			name:  "offset greater than limit",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Page(20, 10) },
+			want:  VehicleQuery{pagination: Pagination{Offset: 20, Limit: 10}},
+		},
+		{
+			name:    "limit 0",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Page(0, 0) },
+			wantErr: &QueryError{Reason: "invalid_limit", Message: fmt.Sprintf("Limit must be between 1 and %d", MaxLimit)},
+		},
+		{
+			name:    "limit greater than MaxLimit",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Page(0, MaxLimit+1) },
+			wantErr: &QueryError{Reason: "invalid_limit", Message: fmt.Sprintf("Limit must be between 1 and %d", MaxLimit)},
+		},
+		{
+			name:    "invalid field",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Select("year", "price") },
+			wantErr: &QueryError{Reason: "invalid_field", Message: "Invalid field: price"},
+		},
+		{
+			name:  "period",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Period("2021-01", "2021-07") },
+			want: VehicleQuery{
+				period:     Period{From: ReferenceMonth{year: 2021, month: 1}, To: ReferenceMonth{year: 2021, month: 7}},
+				pagination: Pagination{Offset: 0, Limit: DefaultLimit},
+			},
+		},
+		{
+			name:  "period open at the end",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Period("2021-01", "") },
+			want: VehicleQuery{
+				period:     Period{From: ReferenceMonth{year: 2021, month: 1}},
+				pagination: Pagination{Offset: 0, Limit: DefaultLimit},
+			},
+		},
+		{
+			name:    "invalid from",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Period("2021-7", "") },
+			wantErr: &QueryError{Reason: "invalid_from", Message: "From must be a month formatted as YYYY-MM"},
+		},
+		{
+			name:    "invalid to",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Period("", "july") },
+			wantErr: &QueryError{Reason: "invalid_to", Message: "To must be a month formatted as YYYY-MM"},
+		},
+		{
+			name:    "from after to",
+			build:   func(builder *VehicleQueryBuilder) *VehicleQueryBuilder { return builder.Period("2021-08", "2021-07") },
+			wantErr: &QueryError{Reason: "invalid_period", Message: "From must not be after to"},
+		},
+		{
+			name: "first error is reported",
+			build: func(builder *VehicleQueryBuilder) *VehicleQueryBuilder {
+				return builder.Where("year", "invalid").OrderBy("brand", false).Page(-1, 0)
+			},
+			wantErr: &QueryError{Reason: "invalid_year", Message: "Invalid year"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(NewVehicleQueryBuilder()).Build()
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleQuery(t *testing.T) {
+	query, err := NewVehicleQueryBuilder().Where("year", "2021").Page(10, 5).Build()
+	assert.Nil(t, err)
+
+	assert.Equal(t, VehicleFields, query.Fields())
+	assert.Equal(t, Pagination{Offset: 15, Limit: 5}, query.NextPage().Pagination())
+	assert.Equal(t, Pagination{Offset: 10, Limit: 5}, query.Pagination(), "NextPage must not change the query")
+
+	query.Filters()[0].Field = VehicleFieldMonth
+	assert.Equal(t, VehicleFieldYear, query.Filters()[0].Field, "callers must not change the query")
+}
+
+func TestNewVehicleQueryBuilderWithClock(t *testing.T) {
+	clock := FixedClock(time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC))
+
+	_, err := NewVehicleQueryBuilderWithClock(clock).Where("year", "2021").Build()
+	assert.Nil(t, err)
+	_, err = NewVehicleQueryBuilderWithClock(clock).Where("year", "2022").Build()
+	assert.Equal(t, &QueryError{Reason: "invalid_year", Message: "Invalid year"}, err)
+	_, err = NewVehicleQueryBuilderWithClock(clock).Match(Compare("year", "gt", "2022")).Build()
+	assert.Equal(t, &QueryError{Reason: "invalid_year", Message: "Invalid year"}, err)
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestIsValidFipeCode(t *testing.T) {
 	type args struct {
@@ -87,6 +90,7 @@ func TestIsValidFipeCode(t *testing.T) {
 }
 
 func TestIsValidYearMonth(t *testing.T) {
+	clock := FixedClock(time.Date(2024, time.July, 31, 23, 0, 0, 0, time.UTC))
 	type args struct {
 		year  int
 		month int
@@ -106,6 +110,11 @@ func TestIsValidYearMonth(t *testing.T) {
 			args: args{year: 2042, month: 7},
 			want: false,
 		},
+		{
+			name: "Valid year and month (current month)",
+			args: args{year: 2024, month: 7},
+			want: true,
+		},
 		{
 			name: "Invalid year (future month)",
 			args: args{year: 2024, month: 8},
@@ -129,9 +138,22 @@ func TestIsValidYearMonth(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := IsValidYearMonth(tt.args.year, tt.args.month); got != tt.want {
+			if got := IsValidYearMonth(clock, tt.args.year, tt.args.month); got != tt.want {
 				t.Errorf("IsValidYearMonth() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestVehicle_Validate(t *testing.T) {
+	clock := FixedClock(time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC))
+	vehicle := GetDomainVehiclesExamples()[0]
+	if err := vehicle.Validate(clock); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	vehicle.Month = 8
+	if err := vehicle.Validate(clock); err == nil {
+		t.Errorf("Validate() = nil, want an error for a future reference month")
+	}
+}
@@ -47,3 +47,31 @@ func NewBadRequestError(message string) *AppError {
 		Code:    http.StatusBadRequest,
 	}
 }
+
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Message: message,
+		Code:    http.StatusUnauthorized,
+	}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{
+		Message: message,
+		Code:    http.StatusForbidden,
+	}
+}
+
+func NewTooManyRequestsError(message string) *AppError {
+	return &AppError{
+		Message: message,
+		Code:    http.StatusTooManyRequests,
+	}
+}
+
+func NewGatewayTimeoutError(message string) *AppError {
+	return &AppError{
+		Message: message,
+		Code:    http.StatusGatewayTimeout,
+	}
+}
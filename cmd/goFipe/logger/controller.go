@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelError = "error"
+
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+var (
+	Levels  = []string{LevelDebug, LevelInfo, LevelError}
+	Formats = []string{FormatJSON, FormatConsole}
+)
+
+// Controller changes the level and the format of a Logger at runtime. The change also applies to the
+// child loggers created before it.
+type Controller struct {
+	mu     sync.Mutex
+	output zapcore.WriteSyncer
+	level  zap.AtomicLevel
+	format string
+	core   atomic.Pointer[zapcore.Core]
+}
+
+func newController(output zapcore.WriteSyncer, level string, format string) (*Controller, error) {
+	controller := &Controller{output: output, level: zap.NewAtomicLevel()}
+	if err := controller.SetLevel(level); err != nil {
+		return nil, err
+	}
+	if err := controller.SetFormat(format); err != nil {
+		return nil, err
+	}
+	return controller, nil
+}
+
+func (c *Controller) Level() string {
+	return c.level.Level().String()
+}
+
+func (c *Controller) SetLevel(level string) error {
+	if !slices.Contains(Levels, level) {
+		return fmt.Errorf("log level must be one of %v, got %q", Levels, level)
+	}
+	var zapLevel zapcore.Level
+	if err := zapLevel.Set(level); err != nil {
+		return err
+	}
+	c.level.SetLevel(zapLevel)
+	return nil
+}
+
+func (c *Controller) Format() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.format
+}
+
+func (c *Controller) SetFormat(format string) error {
+	var encoder zapcore.Encoder
+	switch format {
+	case FormatJSON:
+		encoder = zapcore.NewJSONEncoder(encoderConfig(zap.NewProductionEncoderConfig()))
+	case FormatConsole:
+		encoder = zapcore.NewConsoleEncoder(encoderConfig(zap.NewDevelopmentEncoderConfig()))
+	default:
+		return fmt.Errorf("log format must be one of %v, got %q", Formats, format)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	core := zapcore.NewCore(encoder, c.output, c.level)
+	c.core.Store(&core)
+	c.format = format
+	return nil
+}
+
+func encoderConfig(config zapcore.EncoderConfig) zapcore.EncoderConfig {
+	config.TimeKey = "timestamp"
+	config.EncodeTime = zapcore.ISO8601TimeEncoder
+	return config
+}
+
+// switchableCore writes through the current core of the Controller. Fields added with With are kept
+// by the switchableCore instead of being encoded once, so they survive a format change.
+type switchableCore struct {
+	controller *Controller
+	fields     []zapcore.Field
+}
+
+func (s *switchableCore) current() zapcore.Core {
+	return *s.controller.core.Load()
+}
+
+func (s *switchableCore) Enabled(level zapcore.Level) bool {
+	return s.controller.level.Enabled(level)
+}
+
+func (s *switchableCore) With(fields []zapcore.Field) zapcore.Core {
+	return &switchableCore{controller: s.controller, fields: append(slices.Clip(s.fields), fields...)}
+}
+
+func (s *switchableCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if s.Enabled(entry.Level) {
+		return checked.AddCore(entry, s)
+	}
+	return checked
+}
+
+func (s *switchableCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return s.current().Write(entry, append(slices.Clip(s.fields), fields...))
+}
+
+func (s *switchableCore) Sync() error {
+	return s.current().Sync()
+}
@@ -1,56 +1,109 @@
 package logger
 
 import (
-	"os"
+	"context"
+	"io"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var log *zap.Logger
-
-func init() {
-	var err error
-	var config zap.Config
-	var encoderConfig zapcore.EncoderConfig
-	if os.Getenv("ENV") == "PRD" {
-		config = zap.NewProductionConfig()
-		encoderConfig = zap.NewProductionEncoderConfig()
-	} else {
-		config = zap.NewDevelopmentConfig()
-		encoderConfig = zap.NewDevelopmentEncoderConfig()
-	}
+type Field = zap.Field
+
+// Logger writes structured log entries. It is injected into the constructors of handlers, services and
+// repositories; request-scoped child loggers travel in the request context, see FromContext.
+type Logger interface {
+	Debug(message string, fields ...Field)
+	Info(message string, fields ...Field)
+	Error(message string, fields ...Field)
+	// With returns a child logger adding the fields to every entry.
+	With(fields ...Field) Logger
+	// Sync flushes any buffered log entries. It must be called before the application exits.
+	Sync() error
+}
 
-	encoderConfig.TimeKey = "timestamp"
-	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-	config.EncoderConfig = encoderConfig
+type zapLogger struct {
+	log *zap.Logger
+}
 
-	log, err = config.Build(zap.AddCallerSkip(1))
+// New returns a Logger writing to output with the given level ("debug", "info" or "error") and format
+// ("json" or "console"), and the Controller changing both at runtime.
+func New(output io.Writer, level string, format string) (Logger, *Controller, error) {
+	controller, err := newController(zapcore.AddSync(output), level, format)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
+	log := zap.New(
+		&switchableCore{controller: controller},
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	)
+	return zapLogger{log: log}, controller, nil
+}
+
+// NewNop returns a Logger discarding every entry, for tests.
+func NewNop() Logger {
+	return zapLogger{log: zap.NewNop()}
+}
+
+func (l zapLogger) Debug(message string, fields ...Field) {
+	l.log.Debug(message, fields...)
+}
+
+func (l zapLogger) Info(message string, fields ...Field) {
+	l.log.Info(message, fields...)
+}
+
+func (l zapLogger) Error(message string, fields ...Field) {
+	l.log.Error(message, fields...)
 }
 
-func Info(message string, fields ...zap.Field) {
-	log.Info(message, fields...)
+func (l zapLogger) With(fields ...Field) Logger {
+	return zapLogger{log: l.log.With(fields...)}
 }
 
-func Debug(message string, fields ...zap.Field) {
-	log.Debug(message, fields...)
+func (l zapLogger) Sync() error {
+	return l.log.Sync()
 }
 
-func Error(message string, fields ...zap.Field) {
-	log.Error(message, fields...)
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request-scoped logger.
+func NewContext(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
 }
 
-func Fatal(message string, fields ...zap.Field) {
-	log.Fatal(message, fields...)
+// FromContext returns the request-scoped logger carried by ctx, or fallback when there is none.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if log, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return log
+	}
+	return fallback
 }
 
-func String(key string, value string) zap.Field {
+func String(key string, value string) Field {
 	return zap.String(key, value)
 }
 
-func Int(key string, value int) zap.Field {
+func Int(key string, value int) Field {
 	return zap.Int(key, value)
 }
+
+func Float(key string, value float64) Field {
+	return zap.Float64(key, value)
+}
+
+func Bool(key string, value bool) Field {
+	return zap.Bool(key, value)
+}
+
+func Duration(key string, value time.Duration) Field {
+	return zap.Duration(key, value)
+}
+
+// Err logs the error message under the "error" key.
+func Err(err error) Field {
+	return zap.Error(err)
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		format  string
+		wantErr string
+	}{
+		{name: "Valid", level: LevelInfo, format: FormatJSON},
+		{name: "Invalid level", level: "warn", format: FormatJSON, wantErr: `log level must be one of [debug info error], got "warn"`},
+		{name: "Invalid format", level: LevelInfo, format: "text", wantErr: `log format must be one of [json console], got "text"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := New(&bytes.Buffer{}, tt.level, tt.format)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestController(t *testing.T) {
+	var output bytes.Buffer
+	log, controller, err := New(&output, LevelInfo, FormatJSON)
+	assert.Nil(t, err)
+	child := log.With(String("request_id", "abc"))
+
+	child.Debug("Filtered")
+	assert.Empty(t, output.String())
+
+	child.Info("Written", Int("count", 2), Duration("duration", 0), Float("ratio", 0.5))
+	var entry map[string]any
+	assert.Nil(t, json.Unmarshal(output.Bytes(), &entry))
+	assert.Equal(t, "Written", entry["msg"])
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, float64(2), entry["count"])
+	assert.Equal(t, 0.5, entry["ratio"])
+
+	assert.Nil(t, controller.SetLevel(LevelDebug))
+	assert.Nil(t, controller.SetFormat(FormatConsole))
+	assert.Equal(t, LevelDebug, controller.Level())
+	assert.Equal(t, FormatConsole, controller.Format())
+	output.Reset()
+
+	child.Debug("Console")
+	line := output.String()
+	assert.False(t, strings.HasPrefix(line, "{"), "console format expected, got %s", line)
+	assert.Contains(t, line, "Console")
+	assert.Contains(t, line, `"request_id": "abc"`, "fields of child loggers created before the change are kept")
+
+	assert.NotNil(t, controller.SetLevel("trace"))
+	assert.Equal(t, LevelDebug, controller.Level())
+}
+
+func TestFromContext(t *testing.T) {
+	fallback := NewNop()
+	requestLog := NewNop().With(String("request_id", "abc"))
+
+	assert.Equal(t, fallback, FromContext(context.Background(), fallback))
+	assert.Equal(t, requestLog, FromContext(NewContext(context.Background(), requestLog), fallback))
+}
@@ -1,17 +1,86 @@
 package main
 
 import (
-	"github.com/raffops/gofipe/cmd/goFipe/controller/rest"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	appConfig "github.com/raffops/gofipe/cmd/goFipe/config"
+	"github.com/raffops/gofipe/cmd/goFipe/controller/cli"
 	"github.com/raffops/gofipe/cmd/goFipe/database/postgres"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"github.com/raffops/gofipe/cmd/goFipe/repository/cache"
 	postgresRepo "github.com/raffops/gofipe/cmd/goFipe/repository/postgres"
 	"github.com/raffops/gofipe/cmd/goFipe/service"
 )
 
 func main() {
-	postgresConn := postgres.GetPostgresConnection()
-	defer postgres.ClosePostgresConnection(postgresConn)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], cli.Environment{
+		Stdin:     os.Stdin,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		LookupEnv: os.LookupEnv,
+		Open:      openServices,
+	})
+	stop()
+	os.Exit(code)
+}
+
+// openServices connects to the database and wires the repositories and the services used by the commands.
+func openServices(ctx context.Context, config appConfig.Config, log logger.Logger) (cli.Services, func(), error) {
+	postgresConn, err := postgres.GetPostgresConnection(ctx, config.Postgres, log)
+	if err != nil {
+		return cli.Services{}, nil, err
+	}
+	closeConn := func() {
+		if err := postgres.ClosePostgresConnection(postgresConn); err != nil {
+			log.Error("Unable to close the database connection pool", logger.Err(err))
+			return
+		}
+		log.Info("Database connection pool closed")
+	}
+	sqlDB, err := postgresConn.DB()
+	if err != nil {
+		closeConn()
+		return cli.Services{}, nil, fmt.Errorf("getting the database connection pool: %w", err)
+	}
+	metrics.RegisterDBStats(sqlDB, config.Postgres.Database)
+
+	vehicleRepo := newVehicleRepository(postgresRepo.NewVehicleRepositoryPostgres(postgresConn, log), config.Cache, log)
+	apiKeyRepo := postgresRepo.NewApiKeyRepositoryPostgres(postgresConn, log)
+	services := cli.Services{
+		Vehicle: service.NewVehicleService(vehicleRepo, domain.SystemClock, log),
+		ApiKey:  service.NewApiKeyService(apiKeyRepo, domain.SystemClock, log),
+		Health: service.NewHealthService(
+			postgresRepo.NewHealthRepositoryPostgres(postgresConn, log),
+			postgresRepo.SchemaVersion,
+			log,
+		),
+		Migrate:       func() error { return postgresRepo.Migrate(postgresConn) },
+		SchemaVersion: postgresRepo.SchemaVersion,
+	}
+	return services, closeConn, nil
+}
 
-	vehicleRepo := postgresRepo.NewVehicleRepositoryPostgres(postgresConn)
-	vehicleService := service.NewVehicleService(vehicleRepo)
-	rest.Start(vehicleService)
+// newVehicleRepository wraps the repository with an in-memory cache. A cache size of 0 disables the cache.
+func newVehicleRepository(
+	vehicleRepo ports.VehicleRepository,
+	config appConfig.CacheConfig,
+	log logger.Logger) ports.VehicleRepository {
+	if config.Size <= 0 {
+		return vehicleRepo
+	}
+	return cache.NewVehicleRepositoryCache(
+		vehicleRepo,
+		cache.NewLRUCache[[]domain.Vehicle](config.Size, config.TTL),
+		cache.NewLRUCache[[]domain.AutocompleteValue](config.Size, config.TTL),
+		config.VersionCheckInterval,
+		log,
+	)
 }
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gofipe"
+
+var (
+	HttpRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status.",
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	HttpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "status"},
+	)
+
+	ValidationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Number of requests rejected by the service layer validation, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	RepositoryQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Duration of the repository queries by method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"method"},
+	)
+
+	Panics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_panics_total",
+			Help:      "Number of panics recovered while serving HTTP requests, by route.",
+		},
+		[]string{"route"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(HttpRequests, HttpRequestDuration, ValidationFailures, RepositoryQueryDuration, Panics)
+}
+
+// RegisterDBStats exposes the connection pool statistics of the database.
+func RegisterDBStats(db *sql.DB, dbName string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ObserveRepositoryQuery records the duration of a repository method started at start.
+// It is meant to be deferred: defer metrics.ObserveRepositoryQuery("VehicleRepository.GetVehicle", time.Now())
+func ObserveRepositoryQuery(method string, start time.Time) {
+	RepositoryQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key. Implementations backed by an external store (e.g. Redis or Memcached)
+// can be plugged in to share cached results between replicas.
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	Set(key string, value V)
+	Purge()
+}
+
+type lruEntry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry once it holds size entries.
+// Entries older than ttl are treated as missing.
+type LRUCache[V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries *list.List
+	index   map[string]*list.Element
+	now     func() time.Time
+}
+
+func NewLRUCache[V any](size int, ttl time.Duration) *LRUCache[V] {
+	return &LRUCache[V]{
+		size:    size,
+		ttl:     ttl,
+		entries: list.New(),
+		index:   map[string]*list.Element{},
+		now:     time.Now,
+	}
+}
+
+func (c *LRUCache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.index[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[V])
+	if c.now().After(entry.expiresAt) {
+		c.remove(element)
+		return zero, false
+	}
+
+	c.entries.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *LRUCache[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.index[key]; ok {
+		entry := element.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.entries.MoveToFront(element)
+		return
+	}
+
+	c.index[key] = c.entries.PushFront(&lruEntry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+}
+
+func (c *LRUCache[V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries.Init()
+	c.index = map[string]*list.Element{}
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (c *LRUCache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LRUCache[V]) remove(element *list.Element) {
+	c.entries.Remove(element)
+	delete(c.index, element.Value.(*lruEntry[V]).key)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLRUCache(size int, ttl time.Duration) (*LRUCache[int], *time.Time) {
+	now := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	lru := NewLRUCache[int](size, ttl)
+	lru.now = func() time.Time { return now }
+	return lru, &now
+}
+
+func TestLRUCache_GetSet(t *testing.T) {
+	lru, _ := newTestLRUCache(2, time.Minute)
+
+	_, ok := lru.Get("a")
+	assert.False(t, ok)
+
+	lru.Set("a", 1)
+	got, ok := lru.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	lru.Set("a", 2)
+	got, _ = lru.Get("a")
+	assert.Equal(t, 2, got)
+	assert.Equal(t, 1, lru.Len())
+}
+
+func TestLRUCache_Eviction(t *testing.T) {
+	lru, _ := newTestLRUCache(2, time.Minute)
+
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	_, _ = lru.Get("a")
+	lru.Set("c", 3)
+
+	_, ok := lru.Get("b")
+	assert.False(t, ok, "least recently used entry must be evicted")
+	_, ok = lru.Get("a")
+	assert.True(t, ok)
+	_, ok = lru.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, lru.Len())
+}
+
+func TestLRUCache_Expiration(t *testing.T) {
+	lru, now := newTestLRUCache(2, time.Minute)
+
+	lru.Set("a", 1)
+	*now = now.Add(time.Minute)
+	_, ok := lru.Get("a")
+	assert.True(t, ok)
+
+	*now = now.Add(time.Second)
+	_, ok = lru.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, lru.Len())
+}
+
+func TestLRUCache_Purge(t *testing.T) {
+	lru, _ := newTestLRUCache(2, time.Minute)
+
+	lru.Set("a", 1)
+	lru.Set("b", 2)
+	lru.Purge()
+
+	_, ok := lru.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, lru.Len())
+}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// VehicleRepositoryCache decorates a ports.VehicleRepository caching the results of GetVehicle,
+// GetPriceAsOf and Autocomplete.
+// FIPE data only changes when a reference month is ingested, so the whole cache is purged whenever
+// vehicles are written through SaveVehicles. Writes made by other processes, such as gofipe ingest or
+// other replicas, are observed through the ingestion version of the decorated repository, read at most
+// once per versionCheckInterval: the cache is purged when the version changes. A result fetched while
+// the cache is purged is not cached, since it may predate the purge.
+type VehicleRepositoryCache struct {
+	vehicleRepo          ports.VehicleRepository
+	cache                Cache[[]domain.Vehicle]
+	autocompleteCache    Cache[[]domain.AutocompleteValue]
+	versionCheckInterval time.Duration
+	log                  logger.Logger
+	now                  func() time.Time
+
+	mu             sync.Mutex
+	version        int64
+	versionChecked time.Time
+	generation     uint64
+}
+
+// NewVehicleRepositoryCache caches the results of vehicleRepo. A versionCheckInterval of 0 never checks
+// the ingestion version, so writes made by other processes are only observed once the entries expire.
+func NewVehicleRepositoryCache(
+	vehicleRepo ports.VehicleRepository,
+	cache Cache[[]domain.Vehicle],
+	autocompleteCache Cache[[]domain.AutocompleteValue],
+	versionCheckInterval time.Duration,
+	log logger.Logger) *VehicleRepositoryCache {
+	return &VehicleRepositoryCache{
+		vehicleRepo:          vehicleRepo,
+		cache:                cache,
+		autocompleteCache:    autocompleteCache,
+		versionCheckInterval: versionCheckInterval,
+		log:                  log,
+		now:                  time.Now,
+	}
+}
+
+// GetVehicle returns the cached vehicles for the query or fetches them from the decorated repository.
+// Errors, including NotFoundError, are never cached.
+func (c *VehicleRepositoryCache) GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
+	c.checkVersion(ctx)
+	key := vehicleQueryKey(query)
+	vehicles, ok := c.cache.Get(key)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		logger.FromContext(ctx, c.log).Debug("Vehicle cache hit", logger.String("key", key))
+		return copyVehicles(vehicles), nil
+	}
+
+	generation := c.currentGeneration()
+	vehicles, err := c.vehicleRepo.GetVehicle(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.fill(generation, func() { c.cache.Set(key, copyVehicles(vehicles)) })
+	return vehicles, nil
+}
+
+// GetPriceAsOf returns the cached price or fetches it from the decorated repository. Lookups of any day
+// of a reference month share the entry of that month.
+func (c *VehicleRepositoryCache) GetPriceAsOf(
+	ctx context.Context,
+	fipeCode string,
+	yearModel string,
+	referenceMonth domain.ReferenceMonth,
+) (domain.Vehicle, *errs.AppError) {
+	c.checkVersion(ctx)
+	key := fmt.Sprintf("price_as_of:fipe_code=%q;year_model=%q;reference_month=%s", fipeCode, yearModel, referenceMonth)
+	vehicles, ok := c.cache.Get(key)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		logger.FromContext(ctx, c.log).Debug("Vehicle cache hit", logger.String("key", key))
+		return vehicles[0], nil
+	}
+
+	generation := c.currentGeneration()
+	vehicle, err := c.vehicleRepo.GetPriceAsOf(ctx, fipeCode, yearModel, referenceMonth)
+	if err != nil {
+		return domain.Vehicle{}, err
+	}
+
+	c.fill(generation, func() { c.cache.Set(key, []domain.Vehicle{vehicle}) })
+	return vehicle, nil
+}
+
+// LookupVehicles fetches the vehicles from the decorated repository. Batches are seldom repeated, so
+// they are not cached.
+func (c *VehicleRepositoryCache) LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.Vehicle, *errs.AppError) {
+	return c.vehicleRepo.LookupVehicles(ctx, lookups)
+}
+
+// SearchVehicles fetches the matches from the decorated repository. Free text is seldom repeated, so
+// searches are not cached.
+func (c *VehicleRepositoryCache) SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	return c.vehicleRepo.SearchVehicles(ctx, search)
+}
+
+// Autocomplete returns the cached values or fetches them from the decorated repository. A form asks for
+// the same few prefixes over and over, so empty results are cached too.
+func (c *VehicleRepositoryCache) Autocomplete(
+	ctx context.Context,
+	autocomplete domain.Autocomplete,
+) ([]domain.AutocompleteValue, *errs.AppError) {
+	c.checkVersion(ctx)
+	key := "autocomplete:" + autocomplete.String()
+	values, ok := c.autocompleteCache.Get(key)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("cache.hit", ok))
+	if ok {
+		logger.FromContext(ctx, c.log).Debug("Autocomplete cache hit", logger.String("key", key))
+		return append([]domain.AutocompleteValue(nil), values...), nil
+	}
+
+	generation := c.currentGeneration()
+	values, err := c.vehicleRepo.Autocomplete(ctx, autocomplete)
+	if err != nil {
+		return nil, err
+	}
+
+	c.fill(generation, func() { c.autocompleteCache.Set(key, append([]domain.AutocompleteValue(nil), values...)) })
+	return values, nil
+}
+
+// GetListingCandidates fetches the candidates from the decorated repository. Listings are seldom
+// repeated, so candidates are not cached.
+func (c *VehicleRepositoryCache) GetListingCandidates(ctx context.Context, listing domain.Listing) ([]domain.Vehicle, *errs.AppError) {
+	return c.vehicleRepo.GetListingCandidates(ctx, listing)
+}
+
+// SaveVehicles writes the vehicles through the decorated repository and invalidates the cache.
+func (c *VehicleRepositoryCache) SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	err := c.vehicleRepo.SaveVehicles(ctx, vehicles)
+	c.Invalidate()
+	return err
+}
+
+// GetIngestionVersion reads the version from the decorated repository. It is never cached.
+func (c *VehicleRepositoryCache) GetIngestionVersion(ctx context.Context) (int64, *errs.AppError) {
+	return c.vehicleRepo.GetIngestionVersion(ctx)
+}
+
+// checkVersion purges the cache if the ingestion version changed since it was last read. Only one caller
+// reads it per versionCheckInterval; the others go on with the cache as is. If the version cannot be
+// read, the cache is kept until the next check.
+func (c *VehicleRepositoryCache) checkVersion(ctx context.Context) {
+	if c.versionCheckInterval <= 0 {
+		return
+	}
+	c.mu.Lock()
+	now := c.now()
+	if !c.versionChecked.IsZero() && now.Sub(c.versionChecked) < c.versionCheckInterval {
+		c.mu.Unlock()
+		return
+	}
+	first := c.versionChecked.IsZero()
+	c.versionChecked = now
+	c.mu.Unlock()
+
+	version, err := c.vehicleRepo.GetIngestionVersion(ctx)
+	if err != nil {
+		logger.FromContext(ctx, c.log).Error("Unable to read the ingestion version", logger.String("error", err.Message))
+		return
+	}
+
+	c.mu.Lock()
+	changed := !first && version != c.version
+	c.version = version
+	c.mu.Unlock()
+	if changed {
+		logger.FromContext(ctx, c.log).Info("Ingestion version changed", logger.Int("version", int(version)))
+		c.Invalidate()
+	}
+}
+
+// Invalidate drops every cached result and starts a new generation, so that results fetched before it
+// are not cached after it.
+func (c *VehicleRepositoryCache) Invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.cache.Purge()
+	c.autocompleteCache.Purge()
+	c.mu.Unlock()
+	c.log.Info("Vehicle cache invalidated")
+}
+
+// currentGeneration returns the generation to pass to fill once a result is fetched.
+func (c *VehicleRepositoryCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// fill caches a result by calling set, unless the cache was invalidated since generation was read.
+func (c *VehicleRepositoryCache) fill(generation uint64, set func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		set()
+	}
+}
+
+// vehicleQueryKey builds a cache key that is the same for equivalent queries. Filters are combined
+// with AND and their values with OR, so both are sorted; sorts keep their priority.
+func vehicleQueryKey(query domain.VehicleQuery) string {
+	where := make([]string, 0, len(query.Filters()))
+	for _, filter := range query.Filters() {
+		values := make([]string, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			values = append(values, fmt.Sprintf("%q", fmt.Sprint(value)))
+		}
+		sort.Strings(values)
+		where = append(where, fmt.Sprintf("%q=%s", filter.Field, strings.Join(values, "|")))
+	}
+	sort.Strings(where)
+
+	var match string
+	if node, ok := query.Match(); ok {
+		match = node.String()
+	}
+
+	orderBy := make([]string, 0, len(query.Sorts()))
+	for _, querySort := range query.Sorts() {
+		direction := "asc"
+		if querySort.Desc {
+			direction = "desc"
+		}
+		orderBy = append(orderBy, fmt.Sprintf("%q:%s", querySort.Field, direction))
+	}
+
+	fields := make([]string, 0, len(query.Fields()))
+	for _, field := range query.Fields() {
+		fields = append(fields, string(field))
+	}
+
+	return fmt.Sprintf("where=%s;match=%s;period=%s;order=%s;fields=%s;offset=%d;limit=%d",
+		strings.Join(where, ","),
+		match,
+		query.Period(),
+		strings.Join(orderBy, ","),
+		strings.Join(fields, ","),
+		query.Pagination().Offset,
+		query.Pagination().Limit,
+	)
+}
+
+// copyVehicles prevents callers from mutating the cached slice.
+func copyVehicles(vehicles []domain.Vehicle) []domain.Vehicle {
+	if vehicles == nil {
+		return nil
+	}
+	return append([]domain.Vehicle(nil), vehicles...)
+}
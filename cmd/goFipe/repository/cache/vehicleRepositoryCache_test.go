@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMockVehicleRepository(t *testing.T) (*mockPort.MockVehicleRepository, *gomock.Controller) {
+	ctrl := gomock.NewController(t)
+	mockVehicleRepository := mockPort.NewMockVehicleRepository(ctrl)
+	return mockVehicleRepository, ctrl
+}
+
+func TestVehicleRepositoryCache_GetVehicle(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	vehicles := domain.GetDomainVehiclesExamples()
+	query := domain.NewVehicleQueryBuilder().
+		Where("year", "2021").Where("month", "7").OrderBy("mean_value", true).MustBuild()
+	swappedQuery := domain.NewVehicleQueryBuilder().
+		Where("month", "7").Where("year", "2021").OrderBy("mean_value", true).MustBuild()
+
+	mockVehicleRepository.EXPECT().
+		GetVehicle(gomock.Any(), query).
+		Return([]domain.Vehicle{vehicles[2], vehicles[0]}, nil).
+		Times(1)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+
+	got, err := c.GetVehicle(context.Background(), query)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Vehicle{vehicles[2], vehicles[0]}, got)
+
+	got[0].MeanValue = 0
+	got, err = c.GetVehicle(context.Background(), swappedQuery)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Vehicle{vehicles[2], vehicles[0]}, got, "cached result must not be mutated by callers")
+}
+
+func TestVehicleRepositoryCache_GetVehicleErrorNotCached(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	query := domain.NewVehicleQueryBuilder().Where("fipe_code", "999999-9").MustBuild()
+
+	mockVehicleRepository.EXPECT().
+		GetVehicle(gomock.Any(), query).
+		Return(nil, errs.NewNotFoundError("Vehicles not found")).
+		Times(2)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+	for i := 0; i < 2; i++ {
+		got, err := c.GetVehicle(context.Background(), query)
+		assert.Nil(t, got)
+		assert.Equal(t, errs.NewNotFoundError("Vehicles not found"), err)
+	}
+}
+
+func TestVehicleRepositoryCache_SaveVehiclesInvalidates(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	vehicles := domain.GetDomainVehiclesExamples()
+	query := domain.NewVehicleQueryBuilder().MustBuild()
+
+	gomock.InOrder(
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).Return(vehicles[:1], nil),
+		mockVehicleRepository.EXPECT().SaveVehicles(gomock.Any(), vehicles[1:]).Return(nil),
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).Return(vehicles, nil),
+	)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+
+	got, _ := c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles[:1], got)
+	assert.Nil(t, c.SaveVehicles(context.Background(), vehicles[1:]))
+	got, _ = c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles, got)
+}
+
+func TestVehicleRepositoryCache_InvalidateDuringFetch(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	vehicles := domain.GetDomainVehiclesExamples()
+	query := domain.NewVehicleQueryBuilder().MustBuild()
+	fetching, release := make(chan struct{}), make(chan struct{})
+
+	gomock.InOrder(
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).
+			DoAndReturn(func(context.Context, domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
+				close(fetching)
+				<-release
+				return vehicles[:1], nil
+			}),
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).Return(vehicles, nil),
+	)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+
+	stale := make(chan []domain.Vehicle)
+	go func() {
+		got, _ := c.GetVehicle(context.Background(), query)
+		stale <- got
+	}()
+	<-fetching
+	c.Invalidate()
+	close(release)
+	assert.Equal(t, vehicles[:1], <-stale, "the fetched result is still returned")
+
+	got, _ := c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles, got, "the result fetched before the purge was not cached")
+}
+
+func TestVehicleRepositoryCache_IngestionVersionInvalidates(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	vehicles := domain.GetDomainVehiclesExamples()
+	query := domain.NewVehicleQueryBuilder().MustBuild()
+
+	gomock.InOrder(
+		mockVehicleRepository.EXPECT().GetIngestionVersion(gomock.Any()).Return(int64(1), nil),
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).Return(vehicles[:1], nil),
+		mockVehicleRepository.EXPECT().GetIngestionVersion(gomock.Any()).Return(int64(1), nil),
+		mockVehicleRepository.EXPECT().GetIngestionVersion(gomock.Any()).Return(int64(2), nil),
+		mockVehicleRepository.EXPECT().GetVehicle(gomock.Any(), query).Return(vehicles, nil),
+		mockVehicleRepository.EXPECT().GetIngestionVersion(gomock.Any()).
+			Return(int64(0), errs.NewUnexpectedError("Unexpected database error")),
+	)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), time.Second, logger.NewNop())
+	now := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	got, _ := c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles[:1], got)
+	got, _ = c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles[:1], got, "version not read again within the interval")
+
+	now = now.Add(time.Second)
+	got, _ = c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles[:1], got, "same version")
+
+	now = now.Add(time.Second)
+	got, _ = c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles, got, "another process ingested vehicles")
+
+	now = now.Add(time.Second)
+	got, _ = c.GetVehicle(context.Background(), query)
+	assert.Equal(t, vehicles, got, "cache kept when the version cannot be read")
+}
+
+func Test_vehicleQueryKey(t *testing.T) {
+	build := func() *domain.VehicleQueryBuilder { return domain.NewVehicleQueryBuilder() }
+
+	assert.Equal(t,
+		vehicleQueryKey(build().Where("year", "2021").Where("month", "7").MustBuild()),
+		vehicleQueryKey(build().Where("month", "7").Where("year", "2021").MustBuild()),
+	)
+	assert.Equal(t,
+		vehicleQueryKey(build().Where("year", "2020").Where("year", "2021").MustBuild()),
+		vehicleQueryKey(build().Where("year", "2021").Where("year", "2020").MustBuild()),
+	)
+	assert.NotEqual(t,
+		vehicleQueryKey(build().OrderBy("year", true).OrderBy("month", false).MustBuild()),
+		vehicleQueryKey(build().OrderBy("month", false).OrderBy("year", true).MustBuild()),
+	)
+	assert.NotEqual(t,
+		vehicleQueryKey(build().MustBuild()),
+		vehicleQueryKey(build().Page(10, 10).MustBuild()),
+	)
+	assert.NotEqual(t,
+		vehicleQueryKey(build().MustBuild()),
+		vehicleQueryKey(build().Select("year").MustBuild()),
+	)
+	assert.NotEqual(t,
+		vehicleQueryKey(build().Period("2021-01", "").MustBuild()),
+		vehicleQueryKey(build().Period("", "2021-01").MustBuild()),
+	)
+	assert.NotEqual(t,
+		vehicleQueryKey(build().Match(domain.Compare("brand", "eq", "Fiat")).MustBuild()),
+		vehicleQueryKey(build().Match(domain.Not(domain.Compare("brand", "eq", "Fiat"))).MustBuild()),
+	)
+	assert.Equal(t,
+		`where="month"="7","year"="2021";match=;period=..;order="year":desc;`+
+			`fields=year,month,fipe_code,brand,model,year_model,authentication,mean_value;offset=0;limit=10`,
+		vehicleQueryKey(build().Where("year", "2021").Where("month", "7").OrderBy("year", true).MustBuild()),
+	)
+}
+
+func TestVehicleRepositoryCache_GetPriceAsOf(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	vehicles := domain.GetDomainVehiclesExamples()
+	july2021, _ := domain.NewReferenceMonth(2021, 7)
+	gomock.InOrder(
+		mockVehicleRepository.EXPECT().
+			GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", july2021).
+			Return(vehicles[2], nil).
+			Times(1),
+		mockVehicleRepository.EXPECT().
+			GetPriceAsOf(gomock.Any(), "222222-2", "1992 Gasolina", july2021).
+			Return(domain.Vehicle{}, errs.NewNotFoundError("Price not found")).
+			Times(2),
+	)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+
+	for i := 0; i < 2; i++ {
+		got, err := c.GetPriceAsOf(context.Background(), "222222-2", "1991 Gasolina", july2021)
+		assert.Nil(t, err)
+		assert.Equal(t, vehicles[2], got)
+
+		got, err = c.GetPriceAsOf(context.Background(), "222222-2", "1992 Gasolina", july2021)
+		assert.Equal(t, errs.NewNotFoundError("Price not found"), err)
+		assert.Equal(t, domain.Vehicle{}, got)
+	}
+}
+
+func TestVehicleRepositoryCache_Autocomplete(t *testing.T) {
+	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+	t.Cleanup(ctrl.Finish)
+
+	autocomplete, _ := domain.NewAutocomplete("model", "Gol", "VW - VolksWagen", "", 10)
+	values := []domain.AutocompleteValue{{Value: "Gol 1.0", Count: 3}, {Value: "Gol 1.6", Count: 2}}
+	gomock.InOrder(
+		mockVehicleRepository.EXPECT().Autocomplete(gomock.Any(), autocomplete).
+			Return(append([]domain.AutocompleteValue(nil), values...), nil).
+			Times(1),
+		mockVehicleRepository.EXPECT().SaveVehicles(gomock.Any(), gomock.Any()).Return(nil),
+		mockVehicleRepository.EXPECT().Autocomplete(gomock.Any(), autocomplete).Return(values[:1], nil),
+	)
+
+	c := NewVehicleRepositoryCache(mockVehicleRepository, NewLRUCache[[]domain.Vehicle](10, time.Minute),
+		NewLRUCache[[]domain.AutocompleteValue](10, time.Minute), 0, logger.NewNop())
+
+	for i := 0; i < 2; i++ {
+		got, err := c.Autocomplete(context.Background(), autocomplete)
+		assert.Nil(t, err)
+		assert.Equal(t, values, got, "cached result must not be mutated by callers")
+		got[0].Count = 0
+	}
+
+	assert.Nil(t, c.SaveVehicles(context.Background(), nil))
+	got, err := c.Autocomplete(context.Background(), autocomplete)
+	assert.Nil(t, err)
+	assert.Equal(t, values[:1], got)
+}
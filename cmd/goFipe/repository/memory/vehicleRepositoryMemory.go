@@ -0,0 +1,330 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+)
+
+// VehicleRepositoryMemory keeps the vehicles in memory, for running without a database. Every query scans
+// the whole catalog and the text search ranks it with domain.RankVehicles instead of a text index, so it
+// suits small catalogs such as the ones of tests and demos.
+type VehicleRepositoryMemory struct {
+	mu       sync.RWMutex
+	vehicles []domain.Vehicle
+	version  int64
+}
+
+func NewVehicleRepositoryMemory() *VehicleRepositoryMemory {
+	return &VehicleRepositoryMemory{}
+}
+
+// GetVehicle retrieves the vehicles matching the query, returning a NotFoundError if there are none.
+// Vehicles are in the order they were first saved unless the query sorts them.
+func (v *VehicleRepositoryMemory) GetVehicle(_ context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var vehicles []domain.Vehicle
+	for _, vehicle := range v.vehicles {
+		if matchesQuery(vehicle, query) {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	slices.SortStableFunc(vehicles, func(a domain.Vehicle, b domain.Vehicle) int {
+		for _, sort := range query.Sorts() {
+			byField := compareValues(fieldValue(a, sort.Field), fieldValue(b, sort.Field))
+			if sort.Desc {
+				byField = -byField
+			}
+			if byField != 0 {
+				return byField
+			}
+		}
+		return 0
+	})
+
+	pagination := query.Pagination()
+	start := min(pagination.Offset, len(vehicles))
+	end := min(start+pagination.Limit, len(vehicles))
+	if start == end {
+		return nil, errs.NewNotFoundError("Vehicles not found")
+	}
+	page := make([]domain.Vehicle, 0, end-start)
+	for _, vehicle := range vehicles[start:end] {
+		page = append(page, selectFields(vehicle, query.Fields()))
+	}
+	return page, nil
+}
+
+// GetPriceAsOf retrieves the vehicle of the fipe code and year model in the latest reference month on or
+// before referenceMonth, returning a NotFoundError if there is none.
+func (v *VehicleRepositoryMemory) GetPriceAsOf(
+	_ context.Context,
+	fipeCode string,
+	yearModel string,
+	referenceMonth domain.ReferenceMonth,
+) (domain.Vehicle, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var latest domain.Vehicle
+	var found bool
+	for _, vehicle := range v.vehicles {
+		if vehicle.FipeCode != fipeCode || vehicle.YearModel != yearModel || vehicle.ReferenceMonth().After(referenceMonth) {
+			continue
+		}
+		if !found || vehicle.ReferenceMonth().After(latest.ReferenceMonth()) {
+			latest, found = vehicle, true
+		}
+	}
+	if !found {
+		return domain.Vehicle{}, errs.NewNotFoundError("Price not found")
+	}
+	return latest, nil
+}
+
+// LookupVehicles retrieves the vehicles matching the fipe code, year model and reference month of any of
+// the lookups. Lookups without a match are simply absent from the result.
+func (v *VehicleRepositoryMemory) LookupVehicles(_ context.Context, lookups []domain.VehicleLookup) ([]domain.Vehicle, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var vehicles []domain.Vehicle
+	for _, vehicle := range v.vehicles {
+		if slices.ContainsFunc(lookups, func(lookup domain.VehicleLookup) bool {
+			return vehicle.FipeCode == lookup.FipeCode && vehicle.YearModel == lookup.YearModel &&
+				vehicle.ReferenceMonth() == lookup.ReferenceMonth
+		}) {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	return vehicles, nil
+}
+
+// SearchVehicles ranks the vehicles with domain.RankVehicles, returning a NotFoundError if none matches
+// the search.
+func (v *VehicleRepositoryMemory) SearchVehicles(_ context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	matches := domain.RankVehicles(v.vehicles, search)
+	if len(matches) == 0 {
+		return nil, errs.NewNotFoundError("Vehicles not found")
+	}
+	return matches, nil
+}
+
+// Autocomplete retrieves the distinct values of the field whose folded value starts with the prefix,
+// counting the fipe codes of each value, in alphabetical order. Brand and model narrow the values by
+// equality.
+func (v *VehicleRepositoryMemory) Autocomplete(
+	_ context.Context,
+	autocomplete domain.Autocomplete,
+) ([]domain.AutocompleteValue, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	fipeCodes := map[string]map[string]bool{}
+	for _, vehicle := range v.vehicles {
+		value := fieldValue(vehicle, autocomplete.Field()).(string)
+		if !strings.HasPrefix(domain.FoldText(value), autocomplete.Prefix()) ||
+			(autocomplete.Brand() != "" && vehicle.Brand != autocomplete.Brand()) ||
+			(autocomplete.Model() != "" && vehicle.Model != autocomplete.Model()) {
+			continue
+		}
+		if fipeCodes[value] == nil {
+			fipeCodes[value] = map[string]bool{}
+		}
+		fipeCodes[value][vehicle.FipeCode] = true
+	}
+
+	values := make([]domain.AutocompleteValue, 0, len(fipeCodes))
+	for value, codes := range fipeCodes {
+		values = append(values, domain.AutocompleteValue{Value: value, Count: len(codes)})
+	}
+	slices.SortFunc(values, func(a domain.AutocompleteValue, b domain.AutocompleteValue) int {
+		return cmp.Compare(a.Value, b.Value)
+	})
+	return values[:min(autocomplete.Limit(), len(values))], nil
+}
+
+// GetListingCandidates retrieves the latest reference month of at most domain.MaxListingCandidates fipe
+// codes and year models whose search text has any word of the listing, most confident first.
+func (v *VehicleRepositoryMemory) GetListingCandidates(_ context.Context, listing domain.Listing) ([]domain.Vehicle, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var candidates []domain.Vehicle
+	for _, vehicle := range v.vehicles {
+		searchText := domain.VehicleSearchText(vehicle)
+		if slices.ContainsFunc(listing.Words(), func(word string) bool { return strings.Contains(searchText, word) }) {
+			candidates = append(candidates, vehicle)
+		}
+	}
+
+	matches := domain.MatchListing(listing, candidates, domain.MaxListingCandidates)
+	vehicles := make([]domain.Vehicle, 0, len(matches))
+	for _, match := range matches {
+		vehicles = append(vehicles, match.Vehicle)
+	}
+	return vehicles, nil
+}
+
+// SaveVehicles stores the vehicles, replacing the one already stored for the fipe code, year model and
+// reference month of each, and starts a new ingestion version.
+func (v *VehicleRepositoryMemory) SaveVehicles(_ context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	if len(vehicles) == 0 {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, vehicle := range vehicles {
+		index := slices.IndexFunc(v.vehicles, func(stored domain.Vehicle) bool {
+			return stored.FipeCode == vehicle.FipeCode && stored.YearModel == vehicle.YearModel &&
+				stored.Year == vehicle.Year && stored.Month == vehicle.Month
+		})
+		if index < 0 {
+			v.vehicles = append(v.vehicles, vehicle)
+		} else {
+			v.vehicles[index] = vehicle
+		}
+	}
+	v.version++
+	return nil
+}
+
+// GetIngestionVersion returns how many times vehicles were saved.
+func (v *VehicleRepositoryMemory) GetIngestionVersion(context.Context) (int64, *errs.AppError) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.version, nil
+}
+
+// matchesQuery reports whether vehicle matches every filter, the filter tree and the period of the query.
+func matchesQuery(vehicle domain.Vehicle, query domain.VehicleQuery) bool {
+	for _, filter := range query.Filters() {
+		if !slices.ContainsFunc(filter.Values, func(value any) bool {
+			return compareValues(fieldValue(vehicle, filter.Field), value) == 0
+		}) {
+			return false
+		}
+	}
+	if match, ok := query.Match(); ok && !matchesNode(vehicle, match) {
+		return false
+	}
+	return query.Period().Contains(vehicle.ReferenceMonth())
+}
+
+// matchesNode evaluates the filter tree like the Postgres repository compiles it.
+func matchesNode(vehicle domain.Vehicle, node domain.FilterNode) bool {
+	switch node.Kind {
+	case domain.FilterAnd:
+		for _, child := range node.Children {
+			if !matchesNode(vehicle, child) {
+				return false
+			}
+		}
+		return true
+	case domain.FilterOr:
+		for _, child := range node.Children {
+			if matchesNode(vehicle, child) {
+				return true
+			}
+		}
+		return false
+	case domain.FilterNot:
+		return !matchesNode(vehicle, node.Children[0])
+	}
+
+	condition := node.Condition
+	value := fieldValue(vehicle, condition.Field)
+	compared := compareValues(value, condition.Values[0])
+	switch condition.Operator {
+	case domain.OperatorNe:
+		return compared != 0
+	case domain.OperatorGt:
+		return compared > 0
+	case domain.OperatorGte:
+		return compared >= 0
+	case domain.OperatorLt:
+		return compared < 0
+	case domain.OperatorLte:
+		return compared <= 0
+	case domain.OperatorIn:
+		return slices.ContainsFunc(condition.Values, func(other any) bool { return compareValues(value, other) == 0 })
+	case domain.OperatorBetween:
+		return compared >= 0 && compareValues(value, condition.Values[1]) <= 0
+	default:
+		return compared == 0
+	}
+}
+
+// fieldValue returns the field of vehicle typed like the values of a domain.Filter: int for year and
+// month, float64 for mean_value and string for the other fields.
+func fieldValue(vehicle domain.Vehicle, field domain.VehicleField) any {
+	switch field {
+	case domain.VehicleFieldYear:
+		return vehicle.Year
+	case domain.VehicleFieldMonth:
+		return vehicle.Month
+	case domain.VehicleFieldMeanValue:
+		return float64(vehicle.MeanValue)
+	case domain.VehicleFieldFipeCode:
+		return vehicle.FipeCode
+	case domain.VehicleFieldBrand:
+		return vehicle.Brand
+	case domain.VehicleFieldModel:
+		return vehicle.Model
+	case domain.VehicleFieldYearModel:
+		return vehicle.YearModel
+	default:
+		return vehicle.Authentication
+	}
+}
+
+// compareValues compares two values of the same field. Mean values are compared as stored, in single
+// precision.
+func compareValues(a any, b any) int {
+	switch a := a.(type) {
+	case int:
+		return cmp.Compare(a, b.(int))
+	case float64:
+		return cmp.Compare(float32(a), float32(b.(float64)))
+	default:
+		return cmp.Compare(a.(string), b.(string))
+	}
+}
+
+// selectFields zeroes the fields of vehicle the query does not select, as the columns left out of a
+// Postgres select.
+func selectFields(vehicle domain.Vehicle, fields []domain.VehicleField) domain.Vehicle {
+	var selected domain.Vehicle
+	for _, field := range fields {
+		switch field {
+		case domain.VehicleFieldYear:
+			selected.Year = vehicle.Year
+		case domain.VehicleFieldMonth:
+			selected.Month = vehicle.Month
+		case domain.VehicleFieldFipeCode:
+			selected.FipeCode = vehicle.FipeCode
+		case domain.VehicleFieldBrand:
+			selected.Brand = vehicle.Brand
+		case domain.VehicleFieldModel:
+			selected.Model = vehicle.Model
+		case domain.VehicleFieldYearModel:
+			selected.YearModel = vehicle.YearModel
+		case domain.VehicleFieldAuthentication:
+			selected.Authentication = vehicle.Authentication
+		case domain.VehicleFieldMeanValue:
+			selected.MeanValue = vehicle.MeanValue
+		}
+	}
+	return selected
+}
@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRepository(t *testing.T, vehicles ...domain.Vehicle) *VehicleRepositoryMemory {
+	repository := NewVehicleRepositoryMemory()
+	assert.Nil(t, repository.SaveVehicles(context.Background(), vehicles))
+	return repository
+}
+
+func TestVehicleRepositoryMemory_GetVehicle(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	repository := getRepository(t, vehicles...)
+
+	tests := []struct {
+		name    string
+		query   domain.VehicleQuery
+		want    []domain.Vehicle
+		wantErr *errs.AppError
+	}{
+		{
+			name:  "All vehicles in the order they were saved",
+			query: domain.NewVehicleQueryBuilder().MustBuild(),
+			want:  vehicles,
+		},
+		{
+			name:  "Filters",
+			query: domain.NewVehicleQueryBuilder().Where("fipe_code", "222222-2").Where("month", "7").MustBuild(),
+			want:  []domain.Vehicle{vehicles[2]},
+		},
+		{
+			name: "Filter tree",
+			query: domain.NewVehicleQueryBuilder().Match(domain.Or(
+				domain.Compare("mean_value", "lt", "750"),
+				domain.And(domain.Compare("fipe_code", "eq", "222222-2"), domain.Not(domain.Compare("month", "eq", "6"))),
+			)).MustBuild(),
+			want: []domain.Vehicle{vehicles[0], vehicles[2]},
+		},
+		{
+			name:  "Between",
+			query: domain.NewVehicleQueryBuilder().Match(domain.Compare("mean_value", "between", "800", "801")).MustBuild(),
+			want:  []domain.Vehicle{vehicles[1], vehicles[2]},
+		},
+		{
+			name:  "Period",
+			query: domain.NewVehicleQueryBuilder().Period("2021-07", "2021-08").MustBuild(),
+			want:  []domain.Vehicle{vehicles[0], vehicles[2], vehicles[3]},
+		},
+		{
+			name:  "Sorted and paginated",
+			query: domain.NewVehicleQueryBuilder().OrderBy("mean_value", true).Page(1, 2).MustBuild(),
+			want:  []domain.Vehicle{vehicles[2], vehicles[1]},
+		},
+		{
+			name:  "Selected fields",
+			query: domain.NewVehicleQueryBuilder().Where("fipe_code", "111111-1").Select("brand", "mean_value").MustBuild(),
+			want:  []domain.Vehicle{{Brand: "Acura", MeanValue: 700}},
+		},
+		{
+			name:    "Not found",
+			query:   domain.NewVehicleQueryBuilder().Where("fipe_code", "999999-9").MustBuild(),
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
+		},
+		{
+			name:    "Offset past the vehicles",
+			query:   domain.NewVehicleQueryBuilder().Page(10, 2).MustBuild(),
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repository.GetVehicle(context.Background(), tt.query)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVehicleRepositoryMemory_GetPriceAsOf(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	repository := getRepository(t, vehicles...)
+
+	june, _ := domain.NewReferenceMonth(2021, 6)
+	december, _ := domain.NewReferenceMonth(2021, 12)
+	may, _ := domain.NewReferenceMonth(2021, 5)
+
+	got, err := repository.GetPriceAsOf(context.Background(), "222222-2", "1991 Gasolina", june)
+	assert.Nil(t, err)
+	assert.Equal(t, vehicles[1], got)
+
+	got, err = repository.GetPriceAsOf(context.Background(), "222222-2", "1991 Gasolina", december)
+	assert.Nil(t, err)
+	assert.Equal(t, vehicles[2], got)
+
+	_, err = repository.GetPriceAsOf(context.Background(), "222222-2", "1991 Gasolina", may)
+	assert.Equal(t, errs.NewNotFoundError("Price not found"), err)
+}
+
+func TestVehicleRepositoryMemory_SearchVehicles(t *testing.T) {
+	integra := domain.GetDomainVehiclesExamples()[0]
+	integraJune := integra
+	integraJune.Month = 6
+	integraGSR := domain.Vehicle{
+		Year: 2021, Month: 7, FipeCode: "111112-1", Brand: "Acura", Model: "Integra GSR 1.8", YearModel: "1997 Gasolina",
+	}
+	repository := getRepository(t, integraJune, integraGSR, domain.GetDomainVehiclesExamples()[1], integra)
+
+	tests := []struct {
+		name    string
+		text    string
+		offset  int
+		limit   int
+		want    []domain.VehicleMatch
+		wantErr *errs.AppError
+	}{
+		{
+			name:  "Ranked by score in the latest reference month",
+			text:  "integra gs",
+			limit: 10,
+			want:  []domain.VehicleMatch{{Vehicle: integra, Score: 1}, {Vehicle: integraGSR, Score: 0.875}},
+		},
+		{
+			name:   "Ties by fipe code",
+			text:   "integra",
+			offset: 1,
+			limit:  1,
+			want:   []domain.VehicleMatch{{Vehicle: integraGSR, Score: 1}},
+		},
+		{
+			name:    "No match",
+			text:    "volvo",
+			limit:   10,
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			search, _ := domain.NewTextSearch(tt.text, tt.offset, tt.limit)
+			got, err := repository.SearchVehicles(context.Background(), search)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVehicleRepositoryMemory_Autocomplete(t *testing.T) {
+	repository := getRepository(t, domain.GetDomainVehiclesExamples()...)
+
+	tests := []struct {
+		name   string
+		field  string
+		prefix string
+		brand  string
+		limit  int
+		want   []domain.AutocompleteValue
+	}{
+		{
+			name:  "Brands",
+			field: "brand",
+			limit: 10,
+			want:  []domain.AutocompleteValue{{Value: "Acura", Count: 1}, {Value: "Fiat", Count: 2}},
+		},
+		{
+			name:   "Prefix ignores case",
+			field:  "brand",
+			prefix: "FI",
+			limit:  10,
+			want:   []domain.AutocompleteValue{{Value: "Fiat", Count: 2}},
+		},
+		{
+			name:  "Narrowed by brand",
+			field: "model",
+			brand: "Acura",
+			limit: 10,
+			want:  []domain.AutocompleteValue{{Value: "Integra GS 1.8", Count: 1}},
+		},
+		{
+			name:  "Limited",
+			field: "brand",
+			limit: 1,
+			want:  []domain.AutocompleteValue{{Value: "Acura", Count: 1}},
+		},
+		{
+			name:   "No match",
+			field:  "brand",
+			prefix: "volvo",
+			limit:  10,
+			want:   []domain.AutocompleteValue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			autocomplete, _ := domain.NewAutocomplete(tt.field, tt.prefix, tt.brand, "", tt.limit)
+			got, err := repository.Autocomplete(context.Background(), autocomplete)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVehicleRepositoryMemory_GetListingCandidates(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	repository := getRepository(t, vehicles...)
+
+	listing, _ := domain.NewListing(domain.SystemClock, "fiat 147 1991")
+	got, err := repository.GetListingCandidates(context.Background(), listing)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Vehicle{vehicles[2], vehicles[3]}, got)
+}
+
+func TestVehicleRepositoryMemory_SaveVehicles(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	repository := getRepository(t, vehicles[:2]...)
+
+	updated := vehicles[1]
+	updated.MeanValue = 900
+	assert.Nil(t, repository.SaveVehicles(context.Background(), []domain.Vehicle{updated, vehicles[2]}))
+
+	lookups := []domain.VehicleLookup{
+		{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: vehicles[1].ReferenceMonth()},
+		{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: vehicles[2].ReferenceMonth()},
+		{FipeCode: "333333-3", YearModel: "1991 Gasolina", ReferenceMonth: vehicles[3].ReferenceMonth()},
+	}
+	got, err := repository.LookupVehicles(context.Background(), lookups)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Vehicle{updated, vehicles[2]}, got)
+
+	version, err := repository.GetIngestionVersion(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), version)
+}
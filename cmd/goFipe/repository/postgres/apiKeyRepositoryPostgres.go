@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ApiKeyRepositoryPostgres struct {
+	Conn *gorm.DB
+	log  logger.Logger
+}
+
+type ApiKey struct {
+	ID           uint   `gorm:"primaryKey"`
+	Name         string `gorm:"not null"`
+	Prefix       string `gorm:"index:idx_api_key_prefix"`
+	Hash         string `gorm:"uniqueIndex:idx_api_key_hash;not null"`
+	MonthlyQuota int
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID uint `gorm:"primaryKey;autoIncrement:false"`
+	Year     int  `gorm:"primaryKey;autoIncrement:false"`
+	Month    int  `gorm:"primaryKey;autoIncrement:false"`
+	Requests int  `gorm:"not null;default:0"`
+}
+
+// NewApiKeyRepositoryPostgres initializes a new instance of ApiKeyRepositoryPostgres with the given database connection.
+// It performs automatic migrations for the ApiKey and ApiKeyUsage models and panics if an error occurs during migration.
+func NewApiKeyRepositoryPostgres(conn *gorm.DB, log logger.Logger) *ApiKeyRepositoryPostgres {
+	err := conn.AutoMigrate(&ApiKey{}, &ApiKeyUsage{})
+	if err != nil {
+		panic(err)
+	}
+	return &ApiKeyRepositoryPostgres{Conn: conn, log: log}
+}
+
+// Create stores a new API key. The raw key is never stored, only its hash.
+func (a ApiKeyRepositoryPostgres) Create(ctx context.Context, apiKey domain.ApiKey) (domain.ApiKey, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.Create", time.Now())
+
+	model := fromDomainApiKey(apiKey)
+	if result := a.Conn.WithContext(ctx).Create(&model); result.Error != nil {
+		return domain.ApiKey{}, toAppError(ctx, a.log, result.Error)
+	}
+	return toDomainApiKey(model), nil
+}
+
+// GetByID returns the API key with the given id, or a NotFoundError.
+func (a ApiKeyRepositoryPostgres) GetByID(ctx context.Context, id uint) (domain.ApiKey, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.GetByID", time.Now())
+
+	return a.getFirst(ctx, a.Conn.WithContext(ctx).Where("id = ?", id))
+}
+
+// GetByHash returns the API key with the given hash, or a NotFoundError.
+func (a ApiKeyRepositoryPostgres) GetByHash(ctx context.Context, hash string) (domain.ApiKey, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.GetByHash", time.Now())
+
+	return a.getFirst(ctx, a.Conn.WithContext(ctx).Where("hash = ?", hash))
+}
+
+func (a ApiKeyRepositoryPostgres) getFirst(ctx context.Context, query *gorm.DB) (domain.ApiKey, *errs.AppError) {
+	var apiKey ApiKey
+	result := query.First(&apiKey)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return domain.ApiKey{}, errs.NewNotFoundError("Api key not found")
+		}
+		return domain.ApiKey{}, toAppError(ctx, a.log, result.Error)
+	}
+	return toDomainApiKey(apiKey), nil
+}
+
+// List returns every API key, including revoked ones, ordered by id.
+func (a ApiKeyRepositoryPostgres) List(ctx context.Context) ([]domain.ApiKey, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.List", time.Now())
+
+	var apiKeys []ApiKey
+	if result := a.Conn.WithContext(ctx).Order("id").Find(&apiKeys); result.Error != nil {
+		return nil, toAppError(ctx, a.log, result.Error)
+	}
+
+	domainApiKeys := make([]domain.ApiKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		domainApiKeys = append(domainApiKeys, toDomainApiKey(apiKey))
+	}
+	return domainApiKeys, nil
+}
+
+// Revoke marks the API key as revoked at the given time. Revoking an already revoked key is a no-op.
+func (a ApiKeyRepositoryPostgres) Revoke(ctx context.Context, id uint, revokedAt time.Time) *errs.AppError {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.Revoke", time.Now())
+
+	result := a.Conn.WithContext(ctx).Model(&ApiKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return toAppError(ctx, a.log, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if _, err := a.GetByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetUsage returns the number of requests made with the API key in the given month.
+// A month without requests returns a usage with zero requests.
+func (a ApiKeyRepositoryPostgres) GetUsage(ctx context.Context, id uint, year int, month int) (domain.ApiKeyUsage, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.GetUsage", time.Now())
+
+	usage := ApiKeyUsage{ApiKeyID: id, Year: year, Month: month}
+	result := a.Conn.WithContext(ctx).
+		Where("api_key_id = ? AND year = ? AND month = ?", id, year, month).
+		Limit(1).
+		Find(&usage)
+	if result.Error != nil {
+		return domain.ApiKeyUsage{}, toAppError(ctx, a.log, result.Error)
+	}
+	return toDomainApiKeyUsage(usage), nil
+}
+
+// IncrementUsage atomically adds one request to the API key usage in the given month and returns the
+// updated usage. With a monthlyQuota greater than 0, a usage that already reached it is left untouched
+// and false is returned: the check and the increment are a single conditional upsert, so concurrent
+// requests can never overshoot the quota.
+func (a ApiKeyRepositoryPostgres) IncrementUsage(
+	ctx context.Context,
+	id uint,
+	year int,
+	month int,
+	monthlyQuota int,
+) (domain.ApiKeyUsage, bool, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("ApiKeyRepository.IncrementUsage", time.Now())
+
+	onConflict := clause.OnConflict{
+		Columns: []clause.Column{{Name: "api_key_id"}, {Name: "year"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests": gorm.Expr("api_key_usages.requests + 1"),
+		}),
+	}
+	if monthlyQuota > 0 {
+		onConflict.Where = clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "api_key_usages.requests < ?", Vars: []any{monthlyQuota}},
+		}}
+	}
+
+	usage := ApiKeyUsage{ApiKeyID: id, Year: year, Month: month, Requests: 1}
+	result := a.Conn.WithContext(ctx).Clauses(onConflict, clause.Returning{}).Create(&usage)
+	if result.Error != nil {
+		return domain.ApiKeyUsage{}, false, toAppError(ctx, a.log, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ApiKeyUsage{}, false, nil
+	}
+	return toDomainApiKeyUsage(usage), true, nil
+}
+
+func fromDomainApiKey(apiKey domain.ApiKey) ApiKey {
+	return ApiKey{
+		ID:           apiKey.ID,
+		Name:         apiKey.Name,
+		Prefix:       apiKey.Prefix,
+		Hash:         apiKey.Hash,
+		MonthlyQuota: apiKey.MonthlyQuota,
+		CreatedAt:    apiKey.CreatedAt,
+		RevokedAt:    apiKey.RevokedAt,
+	}
+}
+
+func toDomainApiKey(apiKey ApiKey) domain.ApiKey {
+	return domain.ApiKey{
+		ID:           apiKey.ID,
+		Name:         apiKey.Name,
+		Prefix:       apiKey.Prefix,
+		Hash:         apiKey.Hash,
+		MonthlyQuota: apiKey.MonthlyQuota,
+		CreatedAt:    apiKey.CreatedAt,
+		RevokedAt:    apiKey.RevokedAt,
+	}
+}
+
+func toDomainApiKeyUsage(usage ApiKeyUsage) domain.ApiKeyUsage {
+	return domain.ApiKeyUsage{
+		ApiKeyID: usage.ApiKeyID,
+		Year:     usage.Year,
+		Month:    usage.Month,
+		Requests: usage.Requests,
+	}
+}
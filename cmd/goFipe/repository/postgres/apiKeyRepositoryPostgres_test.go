@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyRepositoryPostgres(t *testing.T) {
+	conn := getPostgresConnection(t)
+	repo := NewApiKeyRepositoryPostgres(conn, logger.NewNop())
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, domain.ApiKey{
+		Name:         "Dealer",
+		Prefix:       "gf_01234567",
+		Hash:         domain.HashApiKey("gf_0123456789"),
+		MonthlyQuota: 10,
+		CreatedAt:    time.Now().UTC(),
+	})
+	assert.Nil(t, err)
+	assert.NotZero(t, created.ID)
+
+	got, err := repo.GetByHash(ctx, domain.HashApiKey("gf_0123456789"))
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, got.ID)
+
+	_, err = repo.GetByHash(ctx, domain.HashApiKey("gf_unknown"))
+	assert.Equal(t, errs.NewNotFoundError("Api key not found"), err)
+
+	usage, err := repo.GetUsage(ctx, created.ID, 2021, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, domain.ApiKeyUsage{ApiKeyID: created.ID, Year: 2021, Month: 7, Requests: 0}, usage)
+
+	for i := 1; i <= 3; i++ {
+		var accounted bool
+		usage, accounted, err = repo.IncrementUsage(ctx, created.ID, 2021, 7, 3)
+		assert.Nil(t, err)
+		assert.True(t, accounted)
+		assert.Equal(t, i, usage.Requests)
+	}
+	_, accounted, err := repo.IncrementUsage(ctx, created.ID, 2021, 7, 3)
+	assert.Nil(t, err)
+	assert.False(t, accounted, "quota reached")
+	usage, accounted, err = repo.IncrementUsage(ctx, created.ID, 2021, 7, 0)
+	assert.Nil(t, err)
+	assert.True(t, accounted, "unlimited quota")
+	assert.Equal(t, 4, usage.Requests)
+
+	assert.Nil(t, repo.Revoke(ctx, created.ID, time.Now().UTC()))
+	revoked, err := repo.GetByID(ctx, created.ID)
+	assert.Nil(t, err)
+	assert.True(t, revoked.IsRevoked())
+
+	assert.Equal(t, errs.NewNotFoundError("Api key not found"), repo.Revoke(ctx, created.ID+1000, time.Now().UTC()))
+}
+
+func TestApiKeyRepositoryPostgres_IncrementUsageConcurrently(t *testing.T) {
+	conn := getPostgresConnection(t)
+	repo := NewApiKeyRepositoryPostgres(conn, logger.NewNop())
+	ctx := context.Background()
+
+	const monthlyQuota, requests = 5, 50
+	created, err := repo.Create(ctx, domain.ApiKey{
+		Name:         "Concurrent dealer",
+		Prefix:       "gf_76543210",
+		Hash:         domain.HashApiKey("gf_7654321098"),
+		MonthlyQuota: monthlyQuota,
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var accountedRequests atomic.Int32
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, accounted, errIncrement := repo.IncrementUsage(ctx, created.ID, 2021, 7, monthlyQuota)
+			assert.Nil(t, errIncrement)
+			if accounted {
+				accountedRequests.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(monthlyQuota), accountedRequests.Load())
+	usage, err := repo.GetUsage(ctx, created.ID, 2021, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, monthlyQuota, usage.Requests)
+}
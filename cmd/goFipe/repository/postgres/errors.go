@@ -0,0 +1,27 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+// toAppError converts a database error into an AppError. A query interrupted by the request deadline
+// becomes a GatewayTimeoutError; the driver does not always wrap the context error, so the context
+// itself is checked too. The error is logged with the request-scoped logger when ctx carries one.
+func toAppError(ctx context.Context, log logger.Logger, err error) *errs.AppError {
+	log = logger.FromContext(ctx, log)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		log.Info("Database query timed out", logger.Err(err))
+		return errs.NewGatewayTimeoutError("Database query timed out")
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		log.Info("Database query canceled", logger.Err(err))
+		return errs.NewUnexpectedError("Request canceled")
+	default:
+		log.Error("Unexpected database error", logger.Err(err))
+		return errs.NewUnexpectedError("Unexpected database error")
+	}
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"gorm.io/gorm"
+)
+
+type HealthRepositoryPostgres struct {
+	Conn *gorm.DB
+	log  logger.Logger
+}
+
+func NewHealthRepositoryPostgres(conn *gorm.DB, log logger.Logger) *HealthRepositoryPostgres {
+	return &HealthRepositoryPostgres{Conn: conn, log: log}
+}
+
+// Ping verifies that a connection to the database can be established.
+func (h HealthRepositoryPostgres) Ping(ctx context.Context) *errs.AppError {
+	defer metrics.ObserveRepositoryQuery("HealthRepository.Ping", time.Now())
+
+	sqlDB, err := h.Conn.DB()
+	if err != nil {
+		return toAppError(ctx, h.log, err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return toAppError(ctx, h.log, err)
+	}
+	return nil
+}
+
+// GetSchemaVersion returns the latest schema version recorded by Migrate.
+func (h HealthRepositoryPostgres) GetSchemaVersion(ctx context.Context) (int, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("HealthRepository.GetSchemaVersion", time.Now())
+
+	var version *int
+	result := h.Conn.WithContext(ctx).Model(&SchemaMigration{}).Select("MAX(version)").Scan(&version)
+	if result.Error != nil {
+		return 0, toAppError(ctx, h.log, result.Error)
+	}
+	if version == nil {
+		return 0, errs.NewNotFoundError("Schema version not found")
+	}
+	return *version, nil
+}
+
+// GetLatestReferenceMonth returns the most recent reference month loaded in the vehicles table,
+// or a NotFoundError when no data is loaded.
+func (h HealthRepositoryPostgres) GetLatestReferenceMonth(ctx context.Context) (int, int, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("HealthRepository.GetLatestReferenceMonth", time.Now())
+
+	var vehicles []Vehicle
+	result := h.Conn.WithContext(ctx).
+		Select("year", "month").
+		Order("year DESC").
+		Order("month DESC").
+		Limit(1).
+		Find(&vehicles)
+	if result.Error != nil {
+		return 0, 0, toAppError(ctx, h.log, result.Error)
+	}
+	if len(vehicles) == 0 {
+		return 0, 0, errs.NewNotFoundError("No reference month loaded")
+	}
+	return vehicles[0].Year, vehicles[0].Month, nil
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthRepositoryPostgres(t *testing.T) {
+	conn := getPostgresConnection(t)
+	ctx := context.Background()
+
+	assert.Nil(t, Migrate(conn))
+	assert.Nil(t, Migrate(conn), "migrating twice must be a no-op")
+
+	repo := NewHealthRepositoryPostgres(conn, logger.NewNop())
+	assert.Nil(t, repo.Ping(ctx))
+	version, err := repo.GetSchemaVersion(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, SchemaVersion, version)
+
+	// The vehicles are inserted in a transaction that is rolled back, so other tests see an unchanged table.
+	tx := conn.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	assert.Nil(t, tx.Where("1 = 1").Delete(&Vehicle{}).Error)
+
+	txRepo := NewHealthRepositoryPostgres(tx, logger.NewNop())
+	_, _, err = txRepo.GetLatestReferenceMonth(ctx)
+	assert.Equal(t, errs.NewNotFoundError("No reference month loaded"), err)
+
+	assert.Nil(t, tx.Create(&[]Vehicle{
+		{Year: 2021, Month: 12, FipeCode: "001004-9"},
+		{Year: 2022, Month: 1, FipeCode: "001004-9"},
+		{Year: 2021, Month: 7, FipeCode: "001004-9"},
+	}).Error)
+	year, month, err := txRepo.GetLatestReferenceMonth(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2022, year)
+	assert.Equal(t, 1, month)
+}
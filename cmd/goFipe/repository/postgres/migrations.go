@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SchemaVersion is the database schema version expected by this build. Bump it whenever a model changes,
+// so readiness fails on instances whose database was not migrated yet.
+const SchemaVersion = 5
+
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	AppliedAt time.Time
+}
+
+// migrationLockID is the key of the advisory lock Migrate holds, so that instances migrating at once
+// apply each migration only once.
+const migrationLockID = 7_341_203_511
+
+// migration is a change of the schema that AutoMigrate cannot make, applied once, when the database is
+// migrated from a version older than Version.
+type migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+var migrations = []migration{
+	{Version: 2, Name: "vehicle search", Statements: vehicleSearchStatements},
+	{Version: 3, Name: "vehicle autocomplete", Statements: vehicleAutocompleteStatements},
+	{Version: 4, Name: "vehicle reference index", Statements: vehicleReferenceStatements},
+}
+
+// Migrate creates or updates the tables of every repository, applies the migrations newer than the latest
+// applied schema version and records each of them and SchemaVersion as applied. It runs in a single
+// transaction, so a failed migration leaves the schema untouched.
+func Migrate(conn *gorm.DB) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+			return fmt.Errorf("locking migrations: %w", err)
+		}
+		if err := tx.AutoMigrate(&Vehicle{}, &VehicleIngestion{}, &ApiKey{}, &ApiKeyUsage{}, &SchemaMigration{}); err != nil {
+			return fmt.Errorf("migrating tables: %w", err)
+		}
+
+		var applied int
+		if err := tx.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&applied).Error; err != nil {
+			return fmt.Errorf("reading the schema version: %w", err)
+		}
+		for _, m := range migrations {
+			if m.Version <= applied {
+				continue
+			}
+			for _, statement := range m.Statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("migrating %s: %w", m.Name, err)
+				}
+			}
+			if err := recordSchemaVersion(tx, m.Version); err != nil {
+				return err
+			}
+		}
+		return recordSchemaVersion(tx, SchemaVersion)
+	})
+}
+
+func recordSchemaVersion(tx *gorm.DB, version int) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&SchemaMigration{Version: version, AppliedAt: time.Now().UTC()})
+	if result.Error != nil {
+		return fmt.Errorf("recording schema version %d: %w", version, result.Error)
+	}
+	return nil
+}
+
+// vehicleReferenceStatements add the unique index SaveVehicles upserts on, so re-ingesting a reference
+// month updates its rows instead of duplicating them. The duplicates ingested before the index are
+// dropped first, keeping the last inserted row.
+var vehicleReferenceStatements = []string{
+	`DELETE FROM vehicles AS older USING vehicles AS newer
+		WHERE older.ctid < newer.ctid
+		AND older.fipe_code = newer.fipe_code AND older.year_model = newer.year_model
+		AND older.year = newer.year AND older.month = newer.month`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicles_reference ON vehicles (fipe_code, year_model, year, month)`,
+}
+
+// vehicleSearchStatements add the search_text column of the vehicles, folded like domain.VehicleSearchText,
+// and its trigram index. The column is generated by the database, so it is never written by the models.
+var vehicleSearchStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	fmt.Sprintf(`ALTER TABLE vehicles ADD COLUMN IF NOT EXISTS search_text text GENERATED ALWAYS AS (
+		lower(translate(
+			coalesce(brand, '') || ' ' || coalesce(vehicle_model, '') || ' ' || coalesce(year_model, ''),
+			'%s', '%s'
+		))
+	) STORED`, domain.AccentedLetters, domain.UnaccentedLetters),
+	`CREATE INDEX IF NOT EXISTS idx_vehicles_search_text ON vehicles USING gin (search_text gin_trgm_ops)`,
+}
+
+// vehicleAutocompleteStatements add the prefix indexes of the autocompleted columns, on the same folded
+// expression Autocomplete matches. text_pattern_ops lets LIKE 'prefix%' use them whatever the collation.
+var vehicleAutocompleteStatements = []string{
+	fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_vehicles_brand_prefix ON vehicles (%s text_pattern_ops)`,
+		foldedColumn(vehicleColumns[domain.VehicleFieldBrand])),
+	fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_vehicles_vehicle_model_prefix ON vehicles (%s text_pattern_ops)`,
+		foldedColumn(vehicleColumns[domain.VehicleFieldModel])),
+	fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_vehicles_year_model_prefix ON vehicles (%s text_pattern_ops)`,
+		foldedColumn(vehicleColumns[domain.VehicleFieldYearModel])),
+}
+
+// foldedColumn folds the accents and the case of column like domain.FoldText. Queries must match the
+// expression of the index verbatim to use it.
+func foldedColumn(column string) string {
+	return fmt.Sprintf(`lower(translate(%s, '%s', '%s'))`, column, domain.AccentedLetters, domain.UnaccentedLetters)
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate_AppliesMigrationsOnce(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []int
+	assert.Nil(t, conn.Model(&SchemaMigration{}).Order("version").Pluck("version", &versions).Error)
+	for _, m := range migrations {
+		assert.Contains(t, versions, m.Version)
+	}
+	assert.Contains(t, versions, SchemaVersion)
+
+	// The index is dropped in a transaction that is rolled back, so other tests see an unchanged schema.
+	tx := conn.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	assert.Nil(t, tx.Exec("DROP INDEX idx_vehicles_reference").Error)
+	assert.Nil(t, Migrate(tx))
+	var indexes int64
+	assert.Nil(t, tx.Raw("SELECT COUNT(*) FROM pg_indexes WHERE indexname = 'idx_vehicles_reference'").Scan(&indexes).Error)
+	assert.Equal(t, int64(0), indexes, "an applied migration is not run again")
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/raffops/gofipe/cmd/goFipe/tracing"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// startSpan creates a client span for a repository method.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.StartClient(ctx, name, semconv.DBSystemPostgreSQL)
+}
+
+// recordStatement adds the executed SQL statement, with placeholders instead of values, to the span.
+func recordStatement(span trace.Span, result *gorm.DB) {
+	if result.Statement != nil {
+		span.SetAttributes(semconv.DBStatement(result.Statement.SQL.String()))
+	}
+	if result.Error != nil {
+		span.SetStatus(codes.Error, result.Error.Error())
+	}
+}
@@ -1,20 +1,31 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"reflect"
-	"slices"
 	"strings"
+	"time"
 
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+const (
+	saveBatchSize = 1000
+	// minFuzzyTermLength is the length from which a search term also matches words of similar trigrams,
+	// e.g. intergra for integra. Shorter terms must be contained in the search text.
+	minFuzzyTermLength = 4
+)
+
 type VehicleRepositoryPostgres struct {
 	Conn *gorm.DB
+	log  logger.Logger
 }
 
 type Vehicle struct {
@@ -28,31 +39,45 @@ type Vehicle struct {
 	MeanValue      float32 `json:"mean_value,omitempty"`
 }
 
+// VehicleIngestion records every SaveVehicles. Its latest id is the ingestion version the caches of every
+// process compare, so they notice the vehicles changed whichever process saved them.
+type VehicleIngestion struct {
+	ID       uint `gorm:"primaryKey"`
+	Vehicles int
+	SavedAt  time.Time
+}
+
 // NewVehicleRepositoryPostgres initializes a new instance of VehicleRepositoryPostgres with the given database connection.
 // It performs automatic migrations for the Vehicle model and panics if an error occurs during migration.
 // Returns a pointer to the VehicleRepositoryPostgres instance.
-func NewVehicleRepositoryPostgres(conn *gorm.DB) *VehicleRepositoryPostgres {
-	err := conn.AutoMigrate(&Vehicle{})
+func NewVehicleRepositoryPostgres(conn *gorm.DB, log logger.Logger) *VehicleRepositoryPostgres {
+	err := conn.AutoMigrate(&Vehicle{}, &VehicleIngestion{})
 	if err != nil {
 		panic(err)
 	}
-	return &VehicleRepositoryPostgres{Conn: conn}
+	return &VehicleRepositoryPostgres{Conn: conn, log: log}
 }
 
-// GetVehicle retrieves vehicles from the database based on the given conditions, order by specifications and pagination settings.
-// It validates the pagination parameters and returns an error if they are invalid.
-// It then fetches the vehicles from the database using the fetchVehiclesFromDb function, passing the specified conditions, order by specifications, and pagination settings.
-// If an error occurs during the fetch operation, it returns the error.
-// Otherwise, it converts the fetched vehicles to domain.Vehicle objects using the ToDomainVehicles function and returns them along with a nil error.
-func (v VehicleRepositoryPostgres) GetVehicle(
-	whereClauses []domain.WhereClause,
-	orderByClauses []domain.OrderByClause,
-	pagination domain.Pagination) ([]domain.Vehicle, *errs.AppError) {
-	if err := validatePagination(pagination); err != nil {
-		return nil, err
-	}
+// vehicleColumns maps the fields of a domain.VehicleQuery to the columns of the vehicles table. Queries
+// only reference these constant names, never a column name formatted from the input.
+var vehicleColumns = map[domain.VehicleField]string{
+	domain.VehicleFieldYear:           "year",
+	domain.VehicleFieldMonth:          "month",
+	domain.VehicleFieldFipeCode:       "fipe_code",
+	domain.VehicleFieldBrand:          "brand",
+	domain.VehicleFieldModel:          "vehicle_model",
+	domain.VehicleFieldYearModel:      "year_model",
+	domain.VehicleFieldAuthentication: "authentication",
+	domain.VehicleFieldMeanValue:      "mean_value",
+}
 
-	vehicles, err := fetchVehiclesFromDb(v, whereClauses, orderByClauses, pagination)
+// GetVehicle retrieves the vehicles matching the query, returning a NotFoundError if there are none.
+func (v VehicleRepositoryPostgres) GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.GetVehicle", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.GetVehicle")
+	defer span.End()
+
+	vehicles, err := fetchVehiclesFromDb(ctx, v, query)
 	if err != nil {
 		return nil, err
 	}
@@ -60,39 +85,370 @@ func (v VehicleRepositoryPostgres) GetVehicle(
 	return ToDomainVehicles(vehicles), nil
 }
 
-func fetchVehiclesFromDb(v VehicleRepositoryPostgres,
-	whereClauses []domain.WhereClause,
-	orderByClauses []domain.OrderByClause,
-	pagination domain.Pagination) ([]Vehicle, *errs.AppError) {
+// GetPriceAsOf retrieves the vehicle of the fipe code and year model in the latest reference month on or
+// before referenceMonth, returning a NotFoundError if there is none. Only that row is read from the
+// series.
+func (v VehicleRepositoryPostgres) GetPriceAsOf(
+	ctx context.Context,
+	fipeCode string,
+	yearModel string,
+	referenceMonth domain.ReferenceMonth,
+) (domain.Vehicle, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.GetPriceAsOf", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.GetPriceAsOf")
+	defer span.End()
 
 	var vehicles []Vehicle
-	fetch := v.Conn.Omit("ID", "CreatedAt", "UpdatedAt", "DeletedAt")
+	fetch := v.Conn.WithContext(ctx).Model(&Vehicle{}).
+		Where(clause.Eq{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]}, Value: fipeCode}).
+		Where(clause.Eq{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]}, Value: yearModel})
+	for _, expression := range periodExpressions(domain.Period{To: referenceMonth}) {
+		fetch = fetch.Where(expression)
+	}
+	result := fetch.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYear]}, Desc: true}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldMonth]}, Desc: true}).
+		Limit(1).
+		Find(&vehicles)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return domain.Vehicle{}, toAppError(ctx, v.log, result.Error)
+	}
+	if len(vehicles) == 0 {
+		return domain.Vehicle{}, errs.NewNotFoundError("Price not found")
+	}
+	return ToDomainVehicles(vehicles)[0], nil
+}
+
+// LookupVehicles retrieves, in a single query, the vehicles matching the fipe code, year model and
+// reference month of any of the lookups. Lookups without a match are simply absent from the result.
+func (v VehicleRepositoryPostgres) LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.Vehicle, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.LookupVehicles", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.LookupVehicles")
+	defer span.End()
+
+	if len(lookups) == 0 {
+		return nil, nil
+	}
 
-	for _, whereClause := range whereClauses {
-		if isValidJsonField(Vehicle{}, whereClause.Column) {
-			query := fmt.Sprintf("%s %s ?", whereClause.Column, whereClause.Operator)
-			fetch = fetch.Where(query, whereClause.Value)
+	tuples := make([][]any, 0, len(lookups))
+	for _, lookup := range lookups {
+		tuples = append(tuples, []any{
+			lookup.FipeCode, lookup.YearModel, lookup.ReferenceMonth.Year(), lookup.ReferenceMonth.Month(),
+		})
+	}
+	var vehicles []Vehicle
+	result := v.Conn.WithContext(ctx).Model(&Vehicle{}).
+		Where(clause.Expr{SQL: "(?, ?, ?, ?) IN ?", Vars: []any{
+			clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]},
+			clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]},
+			clause.Column{Name: vehicleColumns[domain.VehicleFieldYear]},
+			clause.Column{Name: vehicleColumns[domain.VehicleFieldMonth]},
+			tuples,
+		}}).
+		Find(&vehicles)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return nil, toAppError(ctx, v.log, result.Error)
+	}
+	return ToDomainVehicles(vehicles), nil
+}
+
+// vehicleMatch is a row of SearchVehicles.
+type vehicleMatch struct {
+	Vehicle `gorm:"embedded"`
+	Score   float64
+}
+
+// SearchVehicles retrieves the latest reference month of each fipe code and year model whose search_text,
+// created by Migrate, matches every term of the search, ranked by textSearchScore. Terms only have
+// letters, digits and points, so they need no LIKE escaping.
+func (v VehicleRepositoryPostgres) SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.SearchVehicles", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.SearchVehicles")
+	defer span.End()
+
+	searchText := clause.Column{Name: "search_text"}
+	score, scoreVars := textSearchScore(search)
+	latest := v.Conn.WithContext(ctx).Model(&Vehicle{}).
+		Select("DISTINCT ON (fipe_code, year_model) *, "+score+" AS score", scoreVars...)
+	for _, alternatives := range search.Terms() {
+		conditions := make([]clause.Expression, 0, 2*len(alternatives))
+		for _, alternative := range alternatives {
+			conditions = append(conditions, clause.Like{Column: searchText, Value: "%" + alternative + "%"})
+			if len(alternative) >= minFuzzyTermLength {
+				conditions = append(conditions, clause.Expr{SQL: "? <% ?", Vars: []any{alternative, searchText}})
+			}
 		}
+		if len(conditions) == 1 {
+			latest = latest.Where(conditions[0])
+		} else {
+			latest = latest.Where(clause.Or(conditions...))
+		}
+	}
+	latest = latest.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYear]}, Desc: true}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldMonth]}, Desc: true})
+
+	var rows []vehicleMatch
+	pagination := search.Pagination()
+	result := v.Conn.WithContext(ctx).Table("(?) AS latest", latest).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "score"}, Desc: true}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]}}).
+		Offset(pagination.Offset).
+		Limit(pagination.Limit).
+		Find(&rows)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return nil, toAppError(ctx, v.log, result.Error)
+	}
+	if len(rows) == 0 {
+		return nil, errs.NewNotFoundError("Vehicles not found")
 	}
 
-	for _, orderByClause := range orderByClauses {
-		if isValidJsonField(Vehicle{}, orderByClause.Column) {
-			fetch = fetch.Order(
-				clause.OrderByColumn{
-					Column: clause.Column{Name: orderByClause.Column},
-					Desc:   orderByClause.IsDesc,
-				})
+	matches := make([]domain.VehicleMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, domain.VehicleMatch{Vehicle: ToDomainVehicles([]Vehicle{row.Vehicle})[0], Score: row.Score})
+	}
+	return matches, nil
+}
+
+// textSearchScore is the SQL scoring a row for SearchVehicles, with its variables: the mean over the terms
+// of the word similarity to search_text of their best alternative, so that a synonym such as aut for
+// automatico ranks as well as the term itself.
+func textSearchScore(search domain.TextSearch) (string, []any) {
+	terms := search.Terms()
+	similarities := make([]string, 0, len(terms))
+	var vars []any
+	for _, alternatives := range terms {
+		alternativeSimilarities := make([]string, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			alternativeSimilarities = append(alternativeSimilarities, "word_similarity(?, search_text)")
+			vars = append(vars, alternative)
+		}
+		similarities = append(similarities, "GREATEST("+strings.Join(alternativeSimilarities, ", ")+")")
+	}
+	return fmt.Sprintf("(%s) / %d", strings.Join(similarities, " + "), len(terms)), vars
+}
+
+// GetListingCandidates retrieves the latest reference month of at most domain.MaxListingCandidates fipe
+// codes and year models whose search_text, created by Migrate, matches any word of the listing, most
+// similar to the listing first. The candidates are scored by domain.MatchListing; no candidate is not an
+// error.
+func (v VehicleRepositoryPostgres) GetListingCandidates(ctx context.Context, listing domain.Listing) ([]domain.Vehicle, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.GetListingCandidates", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.GetListingCandidates")
+	defer span.End()
+
+	searchText := clause.Column{Name: "search_text"}
+	conditions := make([]clause.Expression, 0, 2*len(listing.Words()))
+	for _, word := range listing.Words() {
+		conditions = append(conditions, clause.Like{Column: searchText, Value: "%" + likeEscaper.Replace(word) + "%"})
+		if len(word) >= minFuzzyTermLength {
+			conditions = append(conditions, clause.Expr{SQL: "? <% ?", Vars: []any{word, searchText}})
 		}
 	}
+	latest := v.Conn.WithContext(ctx).Model(&Vehicle{}).
+		Select("DISTINCT ON (fipe_code, year_model) *, similarity(?, search_text) AS score", listing.Text())
+	if len(conditions) == 1 {
+		latest = latest.Where(conditions[0])
+	} else {
+		latest = latest.Where(clause.Or(conditions...))
+	}
+	latest = latest.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYear]}, Desc: true}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldMonth]}, Desc: true})
+
+	var rows []vehicleMatch
+	result := v.Conn.WithContext(ctx).Table("(?) AS latest", latest).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "score"}, Desc: true}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]}}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldYearModel]}}).
+		Limit(domain.MaxListingCandidates).
+		Find(&rows)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return nil, toAppError(ctx, v.log, result.Error)
+	}
+
+	vehicles := make([]Vehicle, 0, len(rows))
+	for _, row := range rows {
+		vehicles = append(vehicles, row.Vehicle)
+	}
+	return ToDomainVehicles(vehicles), nil
+}
+
+// autocompleteValue is a row of Autocomplete.
+type autocompleteValue struct {
+	Value string
+	Count int
+}
+
+// likeEscaper escapes the LIKE wildcards of the prefixes and listing words, which unlike search terms may
+// have any character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Autocomplete retrieves the distinct values of the field whose folded value, indexed by Migrate, starts
+// with the prefix, counting the fipe codes of each value. Brand and model narrow the values by equality.
+// No value is not an error: the form just has nothing to suggest.
+func (v VehicleRepositoryPostgres) Autocomplete(
+	ctx context.Context,
+	autocomplete domain.Autocomplete,
+) ([]domain.AutocompleteValue, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.Autocomplete", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.Autocomplete")
+	defer span.End()
+
+	column := vehicleColumns[autocomplete.Field()]
+	query := v.Conn.WithContext(ctx).Model(&Vehicle{}).
+		Select("? AS value, COUNT(DISTINCT ?) AS count",
+			clause.Column{Name: column}, clause.Column{Name: vehicleColumns[domain.VehicleFieldFipeCode]})
+	if autocomplete.Prefix() != "" {
+		query = query.Where(foldedColumn(column)+" LIKE ?", likeEscaper.Replace(autocomplete.Prefix())+"%")
+	}
+	if autocomplete.Brand() != "" {
+		query = query.Where(clause.Eq{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldBrand]}, Value: autocomplete.Brand()})
+	}
+	if autocomplete.Model() != "" {
+		query = query.Where(clause.Eq{Column: clause.Column{Name: vehicleColumns[domain.VehicleFieldModel]}, Value: autocomplete.Model()})
+	}
+
+	var rows []autocompleteValue
+	result := query.
+		Group(column).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}}).
+		Limit(autocomplete.Limit()).
+		Find(&rows)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return nil, toAppError(ctx, v.log, result.Error)
+	}
+
+	values := make([]domain.AutocompleteValue, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, domain.AutocompleteValue{Value: row.Value, Count: row.Count})
+	}
+	return values, nil
+}
+
+// vehicleReferenceColumns are the columns of the unique index created by Migrate: a fipe code has a
+// single row per year model and reference month.
+var vehicleReferenceColumns = []clause.Column{{Name: "fipe_code"}, {Name: "year_model"}, {Name: "year"}, {Name: "month"}}
+
+// SaveVehicles upserts the given vehicles in batches of saveBatchSize rows: a vehicle already stored for
+// its fipe code, year model and reference month is updated, so an ingestion can be re-run. Within the
+// given vehicles, the last of a fipe code, year model and reference month wins. The vehicles and their
+// VehicleIngestion are saved in a single transaction.
+func (v VehicleRepositoryPostgres) SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.SaveVehicles", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.SaveVehicles")
+	defer span.End()
 
+	if len(vehicles) == 0 {
+		return nil
+	}
+
+	err := v.Conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{Columns: vehicleReferenceColumns, UpdateAll: true}).
+			CreateInBatches(FromDomainVehicles(uniqueVehicles(vehicles)), saveBatchSize)
+		recordStatement(span, result)
+		if result.Error != nil {
+			return result.Error
+		}
+		return tx.Create(&VehicleIngestion{Vehicles: len(vehicles), SavedAt: time.Now().UTC()}).Error
+	})
+	if err != nil {
+		return toAppError(ctx, v.log, err)
+	}
+	return nil
+}
+
+// GetIngestionVersion returns the id of the latest VehicleIngestion, or 0 if vehicles were never saved.
+func (v VehicleRepositoryPostgres) GetIngestionVersion(ctx context.Context) (int64, *errs.AppError) {
+	defer metrics.ObserveRepositoryQuery("VehicleRepository.GetIngestionVersion", time.Now())
+	ctx, span := startSpan(ctx, "VehicleRepository.GetIngestionVersion")
+	defer span.End()
+
+	var version int64
+	result := v.Conn.WithContext(ctx).Model(&VehicleIngestion{}).Select("COALESCE(MAX(id), 0)").Scan(&version)
+	recordStatement(span, result)
+	if result.Error != nil {
+		return 0, toAppError(ctx, v.log, result.Error)
+	}
+	return version, nil
+}
+
+// uniqueVehicles drops the vehicles followed by another of the same fipe code, year model and reference
+// month: a single upsert statement cannot update the same row twice.
+func uniqueVehicles(vehicles []domain.Vehicle) []domain.Vehicle {
+	type referenceKey struct {
+		fipeCode, yearModel string
+		year, month         int
+	}
+	last := make(map[referenceKey]int, len(vehicles))
+	for index, vehicle := range vehicles {
+		last[referenceKey{vehicle.FipeCode, vehicle.YearModel, vehicle.Year, vehicle.Month}] = index
+	}
+	if len(last) == len(vehicles) {
+		return vehicles
+	}
+	unique := make([]domain.Vehicle, 0, len(last))
+	for index, vehicle := range vehicles {
+		if last[referenceKey{vehicle.FipeCode, vehicle.YearModel, vehicle.Year, vehicle.Month}] == index {
+			unique = append(unique, vehicle)
+		}
+	}
+	return unique
+}
+
+func fetchVehiclesFromDb(ctx context.Context, v VehicleRepositoryPostgres, query domain.VehicleQuery) ([]Vehicle, *errs.AppError) {
+	var vehicles []Vehicle
+	fetch := v.Conn.WithContext(ctx).Model(&Vehicle{})
+
+	columns := make([]string, 0, len(query.Fields()))
+	for _, field := range query.Fields() {
+		columns = append(columns, vehicleColumns[field])
+	}
+	fetch = fetch.Select(columns)
+
+	for _, filter := range query.Filters() {
+		column := clause.Column{Name: vehicleColumns[filter.Field]}
+		if len(filter.Values) == 1 {
+			fetch = fetch.Where(clause.Eq{Column: column, Value: filter.Values[0]})
+		} else {
+			fetch = fetch.Where(clause.IN{Column: column, Values: filter.Values})
+		}
+	}
+
+	if match, ok := query.Match(); ok {
+		fetch = fetch.Where(filterExpression(match))
+	}
+	for _, expression := range periodExpressions(query.Period()) {
+		fetch = fetch.Where(expression)
+	}
+
+	for _, sort := range query.Sorts() {
+		fetch = fetch.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: vehicleColumns[sort.Field]},
+			Desc:   sort.Desc,
+		})
+	}
+
+	pagination := query.Pagination()
 	fetch = fetch.Offset(pagination.Offset).Limit(pagination.Limit)
 
 	result := fetch.Find(&vehicles)
+	recordStatement(trace.SpanFromContext(ctx), result)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errs.NewNotFoundError("Vehicles not found")
 		}
-		return nil, errs.NewUnexpectedError("Unexpected database error")
+		return nil, toAppError(ctx, v.log, result.Error)
 	}
 
 	if len(vehicles) == 0 {
@@ -102,6 +458,69 @@ func fetchVehiclesFromDb(v VehicleRepositoryPostgres,
 	return vehicles, nil
 }
 
+// filterExpression compiles a filter tree into a parameterised expression. Groups of a single child
+// compile to the child, as gorm joins a single OR condition to the preceding ones with OR.
+func filterExpression(node domain.FilterNode) clause.Expression {
+	if node.Kind != domain.FilterCondition {
+		expressions := make([]clause.Expression, 0, len(node.Children))
+		for _, child := range node.Children {
+			expressions = append(expressions, filterExpression(child))
+		}
+		switch {
+		case node.Kind == domain.FilterNot:
+			return clause.Not(expressions...)
+		case len(expressions) == 1:
+			return expressions[0]
+		case node.Kind == domain.FilterOr:
+			return clause.Or(expressions...)
+		default:
+			return clause.And(expressions...)
+		}
+	}
+
+	condition := node.Condition
+	column := clause.Column{Name: vehicleColumns[condition.Field]}
+	switch condition.Operator {
+	case domain.OperatorNe:
+		return clause.Neq{Column: column, Value: condition.Values[0]}
+	case domain.OperatorGt:
+		return clause.Gt{Column: column, Value: condition.Values[0]}
+	case domain.OperatorGte:
+		return clause.Gte{Column: column, Value: condition.Values[0]}
+	case domain.OperatorLt:
+		return clause.Lt{Column: column, Value: condition.Values[0]}
+	case domain.OperatorLte:
+		return clause.Lte{Column: column, Value: condition.Values[0]}
+	case domain.OperatorIn:
+		return clause.IN{Column: column, Values: condition.Values}
+	case domain.OperatorBetween:
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []any{column, condition.Values[0], condition.Values[1]}}
+	default:
+		return clause.Eq{Column: column, Value: condition.Values[0]}
+	}
+}
+
+// periodExpressions compiles the ends of the period into comparisons of the year and month columns.
+func periodExpressions(period domain.Period) []clause.Expression {
+	year := clause.Column{Name: vehicleColumns[domain.VehicleFieldYear]}
+	month := clause.Column{Name: vehicleColumns[domain.VehicleFieldMonth]}
+
+	var expressions []clause.Expression
+	if from := period.From; !from.IsZero() {
+		expressions = append(expressions, clause.Or(
+			clause.Gt{Column: year, Value: from.Year()},
+			clause.And(clause.Eq{Column: year, Value: from.Year()}, clause.Gte{Column: month, Value: from.Month()}),
+		))
+	}
+	if to := period.To; !to.IsZero() {
+		expressions = append(expressions, clause.Or(
+			clause.Lt{Column: year, Value: to.Year()},
+			clause.And(clause.Eq{Column: year, Value: to.Year()}, clause.Lte{Column: month, Value: to.Month()}),
+		))
+	}
+	return expressions
+}
+
 // ToDomainVehicles converts a slice of Vehicle objects to a slice of domain.Vehicle objects.
 func ToDomainVehicles(vehicles []Vehicle) []domain.Vehicle {
 	var domainVehicles []domain.Vehicle
@@ -123,42 +542,23 @@ func ToDomainVehicles(vehicles []Vehicle) []domain.Vehicle {
 	return domainVehicles
 }
 
-// validatePagination validates the given pagination parameters.
-// It checks if the limit is within the valid range (1 to MaxLimit) and if the offset is greater than or equal to 0.
-// If any validation error occurs, it returns an AppError with the corresponding error message.
-// Otherwise, it returns nil indicating that the pagination is valid.
-// Example usage: err := validatePagination(pagination)
-func validatePagination(pagination domain.Pagination) *errs.AppError {
-	if pagination.Limit < 1 || pagination.Limit > domain.MaxLimit {
-		return errs.NewUnprocessableEntityError(
-			fmt.Sprintf("invalid limit. The limit must be between 1 and %d",
-				domain.MaxLimit,
-			),
-		)
-
-	}
-	if pagination.Offset < 0 {
-		return errs.NewUnprocessableEntityError("invalid offset. The offset must be greater than 0")
-	}
-	if pagination.Offset > pagination.Limit {
-		return errs.NewUnprocessableEntityError("Offset must be smaller than Limit")
-
-	}
-	return nil
-}
-
-// isValidJsonField returns true if the given column is a valid JSON field in the input struct, otherwise false.
-func isValidJsonField(input interface{}, column string) bool {
-	typeOfInput := reflect.TypeOf(input)
+// FromDomainVehicles converts a slice of domain.Vehicle objects to a slice of Vehicle objects.
+func FromDomainVehicles(domainVehicles []domain.Vehicle) []Vehicle {
+	vehicles := make([]Vehicle, 0, len(domainVehicles))
 
-	for i := 0; i < typeOfInput.NumField(); i++ {
-		// GetByFipeCode the field, returns https://golang.org/pkg/reflect/#StructField
-		field := typeOfInput.Field(i)
-		jsonTag, ok := field.Tag.Lookup("json")
-		jsonTagList := strings.Split(jsonTag, ",")
-		if ok && slices.Contains(jsonTagList, column) {
-			return true
-		}
+	for _, vehicle := range domainVehicles {
+		vehicles = append(vehicles,
+			Vehicle{
+				Year:           vehicle.Year,
+				Month:          vehicle.Month,
+				FipeCode:       vehicle.FipeCode,
+				Brand:          vehicle.Brand,
+				VehicleModel:   vehicle.Model,
+				YearModel:      vehicle.YearModel,
+				Authentication: vehicle.Authentication,
+				MeanValue:      vehicle.MeanValue,
+			},
+		)
 	}
-	return false
+	return vehicles
 }
@@ -1,9 +1,12 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
+	appConfig "github.com/raffops/gofipe/cmd/goFipe/config"
 	postgres2 "github.com/raffops/gofipe/cmd/goFipe/database/postgres"
 	"github.com/raffops/gofipe/cmd/goFipe/logger"
 	"gorm.io/driver/postgres"
@@ -17,125 +20,22 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func Test_isValidField(t *testing.T) {
-	type args struct {
-		input  interface{}
-		column string
-	}
-
-	tests := []struct {
-		name string
-		args args
-		want bool
-	}{
-		{
-			name: "struct with valid field #1",
-			args: args{
-				input:  Vehicle{},
-				column: "year_model",
-			},
-			want: true,
-		},
-		{
-			name: "struct with valid field #2",
-			args: args{
-				input:  Vehicle{},
-				column: "fipe_code",
-			},
-			want: true,
-		},
-		{
-			name: "struct without valid field",
-			args: args{
-				input:  Vehicle{},
-				column: "fipecode",
-			},
-			want: false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, isValidJsonField(tt.args.input, tt.args.column), "isValidJsonField(%v, %v)", tt.args.input, tt.args.column)
-		})
-	}
-}
-
-func Test_validatePagination(t *testing.T) {
-	type args struct {
-		pagination domain.Pagination
-	}
-	tests := []struct {
-		name string
-		args args
-		want *errs.AppError
-	}{
-		{
-			name: "Valid Pagination",
-			args: args{
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
-			},
-			want: nil,
-		},
-		{
-			name: "Offset greater than limit",
-			args: args{
-				pagination: domain.Pagination{Offset: 11, Limit: 10},
-			},
-			want: errs.NewUnprocessableEntityError("Offset must be smaller than Limit"),
-		},
-
-		{
-			name: "Negative Offset",
-			args: args{
-				pagination: domain.Pagination{Offset: -1, Limit: 10},
-			},
-			want: errs.NewUnprocessableEntityError("invalid offset. The offset must be greater than 0"), // Assuming this error type
-		},
-		{
-			name: "Negative Limit",
-			args: args{
-				pagination: domain.Pagination{Offset: 0, Limit: -1},
-			},
-			want: errs.NewUnprocessableEntityError(
-				fmt.Sprintf("invalid limit. The limit must be between 1 and %d",
-					domain.MaxLimit),
-			),
-		},
-		{
-			name: "Zero Limit",
-			args: args{
-				pagination: domain.Pagination{Offset: 0, Limit: 0},
-			},
-			want: errs.NewUnprocessableEntityError(
-				fmt.Sprintf("invalid limit. The limit must be between 1 and %d",
-					domain.MaxLimit,
-				),
-			),
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, validatePagination(tt.args.pagination), "validatePagination(%v)", tt.args.pagination)
-		})
-	}
-}
-
 func TestMain(m *testing.M) {
 	var errPool error
 	pool, errPool := dockertest.NewPool("")
 	if errPool != nil {
-		logger.Error(fmt.Sprintf("Could not connect to docker: %s", errPool))
+		log.Printf("Could not connect to docker: %s", errPool)
 	}
 
 	network, errNetwork := pool.CreateNetwork("backend")
 	if errNetwork != nil {
-		logger.Error(fmt.Sprintf("Could not create Network to docker: %s \n", errPool))
+		log.Printf("Could not create Network to docker: %s \n", errNetwork)
 	}
 
 	resource, errResource := startPostgres(pool, network)
 
 	if errResource != nil {
-		logger.Error(fmt.Sprintf("Could not create Postgres: %s \n", errResource))
+		log.Printf("Could not create Postgres: %s \n", errResource)
 	}
 
 	exitCode := m.Run()
@@ -186,6 +86,21 @@ func startPostgres(pool *dockertest.Pool, network *dockertest.Network) (*dockert
 	return resource, nil
 }
 
+// getPostgresConnection connects to the test database configured by the environment and closes the
+// connection when the test finishes.
+func getPostgresConnection(t *testing.T) *gorm.DB {
+	config, err := appConfig.Load(nil, os.LookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := postgres2.GetPostgresConnection(context.Background(), config.Postgres, logger.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = postgres2.ClosePostgresConnection(conn) })
+	return conn
+}
+
 func testPostgresConnection(pool *dockertest.Pool) error {
 	var err error
 	var db *gorm.DB
@@ -228,7 +143,7 @@ func testPostgresConnection(pool *dockertest.Pool) error {
 		return err4
 	}
 
-	logger.Info("Connected to the database!")
+	log.Println("Connected to the database!")
 	return nil
 }
 
@@ -236,8 +151,8 @@ func TestNewVehicleRepositoryPostgres(t *testing.T) {
 	type args struct {
 		conn *gorm.DB
 	}
-	conn := postgres2.GetPostgresConnection()
-	t.Cleanup(func() { postgres2.ClosePostgresConnection(conn) })
+	conn := getPostgresConnection(t)
+	nopLogger := logger.NewNop()
 	tests := []struct {
 		name string
 		args args
@@ -246,12 +161,12 @@ func TestNewVehicleRepositoryPostgres(t *testing.T) {
 		{
 			name: "Single test",
 			args: args{conn: conn},
-			want: &VehicleRepositoryPostgres{Conn: conn},
+			want: &VehicleRepositoryPostgres{Conn: conn, log: nopLogger},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewVehicleRepositoryPostgres(tt.args.conn)
+			got := NewVehicleRepositoryPostgres(tt.args.conn, nopLogger)
 			assert.Equal(t, got, tt.want)
 		})
 	}
@@ -282,13 +197,10 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 		conn *gorm.DB
 	}
 	type args struct {
-		conditions []domain.WhereClause
-		orderBy    []domain.OrderByClause
-		pagination domain.Pagination
+		query *domain.VehicleQueryBuilder
 	}
 
-	conn := postgres2.GetPostgresConnection()
-	t.Cleanup(func() { postgres2.ClosePostgresConnection(conn) })
+	conn := getPostgresConnection(t)
 	err := conn.AutoMigrate(&Vehicle{})
 	if err != nil {
 		t.Fatal(err)
@@ -306,9 +218,7 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "One Vehicle",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{},
-				orderBy:    []domain.OrderByClause{},
-				pagination: domain.Pagination{Offset: 0, Limit: 1},
+				query: domain.NewVehicleQueryBuilder().Page(0, 1),
 			},
 			want:      []domain.Vehicle{domainVehiclesOnDb[0]},
 			wantError: nil,
@@ -317,14 +227,7 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "Order by fipe code",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{},
-				orderBy: []domain.OrderByClause{
-					{
-						Column: "fipe_code",
-						IsDesc: false,
-					},
-				},
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
+				query: domain.NewVehicleQueryBuilder().OrderBy("fipe_code", false),
 			},
 			want: []domain.Vehicle{
 				domainVehiclesOnDb[0],
@@ -338,14 +241,7 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "Order desc by mean value",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{},
-				orderBy: []domain.OrderByClause{
-					{
-						Column: "mean_value",
-						IsDesc: true,
-					},
-				},
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
+				query: domain.NewVehicleQueryBuilder().OrderBy("mean_value", true),
 			},
 			want: []domain.Vehicle{
 				domainVehiclesOnDb[3],
@@ -359,14 +255,7 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "Order asc by mean value offset 1 limit 2",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{},
-				orderBy: []domain.OrderByClause{
-					{
-						Column: "mean_value",
-						IsDesc: false,
-					},
-				},
-				pagination: domain.Pagination{Offset: 1, Limit: 2},
+				query: domain.NewVehicleQueryBuilder().OrderBy("mean_value", false).Page(1, 2),
 			},
 			want: []domain.Vehicle{
 				domainVehiclesOnDb[1],
@@ -378,39 +267,33 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "fipe_code equal to 111111-1",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{
-					{
-						Column:   "fipe_code",
-						Operator: "=",
-						Value:    "111111-1",
-					},
-				},
-				orderBy: []domain.OrderByClause{
-					{
-						Column: "mean_value",
-						IsDesc: false,
-					},
-				},
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
+				query: domain.NewVehicleQueryBuilder().Where("fipe_code", "111111-1").OrderBy("mean_value", false),
 			},
 			want: []domain.Vehicle{
 				domainVehiclesOnDb[0],
 			},
 			wantError: nil,
 		},
+		{
+			name:   "fipe_code repeated, matches any of the values",
+			fields: fields{conn: conn},
+			args: args{
+				query: domain.NewVehicleQueryBuilder().
+					Where("fipe_code", "111111-1").
+					Where("fipe_code", "333333-3").
+					OrderBy("mean_value", true),
+			},
+			want: []domain.Vehicle{
+				domainVehiclesOnDb[3],
+				domainVehiclesOnDb[0],
+			},
+			wantError: nil,
+		},
 		{
 			name:   "NewNotFoundError",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{
-					{
-						Column:   "fipe_code",
-						Operator: "=",
-						Value:    "76576",
-					},
-				},
-				orderBy:    []domain.OrderByClause{},
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
+				query: domain.NewVehicleQueryBuilder().Where("fipe_code", "999999-9"),
 			},
 			want:      nil,
 			wantError: errs.NewNotFoundError("Vehicles not found"),
@@ -419,35 +302,369 @@ func TestVehicleRepositoryPostgres_GetVehicle(t *testing.T) {
 			name:   "year equal to 2021 and month equal to 8",
 			fields: fields{conn: conn},
 			args: args{
-				conditions: []domain.WhereClause{
-					{
-						Column:   "year",
-						Operator: "=",
-						Value:    2021,
-					},
-					{
-						Column:   "month",
-						Operator: "=",
-						Value:    8,
-					},
-				},
-				orderBy:    []domain.OrderByClause{},
-				pagination: domain.Pagination{Offset: 0, Limit: 10},
+				query: domain.NewVehicleQueryBuilder().Where("year", "2021").Where("month", "8"),
 			},
 			want: []domain.Vehicle{
 				domainVehiclesOnDb[3],
 			},
 			wantError: nil,
 		},
+		{
+			name:   "Filter tree",
+			fields: fields{conn: conn},
+			args: args{
+				query: domain.NewVehicleQueryBuilder().Match(domain.And(
+					domain.Or(domain.Compare("brand", "eq", "Fiat"), domain.Compare("brand", "eq", "Acura")),
+					domain.Compare("mean_value", "between", "790", "801"),
+					domain.Not(domain.Compare("month", "eq", "7")),
+				)),
+			},
+			want: []domain.Vehicle{
+				domainVehiclesOnDb[1],
+			},
+			wantError: nil,
+		},
+		{
+			name:   "Filter tree and where",
+			fields: fields{conn: conn},
+			args: args{
+				query: domain.NewVehicleQueryBuilder().
+					Where("year", "2021").
+					Match(domain.Or(domain.Compare("month", "lt", "7"), domain.Compare("fipe_code", "eq", "333333-3"))).
+					OrderBy("month", false),
+			},
+			want: []domain.Vehicle{
+				domainVehiclesOnDb[1],
+				domainVehiclesOnDb[3],
+			},
+			wantError: nil,
+		},
+		{
+			name:   "Select fipe code and mean value",
+			fields: fields{conn: conn},
+			args: args{
+				query: domain.NewVehicleQueryBuilder().Select("fipe_code", "mean_value").OrderBy("fipe_code", false).Page(0, 1),
+			},
+			want: []domain.Vehicle{
+				{FipeCode: domainVehiclesOnDb[0].FipeCode, MeanValue: domainVehiclesOnDb[0].MeanValue},
+			},
+			wantError: nil,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			v := VehicleRepositoryPostgres{
 				Conn: tt.fields.conn,
+				log:  logger.NewNop(),
 			}
-			got, gotError := v.GetVehicle(tt.args.conditions, tt.args.orderBy, tt.args.pagination)
-			assert.Equalf(t, tt.want, got, "GetVehicle(%v, %v, %v)", tt.args.conditions, tt.args.orderBy, tt.args.pagination)
-			assert.Equalf(t, tt.wantError, gotError, "GetVehicle(%v, %v, %v)", tt.args.conditions, tt.args.orderBy, tt.args.pagination)
+			query := tt.args.query.MustBuild()
+			got, gotError := v.GetVehicle(context.Background(), query)
+			assert.Equalf(t, tt.want, got, "GetVehicle(%v)", query)
+			assert.Equalf(t, tt.wantError, gotError, "GetVehicle(%v)", query)
+		})
+	}
+}
+
+func TestVehicleRepositoryPostgres_GetPriceAsOf(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := conn.AutoMigrate(&Vehicle{}); err != nil {
+		t.Fatal(err)
+	}
+	series := []domain.Vehicle{
+		{Year: 2020, Month: 12, FipeCode: "444444-4", Brand: "VW", Model: "Gol 1.0", YearModel: "2015 Gasolina", MeanValue: 30000},
+		{Year: 2021, Month: 2, FipeCode: "444444-4", Brand: "VW", Model: "Gol 1.0", YearModel: "2015 Gasolina", MeanValue: 31000},
+		{Year: 2021, Month: 3, FipeCode: "444444-4", Brand: "VW", Model: "Gol 1.0", YearModel: "2016 Gasolina", MeanValue: 35000},
+	}
+	if err := conn.Create(FromDomainVehicles(series)).Error; err != nil {
+		t.Fatal(err)
+	}
+	referenceMonth := func(year int, month int) domain.ReferenceMonth {
+		r, err := domain.NewReferenceMonth(year, month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name           string
+		yearModel      string
+		referenceMonth domain.ReferenceMonth
+		want           domain.Vehicle
+		wantError      *errs.AppError
+	}{
+		{
+			name:           "Published reference month",
+			yearModel:      "2015 Gasolina",
+			referenceMonth: referenceMonth(2021, 2),
+			want:           series[1],
+		},
+		{
+			name:           "Latest reference month before an unpublished one",
+			yearModel:      "2015 Gasolina",
+			referenceMonth: referenceMonth(2021, 1),
+			want:           series[0],
+		},
+		{
+			name:           "Other year models are ignored",
+			yearModel:      "2015 Gasolina",
+			referenceMonth: referenceMonth(2021, 7),
+			want:           series[1],
+		},
+		{
+			name:           "Before the first reference month",
+			yearModel:      "2015 Gasolina",
+			referenceMonth: referenceMonth(2020, 11),
+			wantError:      errs.NewNotFoundError("Price not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+			got, gotError := v.GetPriceAsOf(context.Background(), "444444-4", tt.yearModel, tt.referenceMonth)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantError, gotError)
 		})
 	}
 }
+
+func TestVehicleRepositoryPostgres_LookupVehicles(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := conn.AutoMigrate(&Vehicle{}); err != nil {
+		t.Fatal(err)
+	}
+	series := []domain.Vehicle{
+		{Year: 2021, Month: 6, FipeCode: "555555-5", Brand: "VW", Model: "Up 1.0", YearModel: "2018 Gasolina", MeanValue: 40000},
+		{Year: 2021, Month: 7, FipeCode: "555555-5", Brand: "VW", Model: "Up 1.0", YearModel: "2018 Gasolina", MeanValue: 41000},
+		{Year: 2021, Month: 7, FipeCode: "555555-5", Brand: "VW", Model: "Up 1.0", YearModel: "2019 Gasolina", MeanValue: 45000},
+	}
+	if err := conn.Create(FromDomainVehicles(series)).Error; err != nil {
+		t.Fatal(err)
+	}
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	july2021 := june2021.AddMonths(1)
+
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+	got, err := v.LookupVehicles(context.Background(), []domain.VehicleLookup{
+		{FipeCode: "555555-5", YearModel: "2019 Gasolina", ReferenceMonth: july2021},
+		{FipeCode: "555555-5", YearModel: "2018 Gasolina", ReferenceMonth: june2021},
+		{FipeCode: "555555-5", YearModel: "2019 Gasolina", ReferenceMonth: june2021},
+	})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []domain.Vehicle{series[0], series[2]}, got)
+
+	got, err = v.LookupVehicles(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+}
+
+func TestVehicleRepositoryPostgres_SaveVehicles(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+	version, err := v.GetIngestionVersion(context.Background())
+	assert.Nil(t, err)
+	vehicles := []domain.Vehicle{
+		{Year: 2021, Month: 7, FipeCode: "777770-7", Brand: "Ford", Model: "Ka 1.0", YearModel: "2015 Gasolina", MeanValue: 25000},
+		{Year: 2021, Month: 7, FipeCode: "777770-7", Brand: "Ford", Model: "Ka 1.0", YearModel: "2016 Gasolina", MeanValue: 27000},
+	}
+	assert.Nil(t, v.SaveVehicles(context.Background(), vehicles))
+
+	reingested := []domain.Vehicle{
+		{Year: 2021, Month: 7, FipeCode: "777770-7", Brand: "Ford", Model: "Ka 1.0", YearModel: "2015 Gasolina", MeanValue: 25500},
+		{Year: 2021, Month: 7, FipeCode: "777770-7", Brand: "Ford", Model: "Ka 1.0", YearModel: "2016 Gasolina", MeanValue: 27000},
+		{Year: 2021, Month: 7, FipeCode: "777770-7", Brand: "Ford", Model: "Ka 1.0", YearModel: "2016 Gasolina", MeanValue: 27500},
+	}
+	assert.Nil(t, v.SaveVehicles(context.Background(), reingested), "re-ingesting a reference month must not conflict")
+
+	reingestedVersion, err := v.GetIngestionVersion(context.Background())
+	assert.Nil(t, err)
+	assert.Greater(t, reingestedVersion, version, "every save is a new ingestion version")
+
+	got, err := v.GetVehicle(context.Background(), domain.NewVehicleQueryBuilder().
+		Where("fipe_code", "777770-7").OrderBy("year_model", false).MustBuild())
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.Vehicle{reingested[0], reingested[2]}, got, "updated, not duplicated")
+}
+
+func TestVehicleRepositoryPostgres_SearchVehicles(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	vehicles := []domain.Vehicle{
+		{Year: 2021, Month: 6, FipeCode: "666666-6", Brand: "Citroën", Model: "C4 Picasso 2.0 Aut.", YearModel: "2015 Gasolina", MeanValue: 50000},
+		{Year: 2021, Month: 7, FipeCode: "666666-6", Brand: "Citroën", Model: "C4 Picasso 2.0 Aut.", YearModel: "2015 Gasolina", MeanValue: 51000},
+		{Year: 2021, Month: 7, FipeCode: "666667-6", Brand: "Citroën", Model: "C3 1.6 Mec.", YearModel: "2015 Gasolina", MeanValue: 40000},
+	}
+	if err := conn.Create(FromDomainVehicles(vehicles)).Error; err != nil {
+		t.Fatal(err)
+	}
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+
+	search, _ := domain.NewTextSearch("citroen picasso automatico", 0, 10)
+	got, err := v.SearchVehicles(context.Background(), search)
+	assert.Nil(t, err)
+	if assert.Len(t, got, 1) {
+		assert.Equal(t, vehicles[1], got[0].Vehicle, "latest reference month")
+		assert.Greater(t, got[0].Score, 0.0)
+
+		abbreviated, _ := domain.NewTextSearch("citroen picasso aut", 0, 10)
+		gotAbbreviated, errAbbreviated := v.SearchVehicles(context.Background(), abbreviated)
+		assert.Nil(t, errAbbreviated)
+		if assert.Len(t, gotAbbreviated, 1) {
+			assert.InDelta(t, gotAbbreviated[0].Score, got[0].Score, 1e-6, "ranked by the synonym aut of automatico")
+		}
+	}
+
+	search, _ = domain.NewTextSearch("citroen 2015", 0, 10)
+	got, err = v.SearchVehicles(context.Background(), search)
+	assert.Nil(t, err)
+	assert.Len(t, got, 2)
+
+	search, _ = domain.NewTextSearch("citroen 1.8", 0, 10)
+	_, err = v.SearchVehicles(context.Background(), search)
+	assert.Equal(t, errs.NewNotFoundError("Vehicles not found"), err)
+}
+
+func TestVehicleRepositoryPostgres_Autocomplete(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	vehicles := []domain.Vehicle{
+		{Year: 2021, Month: 6, FipeCode: "888888-8", Brand: "Chéry", Model: "Tiggo 2.0", YearModel: "2015 Gasolina", MeanValue: 40000},
+		{Year: 2021, Month: 7, FipeCode: "888888-8", Brand: "Chéry", Model: "Tiggo 2.0", YearModel: "2015 Gasolina", MeanValue: 41000},
+		{Year: 2021, Month: 7, FipeCode: "888889-8", Brand: "Chéry", Model: "Tiggo 2.0 Aut.", YearModel: "2016 Gasolina", MeanValue: 45000},
+		{Year: 2021, Month: 7, FipeCode: "888890-8", Brand: "Chéry", Model: "QQ 1.0", YearModel: "2015 Gasolina", MeanValue: 20000},
+	}
+	if err := conn.Create(FromDomainVehicles(vehicles)).Error; err != nil {
+		t.Fatal(err)
+	}
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+
+	autocomplete, _ := domain.NewAutocomplete("brand", "CHER", "", "", 10)
+	got, err := v.Autocomplete(context.Background(), autocomplete)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.AutocompleteValue{{Value: "Chéry", Count: 3}}, got)
+
+	autocomplete, _ = domain.NewAutocomplete("model", "tig", "Chéry", "", 10)
+	got, err = v.Autocomplete(context.Background(), autocomplete)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.AutocompleteValue{{Value: "Tiggo 2.0", Count: 1}, {Value: "Tiggo 2.0 Aut.", Count: 1}}, got)
+
+	autocomplete, _ = domain.NewAutocomplete("year_model", "", "Chéry", "Tiggo 2.0", 10)
+	got, err = v.Autocomplete(context.Background(), autocomplete)
+	assert.Nil(t, err)
+	assert.Equal(t, []domain.AutocompleteValue{{Value: "2015 Gasolina", Count: 1}}, got)
+
+	autocomplete, _ = domain.NewAutocomplete("model", "%", "Chéry", "", 10)
+	got, err = v.Autocomplete(context.Background(), autocomplete)
+	assert.Nil(t, err)
+	assert.Empty(t, got, "LIKE wildcards are escaped")
+}
+
+func TestVehicleRepositoryPostgres_GetListingCandidates(t *testing.T) {
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	vehicles := []domain.Vehicle{
+		{Year: 2021, Month: 6, FipeCode: "999990-1", Brand: "Renault", Model: "KWID Zen 1.0 Flex 12V 5p Mec.", YearModel: "2019 Gasolina", MeanValue: 40000},
+		{Year: 2021, Month: 7, FipeCode: "999990-1", Brand: "Renault", Model: "KWID Zen 1.0 Flex 12V 5p Mec.", YearModel: "2019 Gasolina", MeanValue: 41000},
+		{Year: 2021, Month: 7, FipeCode: "999991-1", Brand: "Renault", Model: "KWID Intense 1.0 Flex 12V 5p Mec.", YearModel: "2019 Gasolina", MeanValue: 45000},
+	}
+	if err := conn.Create(FromDomainVehicles(vehicles)).Error; err != nil {
+		t.Fatal(err)
+	}
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+
+	listing, _ := domain.NewListing(domain.SystemClock, "KWID ZEN 1.0 2019")
+	got, err := v.GetListingCandidates(context.Background(), listing)
+	assert.Nil(t, err)
+	assert.Contains(t, got, vehicles[1], "latest reference month")
+	assert.Contains(t, got, vehicles[2])
+	assert.NotContains(t, got, vehicles[0])
+	if matches := domain.MatchListing(listing, got, 1); assert.Len(t, matches, 1) {
+		assert.Equal(t, vehicles[1], matches[0].Vehicle)
+	}
+
+	listing, _ = domain.NewListing(domain.SystemClock, "Xyzzy 2019")
+	got, err = v.GetListingCandidates(context.Background(), listing)
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+}
+
+// TestVehicleRepositoryPostgres_ListingAccuracy matches the labelled listings of the domain fixture
+// against its catalog, decoys included, loaded in the database: unlike the domain accuracy test, only the
+// candidates GetListingCandidates finds are scored, so a labelled vehicle it misses is a miss.
+func TestVehicleRepositoryPostgres_ListingAccuracy(t *testing.T) {
+	const (
+		minTop1Accuracy = 0.9
+		minTop3Accuracy = 0.95
+	)
+	content, err := os.ReadFile("../../domain/testdata/listings.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fixture struct {
+		Catalog []struct {
+			FipeCode  string `json:"fipe_code"`
+			Brand     string `json:"brand"`
+			Model     string `json:"model"`
+			YearModel string `json:"year_model"`
+		} `json:"catalog"`
+		Listings []struct {
+			Description string `json:"description"`
+			FipeCode    string `json:"fipe_code"`
+			YearModel   string `json:"year_model"`
+		} `json:"listings"`
+	}
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := getPostgresConnection(t)
+	if err := Migrate(conn); err != nil {
+		t.Fatal(err)
+	}
+	catalog := make([]domain.Vehicle, 0, len(fixture.Catalog))
+	for _, entry := range fixture.Catalog {
+		catalog = append(catalog, domain.Vehicle{
+			Year: 2021, Month: 7, FipeCode: entry.FipeCode, Brand: entry.Brand, Model: entry.Model, YearModel: entry.YearModel,
+		})
+	}
+	v := VehicleRepositoryPostgres{Conn: conn, log: logger.NewNop()}
+	if err := v.SaveVehicles(context.Background(), catalog); err != nil {
+		t.Fatal(err)
+	}
+
+	var top1, top3 int
+	for _, labelled := range fixture.Listings {
+		listing, errListing := domain.NewListing(domain.SystemClock, labelled.Description)
+		if errListing != nil {
+			t.Fatalf("%q: %v", labelled.Description, errListing)
+		}
+		candidates, errCandidates := v.GetListingCandidates(context.Background(), listing)
+		if errCandidates != nil {
+			t.Fatalf("%q: %v", labelled.Description, errCandidates)
+		}
+		for rank, match := range domain.MatchListing(listing, candidates, 3) {
+			if match.Vehicle.FipeCode != labelled.FipeCode || match.Vehicle.YearModel != labelled.YearModel {
+				continue
+			}
+			if rank == 0 {
+				top1++
+			}
+			top3++
+		}
+	}
+
+	top1Accuracy := float64(top1) / float64(len(fixture.Listings))
+	top3Accuracy := float64(top3) / float64(len(fixture.Listings))
+	t.Logf("%d listings, top 1 accuracy %.3f, top 3 accuracy %.3f", len(fixture.Listings), top1Accuracy, top3Accuracy)
+	assert.GreaterOrEqual(t, top1Accuracy, minTop1Accuracy)
+	assert.GreaterOrEqual(t, top3Accuracy, minTop3Accuracy)
+}
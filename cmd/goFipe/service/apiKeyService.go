@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+type ApiKeyService struct {
+	apiKeyRepo ports.ApiKeyRepository
+	clock      domain.Clock
+	log        logger.Logger
+}
+
+func NewApiKeyService(apiKeyRepo ports.ApiKeyRepository, clock domain.Clock, log logger.Logger) ApiKeyService {
+	return ApiKeyService{apiKeyRepo: apiKeyRepo, clock: clock, log: log}
+}
+
+// Create generates a new API key with the given name and monthly quota (0 means unlimited).
+// The raw key is returned only here; afterwards only its hash is available.
+func (a ApiKeyService) Create(ctx context.Context, name string, monthlyQuota int) (domain.ApiKey, string, *errs.AppError) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.ApiKey{}, "", errs.NewValidationError("Name is required")
+	}
+	if monthlyQuota < 0 {
+		return domain.ApiKey{}, "", errs.NewValidationError("Monthly quota must be greater or equal than 0")
+	}
+
+	rawKey, err := domain.GenerateApiKey()
+	if err != nil {
+		logger.FromContext(ctx, a.log).Error("Unable to generate api key", logger.Err(err))
+		return domain.ApiKey{}, "", errs.NewUnexpectedError("Unable to generate api key")
+	}
+
+	apiKey, errCreate := a.apiKeyRepo.Create(ctx, domain.ApiKey{
+		Name:         name,
+		Prefix:       domain.GetApiKeyIdentifier(rawKey),
+		Hash:         domain.HashApiKey(rawKey),
+		MonthlyQuota: monthlyQuota,
+		CreatedAt:    a.clock.Now().UTC(),
+	})
+	if errCreate != nil {
+		return domain.ApiKey{}, "", errCreate
+	}
+
+	logger.FromContext(ctx, a.log).Info("Api key created",
+		logger.String("name", apiKey.Name),
+		logger.String("prefix", apiKey.Prefix),
+	)
+	return apiKey, rawKey, nil
+}
+
+// Authenticate validates the raw API key. It returns an UnauthorizedError for unknown or revoked keys.
+// The request is not accounted here: see AccountUsage.
+func (a ApiKeyService) Authenticate(ctx context.Context, rawKey string) (domain.ApiKey, *errs.AppError) {
+	if strings.TrimSpace(rawKey) == "" {
+		return domain.ApiKey{}, errs.NewUnauthorizedError("Api key is required")
+	}
+
+	apiKey, err := a.apiKeyRepo.GetByHash(ctx, domain.HashApiKey(rawKey))
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return domain.ApiKey{}, errs.NewUnauthorizedError("Invalid api key")
+		}
+		return domain.ApiKey{}, err
+	}
+
+	if apiKey.IsRevoked() {
+		return domain.ApiKey{}, errs.NewUnauthorizedError("Invalid api key")
+	}
+	return apiKey, nil
+}
+
+// AccountUsage accounts one request in the monthly usage of an authenticated API key. It returns a
+// TooManyRequestsError when the monthly quota is exhausted, in which case the request is not accounted.
+func (a ApiKeyService) AccountUsage(ctx context.Context, apiKey domain.ApiKey) *errs.AppError {
+	year, month, _ := a.clock.Now().UTC().Date()
+	_, accounted, err := a.apiKeyRepo.IncrementUsage(ctx, apiKey.ID, year, int(month), apiKey.MonthlyQuota)
+	if err != nil {
+		return err
+	}
+	if !accounted {
+		return errs.NewTooManyRequestsError("Monthly quota exceeded")
+	}
+	return nil
+}
+
+func (a ApiKeyService) List(ctx context.Context) ([]domain.ApiKey, *errs.AppError) {
+	return a.apiKeyRepo.List(ctx)
+}
+
+func (a ApiKeyService) Revoke(ctx context.Context, id uint) *errs.AppError {
+	if err := a.apiKeyRepo.Revoke(ctx, id, a.clock.Now().UTC()); err != nil {
+		return err
+	}
+	logger.FromContext(ctx, a.log).Info("Api key revoked", logger.Int("id", int(id)))
+	return nil
+}
+
+func (a ApiKeyService) GetUsage(ctx context.Context, id uint, year int, month int) (domain.ApiKeyUsage, *errs.AppError) {
+	if !domain.IsValidYear(a.clock, year) {
+		return domain.ApiKeyUsage{}, errs.NewValidationError("Invalid year")
+	}
+	if !domain.IsValidMonth(month) {
+		return domain.ApiKeyUsage{}, errs.NewValidationError("Invalid month")
+	}
+	if _, err := a.apiKeyRepo.GetByID(ctx, id); err != nil {
+		return domain.ApiKeyUsage{}, err
+	}
+	return a.apiKeyRepo.GetUsage(ctx, id, year, month)
+}
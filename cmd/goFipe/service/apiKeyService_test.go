@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMockApiKeyRepository(t *testing.T) (*mockPort.MockApiKeyRepository, *gomock.Controller) {
+	ctrl := gomock.NewController(t)
+	mockApiKeyRepository := mockPort.NewMockApiKeyRepository(ctrl)
+	return mockApiKeyRepository, ctrl
+}
+
+// testClock is the clock of the services under test, in the reference month 2021-08.
+var testClock = domain.FixedClock(time.Date(2021, time.August, 15, 12, 0, 0, 0, time.UTC))
+
+func TestApiKeyService_Create(t *testing.T) {
+	tests := []struct {
+		name         string
+		keyName      string
+		monthlyQuota int
+		apiKeyRepo   func(repo *mockPort.MockApiKeyRepository)
+		wantErr      *errs.AppError
+	}{
+		{
+			name:         "Valid key",
+			keyName:      "Dealer",
+			monthlyQuota: 1000,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, apiKey domain.ApiKey) (domain.ApiKey, *errs.AppError) {
+						apiKey.ID = 1
+						return apiKey, nil
+					}).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name:         "Empty name, ValidationError",
+			keyName:      " ",
+			monthlyQuota: 1000,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: errs.NewValidationError("Name is required"),
+		},
+		{
+			name:         "Negative quota, ValidationError",
+			keyName:      "Dealer",
+			monthlyQuota: -1,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+			},
+			wantErr: errs.NewValidationError("Monthly quota must be greater or equal than 0"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyRepository, ctrl := getMockApiKeyRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.apiKeyRepo(mockApiKeyRepository)
+			a := NewApiKeyService(mockApiKeyRepository, testClock, logger.NewNop())
+
+			got, rawKey, err := a.Create(context.Background(), tt.keyName, tt.monthlyQuota)
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				return
+			}
+			assert.Equal(t, uint(1), got.ID)
+			assert.Equal(t, domain.HashApiKey(rawKey), got.Hash)
+			assert.Equal(t, domain.GetApiKeyIdentifier(rawKey), got.Prefix)
+			assert.Equal(t, tt.monthlyQuota, got.MonthlyQuota)
+		})
+	}
+}
+
+func TestApiKeyService_Authenticate(t *testing.T) {
+	revokedAt := testClock.Now()
+	rawKey := "gf_secret"
+	hash := domain.HashApiKey(rawKey)
+
+	tests := []struct {
+		name       string
+		rawKey     string
+		apiKeyRepo func(repo *mockPort.MockApiKeyRepository)
+		want       domain.ApiKey
+		wantErr    *errs.AppError
+	}{
+		{
+			name:   "Valid key, not accounted",
+			rawKey: rawKey,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).Return(domain.ApiKey{ID: 1, MonthlyQuota: 10}, nil).Times(1)
+				repo.EXPECT().IncrementUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			want:    domain.ApiKey{ID: 1, MonthlyQuota: 10},
+			wantErr: nil,
+		},
+		{
+			name:   "Unknown key, UnauthorizedError",
+			rawKey: rawKey,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).Return(domain.ApiKey{}, errs.NewNotFoundError("Api key not found")).Times(1)
+			},
+			want:    domain.ApiKey{},
+			wantErr: errs.NewUnauthorizedError("Invalid api key"),
+		},
+		{
+			name:   "Revoked key, UnauthorizedError",
+			rawKey: rawKey,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), hash).Return(domain.ApiKey{ID: 1, RevokedAt: &revokedAt}, nil).Times(1)
+			},
+			want:    domain.ApiKey{},
+			wantErr: errs.NewUnauthorizedError("Invalid api key"),
+		},
+		{
+			name:   "Missing key, UnauthorizedError",
+			rawKey: "",
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByHash(gomock.Any(), gomock.Any()).Times(0)
+			},
+			want:    domain.ApiKey{},
+			wantErr: errs.NewUnauthorizedError("Api key is required"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyRepository, ctrl := getMockApiKeyRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.apiKeyRepo(mockApiKeyRepository)
+			a := NewApiKeyService(mockApiKeyRepository, testClock, logger.NewNop())
+
+			got, err := a.Authenticate(context.Background(), tt.rawKey)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestApiKeyService_AccountUsage(t *testing.T) {
+	year, month := 2021, time.August
+
+	tests := []struct {
+		name       string
+		apiKey     domain.ApiKey
+		apiKeyRepo func(repo *mockPort.MockApiKeyRepository)
+		wantErr    *errs.AppError
+	}{
+		{
+			name:   "Below quota",
+			apiKey: domain.ApiKey{ID: 1, MonthlyQuota: 10},
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().IncrementUsage(gomock.Any(), uint(1), year, int(month), 10).
+					Return(domain.ApiKeyUsage{ApiKeyID: 1, Requests: 10}, true, nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name:   "Without quota",
+			apiKey: domain.ApiKey{ID: 1},
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().IncrementUsage(gomock.Any(), uint(1), year, int(month), 0).
+					Return(domain.ApiKeyUsage{ApiKeyID: 1, Requests: 1}, true, nil).Times(1)
+			},
+			wantErr: nil,
+		},
+		{
+			name:   "Quota exceeded, TooManyRequestsError",
+			apiKey: domain.ApiKey{ID: 1, MonthlyQuota: 10},
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().IncrementUsage(gomock.Any(), uint(1), year, int(month), 10).
+					Return(domain.ApiKeyUsage{}, false, nil).Times(1)
+			},
+			wantErr: errs.NewTooManyRequestsError("Monthly quota exceeded"),
+		},
+		{
+			name:   "Repository error",
+			apiKey: domain.ApiKey{ID: 1, MonthlyQuota: 10},
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().IncrementUsage(gomock.Any(), uint(1), year, int(month), 10).
+					Return(domain.ApiKeyUsage{}, false, errs.NewUnexpectedError("Unexpected database error")).Times(1)
+			},
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyRepository, ctrl := getMockApiKeyRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.apiKeyRepo(mockApiKeyRepository)
+			a := NewApiKeyService(mockApiKeyRepository, testClock, logger.NewNop())
+
+			assert.Equal(t, tt.wantErr, a.AccountUsage(context.Background(), tt.apiKey))
+		})
+	}
+}
+
+func TestApiKeyService_GetUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		year       int
+		month      int
+		apiKeyRepo func(repo *mockPort.MockApiKeyRepository)
+		want       domain.ApiKeyUsage
+		wantErr    *errs.AppError
+	}{
+		{
+			name:  "Valid usage",
+			year:  2021,
+			month: 7,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByID(gomock.Any(), uint(1)).Return(domain.ApiKey{ID: 1}, nil).Times(1)
+				repo.EXPECT().GetUsage(gomock.Any(), uint(1), 2021, 7).
+					Return(domain.ApiKeyUsage{ApiKeyID: 1, Year: 2021, Month: 7, Requests: 42}, nil).Times(1)
+			},
+			want:    domain.ApiKeyUsage{ApiKeyID: 1, Year: 2021, Month: 7, Requests: 42},
+			wantErr: nil,
+		},
+		{
+			name:  "Future year, ValidationError",
+			year:  2022,
+			month: 1,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			want:    domain.ApiKeyUsage{},
+			wantErr: errs.NewValidationError("Invalid year"),
+		},
+		{
+			name:  "Invalid month, ValidationError",
+			year:  2021,
+			month: 13,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			want:    domain.ApiKeyUsage{},
+			wantErr: errs.NewValidationError("Invalid month"),
+		},
+		{
+			name:  "Unknown key, NotFoundError",
+			year:  2021,
+			month: 7,
+			apiKeyRepo: func(repo *mockPort.MockApiKeyRepository) {
+				repo.EXPECT().GetByID(gomock.Any(), uint(1)).Return(domain.ApiKey{}, errs.NewNotFoundError("Api key not found")).Times(1)
+			},
+			want:    domain.ApiKeyUsage{},
+			wantErr: errs.NewNotFoundError("Api key not found"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockApiKeyRepository, ctrl := getMockApiKeyRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.apiKeyRepo(mockApiKeyRepository)
+			a := NewApiKeyService(mockApiKeyRepository, testClock, logger.NewNop())
+
+			got, err := a.GetUsage(context.Background(), 1, tt.year, tt.month)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+)
+
+const (
+	postgresDependency      = "postgres"
+	schemaDependency        = "schema"
+	referenceDataDependency = "reference_data"
+)
+
+type HealthService struct {
+	healthRepo    ports.HealthRepository
+	schemaVersion int
+	log           logger.Logger
+}
+
+// NewHealthService creates a HealthService that expects the database to be migrated to schemaVersion.
+func NewHealthService(healthRepo ports.HealthRepository, schemaVersion int, log logger.Logger) HealthService {
+	return HealthService{healthRepo: healthRepo, schemaVersion: schemaVersion, log: log}
+}
+
+// Readiness checks whether Postgres is reachable, whether its schema matches the expected version and
+// whether at least one reference month is loaded. Every check runs, so the result shows all failures.
+func (h HealthService) Readiness(ctx context.Context) domain.Health {
+	health := domain.Health{
+		Dependencies: []domain.DependencyHealth{
+			h.checkPostgres(ctx),
+			h.checkSchema(ctx),
+			h.checkReferenceData(ctx),
+		},
+	}
+	if health.Status() != domain.HealthStatusUp {
+		for _, dependency := range health.Dependencies {
+			if dependency.Status != domain.HealthStatusUp {
+				logger.FromContext(ctx, h.log).Info("Dependency not ready",
+					logger.String("dependency", dependency.Name),
+					logger.String("reason", dependency.Message),
+				)
+			}
+		}
+	}
+	return health
+}
+
+func (h HealthService) checkPostgres(ctx context.Context) domain.DependencyHealth {
+	if err := h.healthRepo.Ping(ctx); err != nil {
+		return down(postgresDependency, err.Message)
+	}
+	return up(postgresDependency, "")
+}
+
+func (h HealthService) checkSchema(ctx context.Context) domain.DependencyHealth {
+	version, err := h.healthRepo.GetSchemaVersion(ctx)
+	if err != nil {
+		return down(schemaDependency, err.Message)
+	}
+	if version != h.schemaVersion {
+		return down(schemaDependency, fmt.Sprintf("Expected schema version %d, found %d", h.schemaVersion, version))
+	}
+	return up(schemaDependency, fmt.Sprintf("Schema version %d", version))
+}
+
+func (h HealthService) checkReferenceData(ctx context.Context) domain.DependencyHealth {
+	year, month, err := h.healthRepo.GetLatestReferenceMonth(ctx)
+	if err != nil {
+		if err.Code == http.StatusNotFound {
+			return down(referenceDataDependency, "No reference month loaded")
+		}
+		return down(referenceDataDependency, err.Message)
+	}
+	return up(referenceDataDependency, fmt.Sprintf("Latest reference month %04d-%02d", year, month))
+}
+
+func up(name string, message string) domain.DependencyHealth {
+	return domain.DependencyHealth{Name: name, Status: domain.HealthStatusUp, Message: message}
+}
+
+func down(name string, message string) domain.DependencyHealth {
+	return domain.DependencyHealth{Name: name, Status: domain.HealthStatusDown, Message: message}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/raffops/gofipe/cmd/goFipe/domain"
+	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
+	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthService_Readiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		healthRepo func(repo *mockPort.MockHealthRepository)
+		want       domain.Health
+		wantStatus domain.HealthStatus
+	}{
+		{
+			name: "All dependencies up",
+			healthRepo: func(repo *mockPort.MockHealthRepository) {
+				repo.EXPECT().Ping(gomock.Any()).Return(nil)
+				repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(1, nil)
+				repo.EXPECT().GetLatestReferenceMonth(gomock.Any()).Return(2021, 7, nil)
+			},
+			want: domain.Health{Dependencies: []domain.DependencyHealth{
+				{Name: "postgres", Status: domain.HealthStatusUp},
+				{Name: "schema", Status: domain.HealthStatusUp, Message: "Schema version 1"},
+				{Name: "reference_data", Status: domain.HealthStatusUp, Message: "Latest reference month 2021-07"},
+			}},
+			wantStatus: domain.HealthStatusUp,
+		},
+		{
+			name: "Outdated schema and no reference month",
+			healthRepo: func(repo *mockPort.MockHealthRepository) {
+				repo.EXPECT().Ping(gomock.Any()).Return(nil)
+				repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(0, errs.NewNotFoundError("Schema version not found"))
+				repo.EXPECT().GetLatestReferenceMonth(gomock.Any()).
+					Return(0, 0, errs.NewNotFoundError("No reference month loaded"))
+			},
+			want: domain.Health{Dependencies: []domain.DependencyHealth{
+				{Name: "postgres", Status: domain.HealthStatusUp},
+				{Name: "schema", Status: domain.HealthStatusDown, Message: "Schema version not found"},
+				{Name: "reference_data", Status: domain.HealthStatusDown, Message: "No reference month loaded"},
+			}},
+			wantStatus: domain.HealthStatusDown,
+		},
+		{
+			name: "Schema version mismatch",
+			healthRepo: func(repo *mockPort.MockHealthRepository) {
+				repo.EXPECT().Ping(gomock.Any()).Return(nil)
+				repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(2, nil)
+				repo.EXPECT().GetLatestReferenceMonth(gomock.Any()).Return(2021, 7, nil)
+			},
+			want: domain.Health{Dependencies: []domain.DependencyHealth{
+				{Name: "postgres", Status: domain.HealthStatusUp},
+				{Name: "schema", Status: domain.HealthStatusDown, Message: "Expected schema version 1, found 2"},
+				{Name: "reference_data", Status: domain.HealthStatusUp, Message: "Latest reference month 2021-07"},
+			}},
+			wantStatus: domain.HealthStatusDown,
+		},
+		{
+			name: "Postgres down",
+			healthRepo: func(repo *mockPort.MockHealthRepository) {
+				repo.EXPECT().Ping(gomock.Any()).Return(errs.NewGatewayTimeoutError("Database query timed out"))
+				repo.EXPECT().GetSchemaVersion(gomock.Any()).Return(0, errs.NewGatewayTimeoutError("Database query timed out"))
+				repo.EXPECT().GetLatestReferenceMonth(gomock.Any()).
+					Return(0, 0, errs.NewGatewayTimeoutError("Database query timed out"))
+			},
+			want: domain.Health{Dependencies: []domain.DependencyHealth{
+				{Name: "postgres", Status: domain.HealthStatusDown, Message: "Database query timed out"},
+				{Name: "schema", Status: domain.HealthStatusDown, Message: "Database query timed out"},
+				{Name: "reference_data", Status: domain.HealthStatusDown, Message: "Database query timed out"},
+			}},
+			wantStatus: domain.HealthStatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+			mockHealthRepository := mockPort.NewMockHealthRepository(ctrl)
+			tt.healthRepo(mockHealthRepository)
+
+			got := NewHealthService(mockHealthRepository, 1, logger.NewNop()).Readiness(context.Background())
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantStatus, got.Status())
+		})
+	}
+}
@@ -1,147 +1,392 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
 	"github.com/raffops/gofipe/cmd/goFipe/errs"
 	"github.com/raffops/gofipe/cmd/goFipe/logger"
-	"slices"
-	"strconv"
+	"github.com/raffops/gofipe/cmd/goFipe/metrics"
+	"github.com/raffops/gofipe/cmd/goFipe/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"net/http"
+	"strings"
+	"time"
 )
 
 type VehicleService struct {
 	vehicleRepo ports.VehicleRepository
+	clock       domain.Clock
+	log         logger.Logger
 }
 
-func NewVehicleService(vehicleRepo ports.VehicleRepository) VehicleService {
-	return VehicleService{vehicleRepo: vehicleRepo}
+func NewVehicleService(vehicleRepo ports.VehicleRepository, clock domain.Clock, log logger.Logger) VehicleService {
+	return VehicleService{vehicleRepo: vehicleRepo, clock: clock, log: log}
 }
 
-func (v VehicleService) GetVehicle(
-	where map[string]string,
-	orderBy map[string]bool,
-	offset int,
-	limit int) ([]domain.Vehicle, *errs.AppError) {
+// GetVehicle returns the vehicles matching the query. The query was validated when it was built.
+func (v VehicleService) GetVehicle(ctx context.Context, query domain.VehicleQuery) ([]domain.Vehicle, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.GetVehicle")
+	defer span.End()
 
-	logger.Info("GetVehicle service called",
+	match, _ := query.Match()
+	logger.FromContext(ctx, v.log).Debug("GetVehicle service called",
+		logger.String("filters", fmt.Sprint(query.Filters())),
+		logger.String("match", match.String()),
+		logger.String("period", query.Period().String()),
+		logger.String("sorts", fmt.Sprint(query.Sorts())),
+		logger.Int("offset", query.Pagination().Offset),
+		logger.Int("limit", query.Pagination().Limit),
+	)
 
-		logger.String("where", fmt.Sprint(where)),
-		logger.String("orderBy", fmt.Sprint(orderBy)),
-		logger.Int("offset", offset),
-		logger.Int("limit", limit),
+	return v.vehicleRepo.GetVehicle(ctx, query)
+}
+
+// GetHistory returns the prices of fipeCode in the period. A period without end ends in the current
+// reference month and a period without start covers DefaultHistoryMonths reference months.
+func (v VehicleService) GetHistory(ctx context.Context, fipeCode string, period domain.Period) (domain.VehicleHistory, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.GetHistory")
+	defer span.End()
+
+	if period.To.IsZero() {
+		period.To = domain.CurrentReferenceMonth(v.clock)
+	}
+	if period.From.IsZero() {
+		period.From = period.To.AddMonths(1 - domain.DefaultHistoryMonths)
+	}
+	query, errQuery := domain.NewVehicleQueryBuilderWithClock(v.clock).
+		Where(string(domain.VehicleFieldFipeCode), fipeCode).
+		Period(period.From.String(), period.To.String()).
+		OrderBy(string(domain.VehicleFieldYear), false).
+		OrderBy(string(domain.VehicleFieldMonth), false).
+		OrderBy(string(domain.VehicleFieldMeanValue), false).
+		Page(0, domain.MaxLimit).
+		Build()
+	if errQuery != nil {
+		return domain.VehicleHistory{}, newValidationError(errQuery.Reason, errQuery.Message)
+	}
+
+	logger.FromContext(ctx, v.log).Debug("GetHistory service called",
+		logger.String("fipe_code", fipeCode),
+		logger.String("period", period.String()),
 	)
 
-	errValidate := validate(where, orderBy, offset, limit)
-	if errValidate != nil {
-		return nil, errValidate
+	var vehicles []domain.Vehicle
+	for ; ; query = query.NextPage() {
+		page, err := v.vehicleRepo.GetVehicle(ctx, query)
+		if err != nil && err.Code != http.StatusNotFound {
+			return domain.VehicleHistory{}, err
+		}
+		vehicles = append(vehicles, page...)
+		if len(page) < domain.MaxLimit {
+			break
+		}
+	}
+	if len(vehicles) == 0 {
+		return domain.VehicleHistory{}, errs.NewNotFoundError("Vehicles not found")
 	}
 
-	var whereClauses []domain.WhereClause
-	for column, value := range where {
-		whereClauses = append(whereClauses, domain.WhereClause{
-			Column:   column,
-			Operator: "=",
-			Value:    value,
+	prices := make([]domain.VehiclePrice, 0, len(vehicles))
+	for _, vehicle := range vehicles {
+		prices = append(prices, domain.VehiclePrice{
+			ReferenceMonth: vehicle.ReferenceMonth(),
+			YearModel:      vehicle.YearModel,
+			MeanValue:      vehicle.MeanValue,
 		})
 	}
+	latest := vehicles[len(vehicles)-1]
+	return domain.VehicleHistory{
+		FipeCode: fipeCode,
+		Brand:    latest.Brand,
+		Model:    latest.Model,
+		Period:   period,
+		Prices:   prices,
+	}, nil
+}
+
+// GetPriceAsOf returns the price of the year model of fipeCode on the date asOf, taken from the latest
+// reference month on or before it. asOf must not be in the future.
+func (v VehicleService) GetPriceAsOf(
+	ctx context.Context,
+	fipeCode string,
+	yearModel string,
+	asOf time.Time,
+) (domain.VehiclePriceAsOf, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.GetPriceAsOf")
+	defer span.End()
+
+	referenceMonth := domain.ReferenceMonthOf(asOf)
+	switch {
+	case !domain.IsValidFipeCode(fipeCode):
+		return domain.VehiclePriceAsOf{}, newValidationError("invalid_fipe_code", "Invalid fipe code")
+	case strings.TrimSpace(yearModel) == "":
+		return domain.VehiclePriceAsOf{}, newValidationError("invalid_year_model", "Invalid year model")
+	case referenceMonth.Year() < domain.MinReferenceYear:
+		return domain.VehiclePriceAsOf{}, newValidationError(
+			"invalid_as_of", fmt.Sprintf("As of must not be before %d", domain.MinReferenceYear),
+		)
+	case asOf.After(v.clock.Now()):
+		return domain.VehiclePriceAsOf{}, newValidationError("invalid_as_of", "As of must not be in the future")
+	}
+
+	logger.FromContext(ctx, v.log).Debug("GetPriceAsOf service called",
+		logger.String("fipe_code", fipeCode),
+		logger.String("year_model", yearModel),
+		logger.String("as_of", asOf.Format(domain.AsOfLayout)),
+	)
+
+	vehicle, err := v.vehicleRepo.GetPriceAsOf(ctx, fipeCode, yearModel, referenceMonth)
+	if err != nil {
+		return domain.VehiclePriceAsOf{}, err
+	}
+	return domain.VehiclePriceAsOf{
+		FipeCode: vehicle.FipeCode,
+		Brand:    vehicle.Brand,
+		Model:    vehicle.Model,
+		AsOf:     asOf,
+		Price: domain.VehiclePrice{
+			ReferenceMonth: vehicle.ReferenceMonth(),
+			YearModel:      vehicle.YearModel,
+			MeanValue:      vehicle.MeanValue,
+		},
+	}, nil
+}
+
+// LookupVehicles resolves every lookup of a batch with a single repository query. Each result tells
+// whether its lookup was found, not found or invalid, in the order of the lookups; the batch only
+// fails as a whole if it is empty, too large or the repository fails.
+func (v VehicleService) LookupVehicles(ctx context.Context, lookups []domain.VehicleLookup) ([]domain.VehicleLookupResult, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.LookupVehicles")
+	defer span.End()
 
-	var orderByClauses []domain.OrderByClause
-	for column, isDesc := range orderBy {
-		orderByClauses = append(
-			orderByClauses,
-			domain.OrderByClause{Column: column, IsDesc: isDesc},
+	switch {
+	case len(lookups) == 0:
+		return nil, newValidationError("no_lookups", "No lookups")
+	case len(lookups) > domain.MaxVehicleLookups:
+		return nil, newValidationError(
+			"too_many_lookups", fmt.Sprintf("At most %d lookups per batch", domain.MaxVehicleLookups),
 		)
 	}
 
-	pagination := domain.Pagination{
-		Offset: offset,
-		Limit:  limit,
+	results := make([]domain.VehicleLookupResult, 0, len(lookups))
+	validLookups := make([]domain.VehicleLookup, 0, len(lookups))
+	for _, lookup := range lookups {
+		result := domain.VehicleLookupResult{Lookup: lookup, Status: domain.LookupNotFound}
+		if message := validateLookup(v.clock, lookup); message != "" {
+			result.Status = domain.LookupInvalid
+			result.Message = message
+		} else {
+			validLookups = append(validLookups, lookup)
+		}
+		results = append(results, result)
+	}
+
+	logger.FromContext(ctx, v.log).Debug("LookupVehicles service called",
+		logger.Int("lookups", len(lookups)),
+		logger.Int("valid_lookups", len(validLookups)),
+	)
+	if len(validLookups) == 0 {
+		return results, nil
 	}
 
-	return v.vehicleRepo.GetVehicle(whereClauses, orderByClauses, pagination)
+	vehicles, err := v.vehicleRepo.LookupVehicles(ctx, validLookups)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[domain.VehicleLookup]domain.Vehicle, len(vehicles))
+	for _, vehicle := range vehicles {
+		key := domain.VehicleLookup{
+			FipeCode:       vehicle.FipeCode,
+			YearModel:      vehicle.YearModel,
+			ReferenceMonth: vehicle.ReferenceMonth(),
+		}
+		if _, ok := found[key]; !ok {
+			found[key] = vehicle
+		}
+	}
+	for index, result := range results {
+		if vehicle, ok := found[result.Lookup]; ok && result.Status == domain.LookupNotFound {
+			results[index].Status = domain.LookupFound
+			results[index].Vehicle = vehicle
+		}
+	}
+	return results, nil
 }
 
-func validate(where map[string]string, orderBy map[string]bool, offset int, limit int) *errs.AppError {
-	if errValidateWhere := validateWhere(where); errValidateWhere != nil {
-		return errValidateWhere
+// validateLookup returns why the lookup is invalid, if it is.
+func validateLookup(clock domain.Clock, lookup domain.VehicleLookup) string {
+	switch {
+	case !domain.IsValidFipeCode(lookup.FipeCode):
+		return "Invalid fipe code"
+	case strings.TrimSpace(lookup.YearModel) == "":
+		return "Invalid year model"
+	case lookup.ReferenceMonth.IsZero():
+		return "Invalid reference month"
+	case lookup.ReferenceMonth.IsFuture(clock):
+		return "Reference month must not be in the future"
 	}
+	return ""
+}
+
+// SearchVehicles returns the vehicles matching the free text of the search, best matches first.
+func (v VehicleService) SearchVehicles(ctx context.Context, search domain.TextSearch) ([]domain.VehicleMatch, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.SearchVehicles")
+	defer span.End()
+
+	logger.FromContext(ctx, v.log).Debug("SearchVehicles service called",
+		logger.String("q", search.Text()),
+		logger.Int("offset", search.Pagination().Offset),
+		logger.Int("limit", search.Pagination().Limit),
+	)
+	return v.vehicleRepo.SearchVehicles(ctx, search)
+}
+
+// Autocomplete returns the values suggested by the autocomplete, possibly none.
+func (v VehicleService) Autocomplete(
+	ctx context.Context,
+	autocomplete domain.Autocomplete,
+) ([]domain.AutocompleteValue, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.Autocomplete")
+	defer span.End()
 
-	if errValidateOrder := validateOrderBy(orderBy); errValidateOrder != nil {
-		return errValidateOrder
+	logger.FromContext(ctx, v.log).Debug("Autocomplete service called",
+		logger.String("autocomplete", autocomplete.String()),
+	)
+	return v.vehicleRepo.Autocomplete(ctx, autocomplete)
+}
+
+// MatchListing returns at most limit catalog vehicles that may be the one of the listing, most confident
+// first, or a NotFoundError if none may be.
+func (v VehicleService) MatchListing(ctx context.Context, listing domain.Listing, limit int) ([]domain.ListingMatch, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.MatchListing")
+	defer span.End()
+
+	if err := validateListingLimit(limit); err != nil {
+		return nil, err
 	}
+	logger.FromContext(ctx, v.log).Debug("MatchListing service called",
+		logger.String("listing", listing.Text()),
+		logger.Int("limit", limit),
+	)
 
-	if errValidatePagination := validatePagination(offset, limit); errValidatePagination != nil {
-		return errValidatePagination
+	matches, err := v.matchListing(ctx, listing, limit)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if len(matches) == 0 {
+		return nil, errs.NewNotFoundError("Matches not found")
+	}
+	return matches, nil
 }
 
-func validateWhere(where map[string]string) *errs.AppError {
-	if len(where) == 0 {
-		return errs.NewBadRequestError("Where is required")
-	}
-	for column, value := range where {
-		switch column {
-		case "fipe_code":
-			if !domain.IsValidFipeCode(value) {
-				return errs.NewValidationError("Invalid fipe code")
-			}
-		case "year":
-			value, err := strconv.Atoi(value)
-			if err != nil {
-				return errs.NewValidationError("Invalid year")
-			}
-			if !domain.IsValidYear(value) {
-				return errs.NewValidationError("Invalid year")
-			}
-		case "month":
-			value, err := strconv.Atoi(value)
-			if err != nil {
-				return errs.NewValidationError("Invalid month")
-			}
-			if !domain.IsValidMonth(value) {
-				return errs.NewValidationError("Invalid month")
-			}
-		case "mean_value":
-			_, err := strconv.ParseFloat(value, 64)
-			if err != nil {
-				return errs.NewValidationError("Invalid mean value")
-			}
-		default:
-			return errs.NewValidationError("Invalid Column")
+// MatchListings matches every description of a batch, returning at most limit matches for each. Each
+// result tells whether its description matched, matched nothing or is invalid, in the order of the
+// descriptions; the batch only fails as a whole if it is empty, too large or the repository fails.
+func (v VehicleService) MatchListings(
+	ctx context.Context,
+	descriptions []string,
+	limit int,
+) ([]domain.ListingMatchResult, *errs.AppError) {
+	ctx, span := tracing.Start(ctx, "VehicleService.MatchListings")
+	defer span.End()
+
+	switch {
+	case len(descriptions) == 0:
+		return nil, newValidationError("no_listings", "No listings")
+	case len(descriptions) > domain.MaxListingBatch:
+		return nil, newValidationError(
+			"too_many_listings", fmt.Sprintf("At most %d listings per batch", domain.MaxListingBatch),
+		)
+	}
+	if err := validateListingLimit(limit); err != nil {
+		return nil, err
+	}
+	logger.FromContext(ctx, v.log).Debug("MatchListings service called",
+		logger.Int("listings", len(descriptions)),
+		logger.Int("limit", limit),
+	)
+
+	results := make([]domain.ListingMatchResult, 0, len(descriptions))
+	for _, description := range descriptions {
+		result := domain.ListingMatchResult{Description: description, Status: domain.LookupNotFound}
+		listing, errListing := domain.NewListing(v.clock, description)
+		if errListing != nil {
+			result.Status = domain.LookupInvalid
+			result.Message = errListing.Message
+			results = append(results, result)
+			continue
 		}
+
+		matches, err := v.matchListing(ctx, listing, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) > 0 {
+			result.Status = domain.LookupFound
+			result.Matches = matches
+		}
+		results = append(results, result)
 	}
-	return nil
+	return results, nil
 }
 
-func validateOrderBy(orderBy map[string]bool) *errs.AppError {
-	validColumns := []string{"fipe_code", "year", "month", "mean_value"}
-	if len(orderBy) == 0 {
-		return errs.NewBadRequestError("OrderBy is required")
+// matchListing scores the candidates of the listing fetched from the repository.
+func (v VehicleService) matchListing(ctx context.Context, listing domain.Listing, limit int) ([]domain.ListingMatch, *errs.AppError) {
+	candidates, err := v.vehicleRepo.GetListingCandidates(ctx, listing)
+	if err != nil {
+		return nil, err
 	}
-	for column := range orderBy {
-		if !slices.Contains(validColumns, column) {
-			return errs.NewValidationError(fmt.Sprintf("Invalid column: %s", column))
-		}
+	return domain.MatchListing(listing, candidates, limit), nil
+}
+
+func validateListingLimit(limit int) *errs.AppError {
+	if limit < 1 || limit > domain.MaxListingMatches {
+		return newValidationError(
+			"invalid_limit", fmt.Sprintf("Limit must be between 1 and %d", domain.MaxListingMatches),
+		)
 	}
 	return nil
 }
 
-func validatePagination(offset int, limit int) *errs.AppError {
-	if offset < 0 {
-		return errs.NewValidationError("Offset must be greater than 0")
-	}
-	if offset > limit {
-		return errs.NewValidationError("Offset must be smaller than Limit")
+// SaveVehicles stores the vehicles of an ingestion. Every vehicle is validated before any is stored,
+// so an invalid file is rejected as a whole.
+func (v VehicleService) SaveVehicles(ctx context.Context, vehicles []domain.Vehicle) *errs.AppError {
+	ctx, span := tracing.Start(ctx, "VehicleService.SaveVehicles")
+	defer span.End()
+
+	if len(vehicles) == 0 {
+		return newValidationError("no_vehicles", "No vehicles to save")
 	}
-	if limit > domain.MaxLimit {
-		return errs.NewValidationError(
-			fmt.Sprintf("Limit must be smaller or equal than %d",
-				domain.MaxLimit,
-			),
-		)
+	for index, vehicle := range vehicles {
+		if reason, message := validateVehicle(v.clock, vehicle); reason != "" {
+			span.SetStatus(codes.Error, message)
+			return newValidationError(reason, fmt.Sprintf("Vehicle %d: %s", index, message))
+		}
 	}
 
+	if err := v.vehicleRepo.SaveVehicles(ctx, vehicles); err != nil {
+		return err
+	}
+	logger.FromContext(ctx, v.log).Info("Vehicles saved", logger.Int("count", len(vehicles)))
 	return nil
 }
+
+// validateVehicle returns the metric reason and the message of the first invalid field, if any.
+func validateVehicle(clock domain.Clock, vehicle domain.Vehicle) (string, string) {
+	switch {
+	case !domain.IsValidFipeCode(vehicle.FipeCode):
+		return "invalid_fipe_code", fmt.Sprintf("Invalid fipe code %q", vehicle.FipeCode)
+	case !domain.IsValidYearMonth(clock, vehicle.Year, vehicle.Month):
+		return "invalid_year_month", fmt.Sprintf("Invalid year/month %d/%d", vehicle.Year, vehicle.Month)
+	case vehicle.MeanValue < 0:
+		return "invalid_mean_value", "Mean value must be greater or equal than 0"
+	}
+	return "", ""
+}
+
+// newValidationError counts the validation failure by reason and returns a ValidationError.
+func newValidationError(reason string, message string) *errs.AppError {
+	metrics.ValidationFailures.WithLabelValues(reason).Inc()
+	return errs.NewValidationError(message)
+}
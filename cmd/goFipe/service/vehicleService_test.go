@@ -1,14 +1,17 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/raffops/gofipe/cmd/goFipe/domain/ports"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/raffops/gofipe/cmd/goFipe/domain"
 	mockPort "github.com/raffops/gofipe/cmd/goFipe/domain/mocks"
 	"github.com/raffops/gofipe/cmd/goFipe/errs"
+	"github.com/raffops/gofipe/cmd/goFipe/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,6 +24,7 @@ func getMockVehicleRepository(t *testing.T) (*mockPort.MockVehicleRepository, *g
 func TestNewVehicleService(t *testing.T) {
 	mockVehicleRepository, ctrl := getMockVehicleRepository(t)
 	t.Cleanup(ctrl.Finish)
+	nopLogger := logger.NewNop()
 
 	type args struct {
 		vehicleRepo ports.VehicleRepository
@@ -34,12 +38,12 @@ func TestNewVehicleService(t *testing.T) {
 		{
 			name: "TestNewVehicleService",
 			args: args{vehicleRepo: mockVehicleRepository},
-			want: VehicleService{vehicleRepo: mockVehicleRepository},
+			want: VehicleService{vehicleRepo: mockVehicleRepository, clock: testClock, log: nopLogger},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewVehicleService(tt.args.vehicleRepo)
+			got := NewVehicleService(tt.args.vehicleRepo, testClock, nopLogger)
 			assert.Equal(t, tt.want, got)
 		},
 		)
@@ -47,296 +51,251 @@ func TestNewVehicleService(t *testing.T) {
 }
 
 func TestVehicleService_GetVehicle(t *testing.T) {
-	type fields struct {
-		vehicleRepo func(repo *mockPort.MockVehicleRepository)
-	}
-	type args struct {
-		where   map[string]string
-		orderBy map[string]bool
-		offset  int
-		limit   int
-	}
-
 	domainVehicleExamples := domain.GetDomainVehiclesExamples()
-
-	type TestCases struct {
-		name    string
-		fields  fields
-		args    args
-		want    []domain.Vehicle
-		wantErr *errs.AppError
+	query, errQuery := domain.NewVehicleQueryBuilder().
+		Where("year", "2021").
+		Where("month", "7").
+		OrderBy("mean_value", true).
+		Page(0, domain.MaxLimit).
+		Build()
+	if errQuery != nil {
+		t.Fatal(errQuery)
 	}
-	tests := []TestCases{
-
-		{
-			name: "where year = 2021 month 7, order by mean value desc, limit 0, return 2 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "year", Value: "2021", Operator: "="},
-							{Column: "month", Value: "7", Operator: "="},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  domain.MaxLimit - 1,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
-							nil,
-						).Times(1)
-				},
-			},
-			args: args{
-				where:   map[string]string{"year": "2021", "month": "7"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
+
+	tests := []struct {
+		name        string
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        []domain.Vehicle
+		wantErr     *errs.AppError
+	}{
+		{
+			name: "query passed to the repository, return 2 vehicles",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetVehicle(gomock.Any(), query).
+					Return([]domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]}, nil).
+					Times(1)
 			},
 			want:    []domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
 			wantErr: nil,
 		},
 		{
-			name: "where fipe = 111111-1, return 1 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "fipe_code", Value: "111111-1", Operator: "="},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  domain.MaxLimit - 1,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[0]},
-							nil,
-						).Times(1)
-				}},
-			args: args{
-				where:   map[string]string{"fipe_code": "111111-1"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
+			name: "no vehicles, NotFoundError",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetVehicle(gomock.Any(), query).
+					Return(nil, errs.NewNotFoundError("Vehicles not found")).
+					Times(1)
 			},
-			want:    []domain.Vehicle{domainVehicleExamples[0]},
-			wantErr: nil,
+			want:    nil,
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.GetVehicle(context.Background(), query)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleService_GetHistory(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	historyQuery := func(from string, to string) domain.VehicleQuery {
+		return domain.NewVehicleQueryBuilderWithClock(testClock).
+			Where("fipe_code", "222222-2").
+			Period(from, to).
+			OrderBy("year", false).
+			OrderBy("month", false).
+			OrderBy("mean_value", false).
+			Page(0, domain.MaxLimit).
+			MustBuild()
+	}
+	fullPage := make([]domain.Vehicle, domain.MaxLimit)
+	for i := range fullPage {
+		fullPage[i] = vehicles[1]
+	}
+	june2021 := domain.ReferenceMonthOf(time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC))
+	july2021 := june2021.AddMonths(1)
+
+	tests := []struct {
+		name        string
+		fipeCode    string
+		period      domain.Period
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        domain.VehicleHistory
+		wantErr     *errs.AppError
+	}{
 		{
-			name: "where fipe = 222222-2, return 2 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "fipe_code", Operator: "=", Value: "222222-2"},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  domain.MaxLimit - 1,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[1], domainVehicleExamples[2]},
-							nil,
-						).Times(1)
+			name:     "default period ends in the current reference month",
+			fipeCode: "222222-2",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetVehicle(gomock.Any(), historyQuery("2020-09", "2021-08")).
+					Return(vehicles[1:3], nil).
+					Times(1)
+			},
+			want: domain.VehicleHistory{
+				FipeCode: "222222-2",
+				Brand:    "Fiat",
+				Model:    "147 C/ CL",
+				Period:   domain.Period{From: july2021.AddMonths(-10), To: july2021.AddMonths(1)},
+				Prices: []domain.VehiclePrice{
+					{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800},
+					{ReferenceMonth: july2021, YearModel: "1991 Gasolina", MeanValue: 801},
 				},
 			},
-			args: args{
-				where:   map[string]string{"fipe_code": "222222-2"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
-			},
-			want:    []domain.Vehicle{domainVehicleExamples[1], domainVehicleExamples[2]},
-			wantErr: nil,
 		},
 		{
-			name: "where fipe = 999999-9, return 0 vehicles, NotFoundError",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().
-						GetVehicle(
-							[]domain.WhereClause{
-								{
-									Column:   "fipe_code",
-									Operator: "=",
-									Value:    "999999-9",
-								},
-							},
-							[]domain.OrderByClause{
-								{Column: "mean_value", IsDesc: true},
-							},
-							domain.Pagination{
-								Offset: 0,
-								Limit:  domain.MaxLimit - 1,
-							},
-						).Return(
-						nil,
-						errs.NewNotFoundError("Vehicles not found for fipe_code equal to 999999-9"),
-					).Times(1)
-				},
-			},
-			args: args{
-				where:   map[string]string{"fipe_code": "999999-9"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
-			},
-			want:    nil,
-			wantErr: errs.NewNotFoundError("Vehicles not found for fipe_code equal to 999999-9"),
-		},
-		{
-			name: "where fipe = 333333-3, pagination 10, return 2 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "fipe_code", Value: "333333-3", Operator: "="},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  10,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[3]},
-							nil,
-						).Times(1)
-				}},
-			args: args{
-				where:   map[string]string{"fipe_code": "333333-3"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   10,
+			name:     "full pages are followed by the next page",
+			fipeCode: "222222-2",
+			period:   domain.Period{From: june2021, To: july2021},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				query := historyQuery("2021-06", "2021-07")
+				repo.EXPECT().GetVehicle(gomock.Any(), query).Return(fullPage, nil).Times(1)
+				repo.EXPECT().GetVehicle(gomock.Any(), query.NextPage()).
+					Return(nil, errs.NewNotFoundError("Vehicles not found")).
+					Times(1)
+			},
+			want: domain.VehicleHistory{
+				FipeCode: "222222-2",
+				Brand:    "Fiat",
+				Model:    "147 C/ CL",
+				Period:   domain.Period{From: june2021, To: july2021},
+				Prices: func() []domain.VehiclePrice {
+					prices := make([]domain.VehiclePrice, domain.MaxLimit)
+					for i := range prices {
+						prices[i] = domain.VehiclePrice{ReferenceMonth: june2021, YearModel: "1991 Gasolina", MeanValue: 800}
+					}
+					return prices
+				}(),
 			},
-			want:    []domain.Vehicle{domainVehicleExamples[3]},
-			wantErr: nil,
 		},
 		{
-			name: "where year = 2021 month 7, return 2 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "year", Operator: "=", Value: "2021"},
-							{Column: "month", Operator: "=", Value: "7"},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  domain.MaxLimit - 1,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
-							nil,
-						).Times(1)
-				},
-			},
-			args: args{
-				where:   map[string]string{"year": "2021", "month": "7"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
+			name:     "no prices, NotFoundError",
+			fipeCode: "222222-2",
+			period:   domain.Period{From: june2021, To: july2021},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetVehicle(gomock.Any(), historyQuery("2021-06", "2021-07")).
+					Return(nil, errs.NewNotFoundError("Vehicles not found")).
+					Times(1)
 			},
-			want:    []domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
-			wantErr: nil,
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
 		},
 		{
-			name: "where year = 2021 month 7, order by mean value desc, return 2 vehicles",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(
-						[]domain.WhereClause{
-							{Column: "year", Value: "2021", Operator: "="},
-							{Column: "month", Value: "7", Operator: "="},
-						},
-						[]domain.OrderByClause{
-							{Column: "mean_value", IsDesc: true},
-						},
-						domain.Pagination{
-							Offset: 0,
-							Limit:  domain.MaxLimit - 1,
-						},
-					).
-						Return(
-							[]domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
-							nil,
-						).Times(1)
-				},
-			},
-			args: args{
-				where:   map[string]string{"year": "2021", "month": "7"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
-			},
-			want:    []domain.Vehicle{domainVehicleExamples[2], domainVehicleExamples[0]},
-			wantErr: nil,
+			name:        "invalid fipe code, ValidationError",
+			fipeCode:    "222222",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Invalid fipe code"),
 		},
 		{
-			name: "Empty where, BadRequestError",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-				},
+			name:     "repository error",
+			fipeCode: "222222-2",
+			period:   domain.Period{From: june2021, To: july2021},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetVehicle(gomock.Any(), historyQuery("2021-06", "2021-07")).
+					Return(nil, errs.NewUnexpectedError("Unexpected database error")).
+					Times(1)
 			},
-			args: args{
-				where:   map[string]string{},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
-			},
-			want:    nil,
-			wantErr: errs.NewBadRequestError("Where is required"),
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.GetHistory(context.Background(), tt.fipeCode, tt.period)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleService_GetPriceAsOf(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	july2021, _ := domain.NewReferenceMonth(2021, 7)
+	claimDate := time.Date(2021, time.July, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		fipeCode    string
+		yearModel   string
+		asOf        time.Time
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        domain.VehiclePriceAsOf
+		wantErr     *errs.AppError
+	}{
 		{
-			name: "Invalid fipe code, ValidationError",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			name:      "price of the reference month of the date",
+			fipeCode:  "222222-2",
+			yearModel: "1991 Gasolina",
+			asOf:      claimDate,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", july2021).
+					Return(vehicles[1], nil).
+					Times(1)
+			},
+			want: domain.VehiclePriceAsOf{
+				FipeCode: "222222-2",
+				Brand:    "Fiat",
+				Model:    "147 C/ CL",
+				AsOf:     claimDate,
+				Price: domain.VehiclePrice{
+					ReferenceMonth: july2021.AddMonths(-1),
+					YearModel:      "1991 Gasolina",
+					MeanValue:      800,
 				},
 			},
-			args: args{
-				where:   map[string]string{"fipe_code": "invalid"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
-			},
-			want:    nil,
-			wantErr: errs.NewValidationError("Invalid fipe code"),
 		},
 		{
-			name: "Invalid year, ValidationError",
-			fields: fields{
-				vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
-					repo.EXPECT().GetVehicle(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
-				},
-			},
-			args: args{
-				where:   map[string]string{"year": "invalid"},
-				orderBy: map[string]bool{"mean_value": true},
-				offset:  0,
-				limit:   domain.MaxLimit - 1,
+			name:      "no price on or before the date, NotFoundError",
+			fipeCode:  "222222-2",
+			yearModel: "1991 Gasolina",
+			asOf:      claimDate,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetPriceAsOf(gomock.Any(), "222222-2", "1991 Gasolina", july2021).
+					Return(domain.Vehicle{}, errs.NewNotFoundError("Price not found")).
+					Times(1)
 			},
-			want:    nil,
-			wantErr: errs.NewValidationError("Invalid year"),
+			wantErr: errs.NewNotFoundError("Price not found"),
+		},
+		{
+			name:        "invalid fipe code, ValidationError",
+			fipeCode:    "222222",
+			yearModel:   "1991 Gasolina",
+			asOf:        claimDate,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Invalid fipe code"),
+		},
+		{
+			name:        "missing year model, ValidationError",
+			fipeCode:    "222222-2",
+			yearModel:   " ",
+			asOf:        claimDate,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Invalid year model"),
+		},
+		{
+			name:        "date in the future, ValidationError",
+			fipeCode:    "222222-2",
+			yearModel:   "1991 Gasolina",
+			asOf:        time.Date(2021, time.August, 16, 0, 0, 0, 0, time.UTC),
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("As of must not be in the future"),
+		},
+		{
+			name:        "date before the first reference year, ValidationError",
+			fipeCode:    "222222-2",
+			yearModel:   "1991 Gasolina",
+			asOf:        time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC),
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("As of must not be before 1900"),
 		},
 	}
 
@@ -344,208 +303,372 @@ func TestVehicleService_GetVehicle(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
 			t.Cleanup(ctrl.Finish)
-			tt.fields.vehicleRepo(mockVehicleRepository)
-			v := VehicleService{vehicleRepo: mockVehicleRepository}
-			got, err := v.GetVehicle(tt.args.where, tt.args.orderBy, tt.args.offset, tt.args.limit)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.GetPriceAsOf(context.Background(), tt.fipeCode, tt.yearModel, tt.asOf)
 			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
 
-func Test_validatePagination(t *testing.T) {
-	type args struct {
-		offset int
-		limit  int
-	}
+func TestVehicleService_LookupVehicles(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	june2021, _ := domain.NewReferenceMonth(2021, 6)
+	july2021 := june2021.AddMonths(1)
+	found := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: june2021}
+	foundAgain := domain.VehicleLookup{FipeCode: "111111-1", YearModel: "1992 Gasolina", ReferenceMonth: july2021}
+	notFound := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1992 Gasolina", ReferenceMonth: june2021}
+	invalidFipeCode := domain.VehicleLookup{FipeCode: "222222", YearModel: "1991 Gasolina", ReferenceMonth: june2021}
+	missingYearModel := domain.VehicleLookup{FipeCode: "222222-2", ReferenceMonth: june2021}
+	missingReferenceMonth := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina"}
+	futureReferenceMonth := domain.VehicleLookup{FipeCode: "222222-2", YearModel: "1991 Gasolina", ReferenceMonth: july2021.AddMonths(2)}
+	tooMany := make([]domain.VehicleLookup, domain.MaxVehicleLookups+1)
+
 	tests := []struct {
-		name string
-		args args
-		want *errs.AppError
+		name        string
+		lookups     []domain.VehicleLookup
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        []domain.VehicleLookupResult
+		wantErr     *errs.AppError
 	}{
 		{
-			name: "valid offset and limit, no error",
-			args: args{
-				offset: 0,
-				limit:  3,
+			name:    "valid lookups in a single query, results in the order of the lookups",
+			lookups: []domain.VehicleLookup{notFound, found, invalidFipeCode, foundAgain},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().LookupVehicles(gomock.Any(), []domain.VehicleLookup{notFound, found, foundAgain}).
+					Return([]domain.Vehicle{vehicles[0], vehicles[1]}, nil).
+					Times(1)
+			},
+			want: []domain.VehicleLookupResult{
+				{Lookup: notFound, Status: domain.LookupNotFound},
+				{Lookup: found, Status: domain.LookupFound, Vehicle: vehicles[1]},
+				{Lookup: invalidFipeCode, Status: domain.LookupInvalid, Message: "Invalid fipe code"},
+				{Lookup: foundAgain, Status: domain.LookupFound, Vehicle: vehicles[0]},
 			},
-			want: nil,
 		},
 		{
-			name: "offset smaller than 0, BadRequestError",
-			args: args{
-				offset: -1,
-				limit:  3,
+			name:        "only invalid lookups, no query",
+			lookups:     []domain.VehicleLookup{missingYearModel, missingReferenceMonth, futureReferenceMonth},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			want: []domain.VehicleLookupResult{
+				{Lookup: missingYearModel, Status: domain.LookupInvalid, Message: "Invalid year model"},
+				{Lookup: missingReferenceMonth, Status: domain.LookupInvalid, Message: "Invalid reference month"},
+				{Lookup: futureReferenceMonth, Status: domain.LookupInvalid, Message: "Reference month must not be in the future"},
 			},
-			want: errs.NewValidationError("Offset must be greater than 0"),
 		},
-
 		{
-			name: "offset greater than limit, BadRequestError",
-			args: args{
-				offset: 3,
-				limit:  2,
+			name:    "repository error fails the batch",
+			lookups: []domain.VehicleLookup{found},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().LookupVehicles(gomock.Any(), []domain.VehicleLookup{found}).
+					Return(nil, errs.NewUnexpectedError("Unexpected database error")).
+					Times(1)
 			},
-			want: errs.NewValidationError("Offset must be smaller than Limit"),
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
 		},
 		{
-			name: "limit greater than domain.MaxLimit, BadRequestError",
-			args: args{
-				offset: 0,
-				limit:  domain.MaxLimit + 1,
-			},
-			want: errs.NewValidationError(fmt.Sprintf("Limit must be smaller or equal than %d", domain.MaxLimit)),
+			name:        "no lookups, ValidationError",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("No lookups"),
+		},
+		{
+			name:        "too many lookups, ValidationError",
+			lookups:     tooMany,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError(fmt.Sprintf("At most %d lookups per batch", domain.MaxVehicleLookups)),
 		},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, validatePagination(tt.args.offset, tt.args.limit), "validatePagination(%v, %v)", tt.args.offset, tt.args.limit)
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.LookupVehicles(context.Background(), tt.lookups)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
 
-func Test_validateOrderBy(t *testing.T) {
-	type args struct {
-		orderBy map[string]bool
+func TestVehicleService_SearchVehicles(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	search, errSearch := domain.NewTextSearch("fiat 147", 0, 10)
+	if errSearch != nil {
+		t.Fatal(errSearch)
 	}
+
 	tests := []struct {
-		name string
-		args args
-		want *errs.AppError
+		name        string
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        []domain.VehicleMatch
+		wantErr     *errs.AppError
 	}{
 		{
-			name: "valid orderBy, no error",
-			args: args{
-				orderBy: map[string]bool{"fipe_code": true},
+			name: "matches of the repository",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().SearchVehicles(gomock.Any(), search).
+					Return([]domain.VehicleMatch{{Vehicle: vehicles[2], Score: 0.9}}, nil).
+					Times(1)
+			},
+			want: []domain.VehicleMatch{{Vehicle: vehicles[2], Score: 0.9}},
+		},
+		{
+			name: "no matches, NotFoundError",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().SearchVehicles(gomock.Any(), search).
+					Return(nil, errs.NewNotFoundError("Vehicles not found")).
+					Times(1)
 			},
-			want: nil,
+			wantErr: errs.NewNotFoundError("Vehicles not found"),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.SearchVehicles(context.Background(), search)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleService_Autocomplete(t *testing.T) {
+	autocomplete, errAutocomplete := domain.NewAutocomplete("brand", "fi", "", "", 10)
+	if errAutocomplete != nil {
+		t.Fatal(errAutocomplete)
+	}
+
+	tests := []struct {
+		name        string
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        []domain.AutocompleteValue
+		wantErr     *errs.AppError
+	}{
 		{
-			name: "invalid column, BadRequestError",
-			args: args{
-				orderBy: map[string]bool{"invalid_column": true},
+			name: "values of the repository",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().Autocomplete(gomock.Any(), autocomplete).
+					Return([]domain.AutocompleteValue{{Value: "Fiat", Count: 2}}, nil).
+					Times(1)
 			},
-			want: errs.NewValidationError("Invalid column: invalid_column"),
+			want: []domain.AutocompleteValue{{Value: "Fiat", Count: 2}},
 		},
 		{
-			name: "empty orderBy, BadRequestError",
-			args: args{
-				orderBy: map[string]bool{},
+			name: "repository error",
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().Autocomplete(gomock.Any(), autocomplete).
+					Return(nil, errs.NewUnexpectedError("Unexpected database error")).
+					Times(1)
 			},
-			want: errs.NewBadRequestError("OrderBy is required"),
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
 		},
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, validateOrderBy(tt.args.orderBy), "validateOrderBy(%v)", tt.args.orderBy)
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.Autocomplete(context.Background(), autocomplete)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
 		})
 	}
 }
 
-func Test_validateWhere(t *testing.T) {
-	type args struct {
-		where map[string]string
+func TestVehicleService_MatchListing(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	listing, errListing := domain.NewListing(testClock, "FIAT 147 CL 1991 GAS")
+	if errListing != nil {
+		t.Fatal(errListing)
 	}
+
 	tests := []struct {
-		name string
-		args args
-		want *errs.AppError
+		name        string
+		limit       int
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		want        []domain.ListingMatch
+		wantErr     *errs.AppError
 	}{
 		{
-			name: "valid where by fipe_code, no error",
-			args: args{
-				where: map[string]string{"fipe_code": "111111-1"},
+			name:  "candidates scored",
+			limit: 1,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetListingCandidates(gomock.Any(), listing).Return(vehicles, nil).Times(1)
 			},
-			want: nil,
+			want: []domain.ListingMatch{{Vehicle: vehicles[2], Confidence: listing.Confidence(vehicles[2])}},
 		},
 		{
-			name: "valid where by year, no error",
-			args: args{
-				where: map[string]string{"year": "2021"},
+			name:  "no candidate, NotFoundError",
+			limit: 5,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetListingCandidates(gomock.Any(), listing).Return(nil, nil).Times(1)
 			},
-			want: nil,
+			wantErr: errs.NewNotFoundError("Matches not found"),
 		},
 		{
-			name: "valid where by month, no error",
-			args: args{
-				where: map[string]string{"month": "7"},
-			},
-			want: nil,
+			name:        "invalid limit",
+			limit:       domain.MaxListingMatches + 1,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Limit must be between 1 and 20"),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.MatchListing(context.Background(), listing, tt.limit)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleService_MatchListings(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	fiat, _ := domain.NewListing(testClock, "FIAT 147 CL 1991 GAS")
+	corcel, _ := domain.NewListing(testClock, "Corcel II 1984")
+
+	tests := []struct {
+		name         string
+		descriptions []string
+		vehicleRepo  func(repo *mockPort.MockVehicleRepository)
+		want         []domain.ListingMatchResult
+		wantErr      *errs.AppError
+	}{
 		{
-			name: "valid where by mean_value, no error",
-			args: args{
-				where: map[string]string{"mean_value": "1000.0"},
+			name:         "found, not found and invalid in order",
+			descriptions: []string{"FIAT 147 CL 1991 GAS", "2015 flex", "Corcel II 1984"},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetListingCandidates(gomock.Any(), fiat).Return(vehicles[1:3], nil).Times(1)
+				repo.EXPECT().GetListingCandidates(gomock.Any(), corcel).Return(nil, nil).Times(1)
+			},
+			want: []domain.ListingMatchResult{
+				{
+					Description: "FIAT 147 CL 1991 GAS",
+					Status:      domain.LookupFound,
+					Matches:     []domain.ListingMatch{{Vehicle: vehicles[2], Confidence: fiat.Confidence(vehicles[2])}},
+				},
+				{
+					Description: "2015 flex",
+					Status:      domain.LookupInvalid,
+					Message:     "Description must have at least 1 word of the brand or the model",
+				},
+				{Description: "Corcel II 1984", Status: domain.LookupNotFound},
 			},
-			want: nil,
 		},
 		{
-			name: "empty where, BadRequestError",
-			args: args{
-				where: map[string]string{},
+			name:         "repository error fails the batch",
+			descriptions: []string{"FIAT 147 CL 1991 GAS"},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().GetListingCandidates(gomock.Any(), fiat).
+					Return(nil, errs.NewUnexpectedError("Unexpected database error")).
+					Times(1)
 			},
-			want: errs.NewBadRequestError("Where is required"),
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
 		},
 		{
-			name: "invalid fipe_code, ValidationError",
-			args: args{
-				where: map[string]string{"fipe_code": "invalid"},
-			},
-			want: errs.NewValidationError("Invalid fipe code"),
+			name:         "no listings",
+			descriptions: nil,
+			vehicleRepo:  func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:      errs.NewValidationError("No listings"),
 		},
 		{
-			name: "invalid year, ValidationError",
-			args: args{
-				where: map[string]string{"year": "invalid"},
-			},
-			want: errs.NewValidationError("Invalid year"),
+			name:         "too many listings",
+			descriptions: make([]string, domain.MaxListingBatch+1),
+			vehicleRepo:  func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:      errs.NewValidationError("At most 100 listings per batch"),
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			got, err := v.MatchListings(context.Background(), tt.descriptions, domain.DefaultListingMatches)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestVehicleService_SaveVehicles(t *testing.T) {
+	vehicles := domain.GetDomainVehiclesExamples()
+	invalidFipeCode := vehicles[1]
+	invalidFipeCode.FipeCode = "111111"
+	negativeMeanValue := vehicles[1]
+	negativeMeanValue.MeanValue = -1
+	futureReferenceMonth := vehicles[1]
+	futureReferenceMonth.Month = 9
+
+	tests := []struct {
+		name        string
+		vehicles    []domain.Vehicle
+		vehicleRepo func(repo *mockPort.MockVehicleRepository)
+		wantErr     *errs.AppError
+	}{
 		{
-			name: "invalid year, ValidationError",
-			args: args{
-				where: map[string]string{"year": "-1"},
+			name:     "valid vehicles, saved",
+			vehicles: vehicles,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().SaveVehicles(gomock.Any(), vehicles).Return(nil).Times(1)
 			},
-			want: errs.NewValidationError("Invalid year"),
+			wantErr: nil,
 		},
 		{
-			name: "invalid month, ValidationError",
-			args: args{
-				where: map[string]string{"month": "invalid"},
-			},
-			want: errs.NewValidationError("Invalid month"),
+			name:        "no vehicles, ValidationError",
+			vehicles:    nil,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("No vehicles to save"),
 		},
 		{
-			name: "invalid month, ValidationError",
-			args: args{
-				where: map[string]string{"month": "-1"},
-			},
-			want: errs.NewValidationError("Invalid month"),
+			name:        "invalid fipe code, nothing saved",
+			vehicles:    []domain.Vehicle{vehicles[0], invalidFipeCode},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError(`Vehicle 1: Invalid fipe code "111111"`),
 		},
 		{
-			name: "invalid month, ValidationError",
-			args: args{
-				where: map[string]string{"month": "13"},
-			},
-			want: errs.NewValidationError("Invalid month"),
+			name:        "negative mean value, nothing saved",
+			vehicles:    []domain.Vehicle{negativeMeanValue},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Vehicle 0: Mean value must be greater or equal than 0"),
 		},
 		{
-			name: "invalid mean_value, ValidationError",
-			args: args{
-				where: map[string]string{"mean_value": "invalid"},
-			},
-			want: errs.NewValidationError("Invalid mean value"),
+			name:        "reference month after the current one, nothing saved",
+			vehicles:    []domain.Vehicle{vehicles[0], futureReferenceMonth},
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {},
+			wantErr:     errs.NewValidationError("Vehicle 1: Invalid year/month 2021/9"),
 		},
 		{
-			name: "invalid column, ValidationError",
-			args: args{
-				where: map[string]string{"invalid_column": "invalid"},
+			name:     "repository error",
+			vehicles: vehicles,
+			vehicleRepo: func(repo *mockPort.MockVehicleRepository) {
+				repo.EXPECT().SaveVehicles(gomock.Any(), vehicles).
+					Return(errs.NewUnexpectedError("Unexpected database error")).Times(1)
 			},
-			want: errs.NewValidationError("Invalid Column"),
+			wantErr: errs.NewUnexpectedError("Unexpected database error"),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equalf(t, tt.want, validateWhere(tt.args.where), "validateWhere(%v)", tt.args.where)
+			mockVehicleRepository, ctrl := getMockVehicleRepository(t)
+			t.Cleanup(ctrl.Finish)
+			tt.vehicleRepo(mockVehicleRepository)
+			v := NewVehicleService(mockVehicleRepository, testClock, logger.NewNop())
+			assert.Equal(t, tt.wantErr, v.SaveVehicles(context.Background(), tt.vehicles))
 		})
 	}
 }
@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	serviceName      = "gofipe"
+	instrumentation  = "github.com/raffops/gofipe"
+	exporterOtlp     = "otlp"
+	exporterStdout   = "stdout"
+	exporterDisabled = "none"
+)
+
+// Init configures the global tracer provider and the W3C trace context propagator.
+// exporterName selects the exporter: "otlp" exports over OTLP/HTTP (configured by the standard
+// OTEL_EXPORTER_OTLP_* variables), "stdout" prints the spans for local testing and "none" disables the export.
+// The returned function flushes the pending spans and must be called on shutdown.
+func Init(ctx context.Context, exporterName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch exporterName {
+	case exporterOtlp:
+		exporter, err = otlptracehttp.New(ctx)
+	case exporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "", exporterDisabled:
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, must be one of otlp, stdout or none", exporterName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start creates a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// StartServer creates a server span as a child of the remote span in ctx, if any.
+func StartServer(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attributes...),
+	)
+}
+
+// StartClient creates a client span, for a call to another system such as the database, as a child of the
+// span in ctx, if any.
+func StartClient(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+}
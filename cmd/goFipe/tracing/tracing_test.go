@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStart_SpanKind(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	tests := []struct {
+		name     string
+		start    func(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span)
+		wantKind trace.SpanKind
+	}{
+		{name: "internal", start: Start, wantKind: trace.SpanKindInternal},
+		{name: "server", start: StartServer, wantKind: trace.SpanKindServer},
+		{name: "client", start: StartClient, wantKind: trace.SpanKindClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, span := tt.start(context.Background(), tt.name, attribute.String("db.system", "postgresql"))
+			span.End()
+
+			spans := recorder.Ended()
+			got := spans[len(spans)-1]
+			assert.Equal(t, tt.name, got.Name())
+			assert.Equal(t, tt.wantKind, got.SpanKind())
+			assert.Equal(t, []attribute.KeyValue{attribute.String("db.system", "postgresql")}, got.Attributes())
+		})
+	}
+}